@@ -0,0 +1,217 @@
+// Package aiff provides streaming reader and writer of AIFF and AIFF-C
+// files built on top of signal buffers.
+//
+// Uncompressed big-endian PCM and AIFF-C sowt, fl32 and fl64 compression
+// types are supported. Samples are decoded into buffers of any signal
+// type and quantized to the bit depth of the Buffer:
+//
+//	r, err := aiff.NewReader(file)
+//	if err != nil {
+//		return err
+//	}
+//	buf := signal.AllocBitDepth[int32](r.Allocator(1024), signal.BitDepth24)
+//	for {
+//		n, err := aiff.Read(r, buf)
+//		if err == io.EOF {
+//			break
+//		}
+//		// process buf.Slice(0, n)
+//	}
+package aiff
+
+import (
+	"errors"
+	"math"
+
+	"pipelined.dev/signal"
+	"pipelined.dev/signal/pcm"
+)
+
+var (
+	// ErrHeader is returned when the stream is not a valid AIFF file.
+	ErrHeader = errors.New("aiff: invalid header")
+	// ErrFormat is returned when the format of samples is not supported.
+	ErrFormat = errors.New("aiff: unsupported format")
+	// ErrChannels is returned when the number of channels of the Buffer
+	// doesn't match the file.
+	ErrChannels = errors.New("aiff: different number of channels")
+	// ErrClosed is returned when samples are written after the Writer
+	// is closed.
+	ErrClosed = errors.New("aiff: writer is closed")
+)
+
+// aifcVersion is a timestamp of AIFF-C version 1 for FVER chunk.
+const aifcVersion uint32 = 0xa2805140
+
+// Compression is a compression type of AIFF-C file.
+type Compression string
+
+// Supported compression types.
+const (
+	// None is big-endian PCM.
+	None Compression = "NONE"
+	// Sowt is little-endian PCM.
+	Sowt Compression = "sowt"
+	// Float32 is big-endian 32-bit IEEE floating-point.
+	Float32 Compression = "fl32"
+	// Float64 is big-endian 64-bit IEEE floating-point.
+	Float64 Compression = "fl64"
+)
+
+// name returns the human readable name of compression type.
+func (c Compression) name() string {
+	switch c {
+	case None:
+		return "not compressed"
+	case Float32:
+		return "32-bit floating point"
+	case Float64:
+		return "64-bit floating point"
+	}
+	return ""
+}
+
+// Header describes the format of samples in the AIFF file.
+type Header struct {
+	Channels   int
+	SampleRate signal.Frequency
+	// BitDepth is a number of meaningful bits in the sample. Samples are
+	// stored in the smallest number of bytes that can hold them. For
+	// floating-point compression types it's defined by the type and can
+	// be zero.
+	BitDepth signal.BitDepth
+	// Compression is empty for AIFF files.
+	Compression Compression
+	Markers     []Marker
+	Instrument  *Instrument
+}
+
+// Marker points to a position in the samples data.
+type Marker struct {
+	ID int16
+	// Position is the number of sample frames that precede the marker.
+	Position uint32
+	Name     string
+}
+
+// PlayMode defines the playback of the Loop.
+type PlayMode int16
+
+// Play modes of the Loop.
+const (
+	NoLooping PlayMode = iota
+	ForwardLooping
+	ForwardBackwardLooping
+)
+
+// Loop is a looped section of samples between two markers.
+type Loop struct {
+	PlayMode
+	Begin int16
+	End   int16
+}
+
+// Instrument defines how the sound should be played on a sampler.
+type Instrument struct {
+	BaseNote     int8
+	Detune       int8
+	LowNote      int8
+	HighNote     int8
+	LowVelocity  int8
+	HighVelocity int8
+	Gain         int16
+	SustainLoop  Loop
+	ReleaseLoop  Loop
+}
+
+// Allocator returns an allocator for buffers that hold provided number of
+// samples per channel.
+func (h Header) Allocator(length int) signal.Allocator {
+	return signal.Allocator{
+		Channels:   h.Channels,
+		Length:     length,
+		Capacity:   length,
+		SampleRate: h.SampleRate,
+	}
+}
+
+// format returns PCM format of samples.
+func (h Header) format() (pcm.Format, error) {
+	f := pcm.Format{
+		BitDepth:  (h.BitDepth + 7) / 8 * 8,
+		BigEndian: true,
+	}
+	switch h.Compression {
+	case "", None, "twos":
+		f.Encoding = pcm.Signed
+	case "raw ":
+		f.Encoding = pcm.Unsigned
+	case Sowt:
+		f.Encoding, f.BigEndian = pcm.Signed, false
+	case Float32, "FL32":
+		f.Encoding, f.BitDepth = pcm.Float, signal.BitDepth32
+	case Float64, "FL64":
+		f.Encoding, f.BitDepth = pcm.Float, signal.BitDepth64
+	default:
+		return pcm.Format{}, ErrFormat
+	}
+	// bit depth of floating-point samples is defined by compression type,
+	// zero value is allowed in the header.
+	if f.Encoding == pcm.Float && h.BitDepth != 0 && h.BitDepth != f.BitDepth {
+		return pcm.Format{}, ErrFormat
+	}
+	if err := f.Validate(); err != nil || h.Channels <= 0 {
+		return pcm.Format{}, ErrFormat
+	}
+	return f, nil
+}
+
+// extended decodes 80-bit IEEE 754 extended precision number.
+func extended(b [10]byte) float64 {
+	exp := int(b[0]&0x7f)<<8 | int(b[1])
+	var mant uint64
+	for _, v := range b[2:] {
+		mant = mant<<8 | uint64(v)
+	}
+	if exp == 0 && mant == 0 {
+		return 0
+	}
+	f := math.Ldexp(float64(mant), exp-16383-63)
+	if b[0]&0x80 != 0 {
+		return -f
+	}
+	return f
+}
+
+// putExtended encodes 80-bit IEEE 754 extended precision number.
+func putExtended(f float64) (b [10]byte) {
+	if f == 0 {
+		return
+	}
+	if f < 0 {
+		b[0] = 0x80
+		f = -f
+	}
+	frac, exp := math.Frexp(f)
+	exp += 16382
+	b[0] |= byte(exp >> 8 & 0x7f)
+	b[1] = byte(exp)
+	mant := uint64(math.Ldexp(frac, 64))
+	for i := 9; i >= 2; i-- {
+		b[i] = byte(mant)
+		mant >>= 8
+	}
+	return
+}
+
+// pstring returns Pascal-style string padded to even length.
+func pstring(s string) []byte {
+	if len(s) > 255 {
+		s = s[:255]
+	}
+	b := append([]byte{byte(len(s))}, s...)
+	if len(b)%2 != 0 {
+		b = append(b, 0)
+	}
+	return b
+}
@@ -0,0 +1,284 @@
+package aiff_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"reflect"
+	"testing"
+
+	"pipelined.dev/signal"
+	"pipelined.dev/signal/aiff"
+)
+
+func TestRoundTrip(t *testing.T) {
+	testOk := func(h aiff.Header, samples []int32, bd signal.BitDepth) func(*testing.T) {
+		return func(t *testing.T) {
+			t.Helper()
+			var ws writeSeeker
+			w, err := aiff.NewWriter(&ws, h)
+			assertNoError(t, err)
+			src := signal.AllocBitDepth[int32](h.Allocator(len(samples)/h.Channels), bd)
+			signal.Write(samples, src)
+			// write samples in two chunks
+			n, err := aiff.Write(w, src.Slice(0, 1))
+			assertNoError(t, err)
+			assertEqual(t, "written", n, 1)
+			_, err = aiff.Write(w, src.Slice(1, src.Length()))
+			assertNoError(t, err)
+			assertNoError(t, w.Close())
+
+			for _, r := range []io.Reader{bytes.NewReader(ws.data), reader{bytes.NewReader(ws.data)}} {
+				r, err := aiff.NewReader(r)
+				assertNoError(t, err)
+				assertEqual(t, "header", r.Header, w.Header)
+				dst := signal.AllocBitDepth[int32](r.Allocator(2), bd)
+				var result []int32
+				for {
+					n, err := aiff.Read(r, dst)
+					if err == io.EOF {
+						break
+					}
+					assertNoError(t, err)
+					read := make([]int32, n*h.Channels)
+					signal.Read(dst, read)
+					result = append(result, read...)
+				}
+				assertEqual(t, "samples", result, samples)
+			}
+		}
+	}
+	t.Run("aiff 16 bits", testOk(
+		aiff.Header{Channels: 2, SampleRate: 44100, BitDepth: signal.BitDepth16},
+		[]int32{32767, -32768, 1, -1, 0, 100},
+		signal.BitDepth16,
+	))
+	t.Run("aiff 8 bits odd size", testOk(
+		aiff.Header{Channels: 1, SampleRate: 22050, BitDepth: signal.BitDepth8},
+		[]int32{127, -128, 0},
+		signal.BitDepth8,
+	))
+	t.Run("aiff 20 bits", testOk(
+		aiff.Header{Channels: 1, SampleRate: 48000, BitDepth: 20},
+		[]int32{524287, -524288, 0, 1},
+		20,
+	))
+	t.Run("aifc sowt 24 bits", testOk(
+		aiff.Header{
+			Channels:    2,
+			SampleRate:  48000,
+			BitDepth:    signal.BitDepth24,
+			Compression: aiff.Sowt,
+			Markers: []aiff.Marker{
+				{ID: 1, Position: 0, Name: "begin"},
+				{ID: 2, Position: 2, Name: "end"},
+			},
+			Instrument: &aiff.Instrument{
+				BaseNote:     60,
+				Detune:       -3,
+				HighNote:     127,
+				HighVelocity: 127,
+				Gain:         -6,
+				SustainLoop:  aiff.Loop{PlayMode: aiff.ForwardLooping, Begin: 1, End: 2},
+			},
+		},
+		[]int32{8388607, -8388608, 16, -16},
+		signal.BitDepth24,
+	))
+	t.Run("aifc fl32", testOk(
+		aiff.Header{Channels: 1, SampleRate: 96000, BitDepth: signal.BitDepth32, Compression: aiff.Float32},
+		[]int32{8388607, -8388608, -4096},
+		signal.BitDepth24,
+	))
+	t.Run("aifc fl64", testOk(
+		aiff.Header{Channels: 1, SampleRate: 11025, BitDepth: signal.BitDepth64, Compression: aiff.Float64},
+		[]int32{32767, -32768, -4096},
+		signal.BitDepth16,
+	))
+	t.Run("aifc fl64 without bit depth", testOk(
+		aiff.Header{Channels: 1, SampleRate: 11025, Compression: aiff.Float64},
+		[]int32{32767, -32768, -4096},
+		signal.BitDepth16,
+	))
+}
+
+func TestWriter(t *testing.T) {
+	t.Run("float bit depth", func(t *testing.T) {
+		var ws writeSeeker
+		_, err := aiff.NewWriter(&ws, aiff.Header{Channels: 1, SampleRate: 44100, BitDepth: signal.BitDepth64, Compression: aiff.Float32})
+		assertError(t, err, aiff.ErrFormat)
+	})
+	t.Run("incomplete frame", func(t *testing.T) {
+		var ws writeSeeker
+		h := aiff.Header{Channels: 2, SampleRate: 8000, BitDepth: signal.BitDepth8}
+		w, err := aiff.NewWriter(&ws, h)
+		assertNoError(t, err)
+		header := len(ws.data)
+		src := signal.Alloc[int8](signal.Allocator{Channels: 2, Capacity: 2})
+		for i := 0; i < 3; i++ {
+			src.AppendSample(1)
+		}
+		n, err := aiff.Write(w, src)
+		assertNoError(t, err)
+		assertEqual(t, "written", n, 1)
+		assertEqual(t, "bytes", len(ws.data)-header, 2)
+	})
+	t.Run("write after close", func(t *testing.T) {
+		var ws writeSeeker
+		h := aiff.Header{Channels: 1, SampleRate: 8000, BitDepth: signal.BitDepth8}
+		w, err := aiff.NewWriter(&ws, h)
+		assertNoError(t, err)
+		assertNoError(t, w.Close())
+		_, err = aiff.Write(w, signal.Alloc[int8](h.Allocator(1)))
+		assertError(t, err, aiff.ErrClosed)
+	})
+	// readSamples checks the size of the stream and reads its samples.
+	readSamples := func(t *testing.T, data []byte) []int8 {
+		t.Helper()
+		assertEqual(t, "size", int(binary.BigEndian.Uint32(data[4:])), len(data)-8)
+		r, err := aiff.NewReader(bytes.NewReader(data))
+		assertNoError(t, err)
+		dst := signal.Alloc[int8](r.Allocator(10))
+		n, err := aiff.Read(r, dst)
+		assertNoError(t, err)
+		result := make([]int8, n)
+		signal.Read(dst, result)
+		return result
+	}
+	t.Run("offset", func(t *testing.T) {
+		ws := writeSeeker{data: []byte("JUNK"), pos: 4}
+		h := aiff.Header{Channels: 1, SampleRate: 8000, BitDepth: signal.BitDepth8}
+		w, err := aiff.NewWriter(&ws, h)
+		assertNoError(t, err)
+		src := signal.Alloc[int8](h.Allocator(3))
+		signal.Write([]int8{1, 2, 3}, src)
+		_, err = aiff.Write(w, src)
+		assertNoError(t, err)
+		assertNoError(t, w.Close())
+		assertEqual(t, "prefix", string(ws.data[:4]), "JUNK")
+		assertEqual(t, "samples", readSamples(t, ws.data[4:]), []int8{1, 2, 3})
+	})
+	t.Run("retry close", func(t *testing.T) {
+		var ws writeSeeker
+		h := aiff.Header{Channels: 1, SampleRate: 8000, BitDepth: signal.BitDepth8}
+		w, err := aiff.NewWriter(&ws, h)
+		assertNoError(t, err)
+		src := signal.Alloc[int8](h.Allocator(3))
+		signal.Write([]int8{1, 2, 3}, src)
+		_, err = aiff.Write(w, src)
+		assertNoError(t, err)
+		errSeek := errors.New("seek failed")
+		ws.seekErr = errSeek
+		assertError(t, w.Close(), errSeek)
+		assertNoError(t, w.Close())
+		assertEqual(t, "samples", readSamples(t, ws.data), []int8{1, 2, 3})
+	})
+	t.Run("close twice", func(t *testing.T) {
+		var ws writeSeeker
+		h := aiff.Header{Channels: 1, SampleRate: 8000, BitDepth: signal.BitDepth8}
+		w, err := aiff.NewWriter(&ws, h)
+		assertNoError(t, err)
+		src := signal.Alloc[int8](h.Allocator(3))
+		_, err = aiff.Write(w, src)
+		assertNoError(t, err)
+		assertNoError(t, w.Close())
+		closed := append([]byte{}, ws.data...)
+		assertNoError(t, w.Close())
+		assertEqual(t, "stream", ws.data, closed)
+	})
+}
+
+func TestReader(t *testing.T) {
+	t.Run("sample rate", func(t *testing.T) {
+		data := []byte("FORM\x00\x00\x00\x2eAIFF" +
+			"COMM\x00\x00\x00\x12\x00\x01\x00\x00\x00\x01\x00\x10\x40\x0e\xac\x44\x00\x00\x00\x00\x00\x00" +
+			"SSND\x00\x00\x00\x0c\x00\x00\x00\x02\x00\x00\x00\x00\xff\xff\x7f\xff",
+		)
+		r, err := aiff.NewReader(reader{bytes.NewReader(data)})
+		assertNoError(t, err)
+		assertEqual(t, "sample rate", r.SampleRate, signal.Frequency(44100))
+		buf := signal.Alloc[int16](r.Allocator(2))
+		n, err := aiff.Read(r, buf)
+		assertNoError(t, err)
+		assertEqual(t, "read", n, 1)
+		assertEqual(t, "sample", buf.Sample(0), int16(32767))
+	})
+	t.Run("not aiff", func(t *testing.T) {
+		_, err := aiff.NewReader(bytes.NewReader([]byte("FORM\x00\x00\x00\x04WAVE")))
+		assertError(t, err, aiff.ErrHeader)
+	})
+	t.Run("float bit depth", func(t *testing.T) {
+		// fl32 compression with 64-bit sample size.
+		data := []byte("FORM\x00\x00\x00\x24AIFC" +
+			"COMM\x00\x00\x00\x18\x00\x01\x00\x00\x00\x01\x00\x40\x40\x0e\xac\x44\x00\x00\x00\x00\x00\x00fl32\x00\x00",
+		)
+		_, err := aiff.NewReader(bytes.NewReader(data))
+		assertError(t, err, aiff.ErrFormat)
+	})
+	t.Run("unsupported compression", func(t *testing.T) {
+		data := []byte("FORM\x00\x00\x00\x24AIFC" +
+			"COMM\x00\x00\x00\x18\x00\x01\x00\x00\x00\x01\x00\x10\x40\x0e\xac\x44\x00\x00\x00\x00\x00\x00ulaw\x00\x00",
+		)
+		_, err := aiff.NewReader(bytes.NewReader(data))
+		assertError(t, err, aiff.ErrFormat)
+	})
+}
+
+// reader hides io.Seeker implementation.
+type reader struct {
+	io.Reader
+}
+
+// writeSeeker is an in-memory io.WriteSeeker.
+type writeSeeker struct {
+	data []byte
+	pos  int
+	// seekErr is returned by the next call to Seek.
+	seekErr error
+}
+
+func (w *writeSeeker) Write(p []byte) (int, error) {
+	if end := w.pos + len(p); end > len(w.data) {
+		w.data = append(w.data, make([]byte, end-len(w.data))...)
+	}
+	w.pos += copy(w.data[w.pos:], p)
+	return len(p), nil
+}
+
+func (w *writeSeeker) Seek(offset int64, whence int) (int64, error) {
+	if err := w.seekErr; err != nil {
+		w.seekErr = nil
+		return 0, err
+	}
+	switch whence {
+	case io.SeekStart:
+		w.pos = int(offset)
+	case io.SeekCurrent:
+		w.pos += int(offset)
+	case io.SeekEnd:
+		w.pos = len(w.data) + int(offset)
+	}
+	return int64(w.pos), nil
+}
+
+func assertNoError(t *testing.T, err error) {
+	t.Helper()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func assertError(t *testing.T, err, expected error) {
+	t.Helper()
+	if !errors.Is(err, expected) {
+		t.Fatalf("expected error: %v got: %v", expected, err)
+	}
+}
+
+func assertEqual(t *testing.T, name string, result, expected interface{}) {
+	t.Helper()
+	if !reflect.DeepEqual(expected, result) {
+		t.Fatalf("%v\nresult: \t%T\t%+v \nexpected: \t%T\t%+v", name, result, result, expected, expected)
+	}
+}
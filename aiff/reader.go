@@ -0,0 +1,229 @@
+package aiff
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+
+	"pipelined.dev/signal"
+	"pipelined.dev/signal/pcm"
+)
+
+// Reader decodes samples from the AIFF stream.
+type Reader struct {
+	Header
+	r      io.Reader
+	format pcm.Format
+	// remaining is a number of bytes left in the sound data chunk.
+	remaining int64
+	buf       []byte
+}
+
+// NewReader reads the header of the AIFF stream and returns a Reader
+// positioned at the beginning of samples data. If the stream implements
+// io.Seeker, chunks that follow the sound data chunk are read as well,
+// otherwise markers and instrument must precede the sound data to be
+// available.
+func NewReader(r io.Reader) (*Reader, error) {
+	var form [12]byte
+	if _, err := io.ReadFull(r, form[:]); err != nil {
+		return nil, ErrHeader
+	}
+	if string(form[0:4]) != "FORM" {
+		return nil, ErrHeader
+	}
+	var aifc bool
+	switch string(form[8:12]) {
+	case "AIFF":
+	case "AIFC":
+		aifc = true
+	default:
+		return nil, ErrHeader
+	}
+
+	reader := Reader{r: r}
+	seeker, canSeek := r.(io.Seeker)
+	var (
+		hasCommon bool
+		dataStart int64 = -1
+	)
+	for {
+		var chunk [8]byte
+		if _, err := io.ReadFull(r, chunk[:]); err != nil {
+			// all chunks are read
+			if dataStart >= 0 && errors.Is(err, io.EOF) {
+				break
+			}
+			return nil, ErrHeader
+		}
+		size := binary.BigEndian.Uint32(chunk[4:])
+		var err error
+		switch string(chunk[:4]) {
+		case "COMM":
+			err = reader.readCommon(r, size, aifc)
+			hasCommon = true
+		case "MARK":
+			err = reader.readMarkers(r, size)
+		case "INST":
+			err = reader.readInstrument(r, size)
+		case "SSND":
+			var ssnd [8]byte
+			if _, err := io.ReadFull(r, ssnd[:]); err != nil || size < 8 {
+				return nil, ErrHeader
+			}
+			offset := binary.BigEndian.Uint32(ssnd[:])
+			reader.remaining = int64(size) - 8 - int64(offset)
+			if !canSeek {
+				if !hasCommon || skip(r, int64(offset)) != nil {
+					return nil, ErrHeader
+				}
+				return &reader, nil
+			}
+			if dataStart, err = seeker.Seek(0, io.SeekCurrent); err != nil {
+				return nil, err
+			}
+			dataStart += int64(offset)
+			err = skip(r, int64(size)-8+int64(size%2))
+		default:
+			err = skip(r, int64(size)+int64(size%2))
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	if !hasCommon {
+		return nil, ErrHeader
+	}
+	if _, err := seeker.Seek(dataStart, io.SeekStart); err != nil {
+		return nil, err
+	}
+	return &reader, nil
+}
+
+func (reader *Reader) readCommon(r io.Reader, size uint32, aifc bool) error {
+	data, err := readChunk(r, size)
+	if err != nil || len(data) < 18 {
+		return ErrHeader
+	}
+	reader.Channels = int(binary.BigEndian.Uint16(data[0:]))
+	reader.BitDepth = signal.BitDepth(binary.BigEndian.Uint16(data[6:]))
+	reader.SampleRate = signal.Frequency(extended([10]byte(data[8:18])))
+	if aifc {
+		if len(data) < 22 {
+			return ErrHeader
+		}
+		reader.Compression = Compression(data[18:22])
+	}
+	if reader.format, err = reader.Header.format(); err != nil {
+		return err
+	}
+	// floating-point samples always use full container.
+	if reader.format.Encoding == pcm.Float {
+		reader.BitDepth = reader.format.BitDepth
+	}
+	return nil
+}
+
+func (reader *Reader) readMarkers(r io.Reader, size uint32) error {
+	data, err := readChunk(r, size)
+	if err != nil || len(data) < 2 {
+		return ErrHeader
+	}
+	count := int(binary.BigEndian.Uint16(data))
+	data = data[2:]
+	markers := make([]Marker, 0, count)
+	for i := 0; i < count; i++ {
+		if len(data) < 7 {
+			return ErrHeader
+		}
+		m := Marker{
+			ID:       int16(binary.BigEndian.Uint16(data[0:])),
+			Position: binary.BigEndian.Uint32(data[2:]),
+		}
+		data = data[6:]
+		n := int(data[0])
+		if len(data) < 1+n {
+			return ErrHeader
+		}
+		m.Name = string(data[1 : 1+n])
+		data = data[min(len(data), len(pstring(m.Name))):]
+		markers = append(markers, m)
+	}
+	reader.Markers = markers
+	return nil
+}
+
+func (reader *Reader) readInstrument(r io.Reader, size uint32) error {
+	data, err := readChunk(r, size)
+	if err != nil || len(data) < 20 {
+		return ErrHeader
+	}
+	loop := func(b []byte) Loop {
+		return Loop{
+			PlayMode: PlayMode(binary.BigEndian.Uint16(b[0:])),
+			Begin:    int16(binary.BigEndian.Uint16(b[2:])),
+			End:      int16(binary.BigEndian.Uint16(b[4:])),
+		}
+	}
+	reader.Instrument = &Instrument{
+		BaseNote:     int8(data[0]),
+		Detune:       int8(data[1]),
+		LowNote:      int8(data[2]),
+		HighNote:     int8(data[3]),
+		LowVelocity:  int8(data[4]),
+		HighVelocity: int8(data[5]),
+		Gain:         int16(binary.BigEndian.Uint16(data[6:])),
+		SustainLoop:  loop(data[8:]),
+		ReleaseLoop:  loop(data[14:]),
+	}
+	return nil
+}
+
+// readChunk reads the chunk data with its padding byte.
+func readChunk(r io.Reader, size uint32) ([]byte, error) {
+	data := make([]byte, size+size%2)
+	if _, err := io.ReadFull(r, data); err != nil {
+		return nil, err
+	}
+	return data[:size], nil
+}
+
+// skip discards n bytes of the stream.
+func skip(r io.Reader, n int64) error {
+	if s, ok := r.(io.Seeker); ok {
+		_, err := s.Seek(n, io.SeekCurrent)
+		return err
+	}
+	_, err := io.CopyN(io.Discard, r, n)
+	return err
+}
+
+// Read decodes samples into the dst Buffer. The Buffer must have the same
+// number of channels as the stream. Up to dst.Length() samples per
+// channel are read. Returns a number of samples read per channel and
+// io.EOF when there are no samples left. If the stream ends before the
+// declared size of data, io.ErrUnexpectedEOF is returned.
+func Read[T signal.SignalTypes](r *Reader, dst *signal.Buffer[T]) (int, error) {
+	if dst.Channels() != r.Channels {
+		return 0, ErrChannels
+	}
+	frameSize := r.format.FrameSize(r.Channels)
+	size := dst.Length() * frameSize
+	if int64(size) > r.remaining {
+		size = int(r.remaining) - int(r.remaining)%frameSize
+	}
+	if size <= 0 {
+		return 0, io.EOF
+	}
+	if len(r.buf) < size {
+		r.buf = make([]byte, size)
+	}
+
+	n, err := io.ReadFull(r.r, r.buf[:size])
+	if errors.Is(err, io.EOF) {
+		err = io.ErrUnexpectedEOF
+	}
+	r.remaining -= int64(n)
+	read, _ := pcm.Decode(r.format, r.buf[:n-n%frameSize], dst)
+	return read, err
+}
@@ -0,0 +1,191 @@
+package aiff
+
+import (
+	"encoding/binary"
+	"io"
+
+	"pipelined.dev/signal"
+	"pipelined.dev/signal/pcm"
+)
+
+// Writer encodes samples into the AIFF stream. The sizes of chunks are
+// written when Writer is closed, so the length of the stream doesn't need
+// to be known in advance.
+type Writer struct {
+	Header
+	w      io.WriteSeeker
+	format pcm.Format
+	// start is the position of the header in the underlying writer.
+	start int64
+	// offsets of size fields that are written on Close, relative to
+	// the start.
+	framesOffset int64
+	dataOffset   int64
+	headerSize   int64
+	written      int64
+	buf          []byte
+	padded       bool
+	closed       bool
+}
+
+// NewWriter writes the header of the AIFF stream and returns a Writer.
+// AIFF-C file is written if compression type is set. Markers and
+// instrument are written before the sound data.
+func NewWriter(w io.WriteSeeker, h Header) (*Writer, error) {
+	f, err := h.format()
+	if err != nil {
+		return nil, err
+	}
+	start, err := w.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return nil, err
+	}
+	if f.Encoding == pcm.Float {
+		h.BitDepth = f.BitDepth
+	}
+	aifc := h.Compression != ""
+
+	header := make([]byte, 0, 128)
+	header = append(header, "FORM"...)
+	header = binary.BigEndian.AppendUint32(header, 0)
+	if aifc {
+		header = append(header, "AIFC"...)
+		header = append(header, "FVER"...)
+		header = binary.BigEndian.AppendUint32(header, 4)
+		header = binary.BigEndian.AppendUint32(header, aifcVersion)
+	} else {
+		header = append(header, "AIFF"...)
+	}
+
+	common := binary.BigEndian.AppendUint16(nil, uint16(h.Channels))
+	common = binary.BigEndian.AppendUint32(common, 0)
+	common = binary.BigEndian.AppendUint16(common, uint16(h.BitDepth))
+	rate := putExtended(float64(h.SampleRate))
+	common = append(common, rate[:]...)
+	if aifc {
+		common = append(common, h.Compression...)
+		common = append(common, pstring(h.Compression.name())...)
+	}
+	header = append(header, "COMM"...)
+	header = binary.BigEndian.AppendUint32(header, uint32(len(common)))
+	framesOffset := int64(len(header)) + 2
+	header = append(header, common...)
+
+	if len(h.Markers) > 0 {
+		markers := binary.BigEndian.AppendUint16(nil, uint16(len(h.Markers)))
+		for _, m := range h.Markers {
+			markers = binary.BigEndian.AppendUint16(markers, uint16(m.ID))
+			markers = binary.BigEndian.AppendUint32(markers, m.Position)
+			markers = append(markers, pstring(m.Name)...)
+		}
+		header = append(header, "MARK"...)
+		header = binary.BigEndian.AppendUint32(header, uint32(len(markers)))
+		header = append(header, markers...)
+	}
+
+	if i := h.Instrument; i != nil {
+		header = append(header, "INST"...)
+		header = binary.BigEndian.AppendUint32(header, 20)
+		header = append(header,
+			byte(i.BaseNote),
+			byte(i.Detune),
+			byte(i.LowNote),
+			byte(i.HighNote),
+			byte(i.LowVelocity),
+			byte(i.HighVelocity),
+		)
+		header = binary.BigEndian.AppendUint16(header, uint16(i.Gain))
+		for _, l := range []Loop{i.SustainLoop, i.ReleaseLoop} {
+			header = binary.BigEndian.AppendUint16(header, uint16(l.PlayMode))
+			header = binary.BigEndian.AppendUint16(header, uint16(l.Begin))
+			header = binary.BigEndian.AppendUint16(header, uint16(l.End))
+		}
+	}
+
+	header = append(header, "SSND"...)
+	dataOffset := int64(len(header))
+	header = binary.BigEndian.AppendUint32(header, 8)
+	// offset and block size
+	header = binary.BigEndian.AppendUint32(header, 0)
+	header = binary.BigEndian.AppendUint32(header, 0)
+	if _, err := w.Write(header); err != nil {
+		return nil, err
+	}
+	return &Writer{
+		Header:       h,
+		w:            w,
+		format:       f,
+		start:        start,
+		framesOffset: framesOffset,
+		dataOffset:   dataOffset,
+		headerSize:   int64(len(header)),
+	}, nil
+}
+
+// Write encodes samples of the src Buffer into the stream. The Buffer
+// must have the same number of channels as the stream. Incomplete last
+// frame of the Buffer is not written. Returns a number of samples written
+// per channel.
+func Write[T signal.SignalTypes](w *Writer, src *signal.Buffer[T]) (int, error) {
+	if w.closed {
+		return 0, ErrClosed
+	}
+	if src.Channels() != w.Channels {
+		return 0, ErrChannels
+	}
+	frameSize := w.format.FrameSize(w.Channels)
+	size := src.Len() / w.Channels * frameSize
+	if len(w.buf) < size {
+		w.buf = make([]byte, size)
+	}
+	n, _ := pcm.Encode(w.format, src, w.buf[:size])
+	written, err := w.w.Write(w.buf[:n*frameSize])
+	w.written += int64(written)
+	if err != nil {
+		return written / frameSize, err
+	}
+	return n, nil
+}
+
+// Close writes the sizes of chunks into the header. It doesn't close the
+// underlying writer. If Close fails, it can be called again, otherwise
+// subsequent calls do nothing.
+func (w *Writer) Close() error {
+	if w.closed {
+		return nil
+	}
+	size := w.headerSize + w.written
+	if w.written%2 != 0 {
+		if !w.padded {
+			if _, err := w.w.Write([]byte{0}); err != nil {
+				return err
+			}
+			w.padded = true
+		}
+		size++
+	}
+	if err := w.writeUint32(4, uint32(size-8)); err != nil {
+		return err
+	}
+	frames := w.written / int64(w.format.FrameSize(w.Channels))
+	if err := w.writeUint32(w.framesOffset, uint32(frames)); err != nil {
+		return err
+	}
+	if err := w.writeUint32(w.dataOffset, uint32(w.written+8)); err != nil {
+		return err
+	}
+	if _, err := w.w.Seek(w.start+size, io.SeekStart); err != nil {
+		return err
+	}
+	w.closed = true
+	return nil
+}
+
+// writeUint32 writes the value at the offset relative to the start.
+func (w *Writer) writeUint32(offset int64, v uint32) error {
+	if _, err := w.w.Seek(w.start+offset, io.SeekStart); err != nil {
+		return err
+	}
+	_, err := w.w.Write(binary.BigEndian.AppendUint32(nil, v))
+	return err
+}
@@ -6,20 +6,45 @@ import (
 
 type (
 	// Allocator defines allocation parameters for signal buffers.
+	//
+	// Allocator must be initialized with keyed fields. SampleRate was
+	// appended after Capacity, so unkeyed literals like
+	// Allocator{2, 0, 512} don't compile anymore and should be replaced
+	// with Allocator{Channels: 2, Capacity: 512}. New fields may be
+	// appended in the future.
 	Allocator struct {
 		Channels int
 		Length   int
 		Capacity int
+		// SampleRate is optional. Zero value means that sample rate of
+		// allocated buffers is not set.
+		SampleRate Frequency
 	}
 )
 
 // Alloc acllocates signal buffers based on provided type parameter. Type parameter also determines
 // bit depth of the buffer, ie int8 will be 8 bit depth and int64 is a 64 bit depth.
 func Alloc[T SignalTypes](a Allocator) *Buffer[T] {
+	return alloc[T](a, getBitDepth[T]())
+}
+
+// AllocBitDepth allocates signal buffers with explicitly provided bit
+// depth. It allows to use wider types to hold samples of lower bit depth,
+// ie int32 buffer with 24 bits per sample. Bit depth of fixed-point
+// buffers must be in range [1, max bit depth of the type] and bit depth of
+// floating-point buffers must match the size of the type, otherwise
+// function will panic.
+func AllocBitDepth[T SignalTypes](a Allocator, bd BitDepth) *Buffer[T] {
+	mustValidBitDepth[T](bd)
+	return alloc[T](a, bd)
+}
+
+func alloc[T SignalTypes](a Allocator, bd BitDepth) *Buffer[T] {
 	return &Buffer[T]{
-		data:     make([]T, a.Channels*a.Length, a.Channels*a.Capacity),
-		channels: channels(a.Channels),
-		bitDepth: bitDepth(getBitDepth[T]()),
+		data:       make([]T, a.Channels*a.Length, a.Channels*a.Capacity),
+		channels:   channels(a.Channels),
+		bitDepth:   bitDepth(bd),
+		sampleRate: sampleRate(a.SampleRate),
 	}
 }
 
@@ -32,7 +57,32 @@ func getBitDepth[T SignalTypes]() BitDepth {
 		return BitDepth16
 	case *int32, *uint32, *float32:
 		return BitDepth32
+	case *int64, *uint64, *float64:
+		return BitDepth64
 	default:
 		return strconv.IntSize
 	}
 }
+
+// isFloat reports if the type parameter is a floating-point type.
+func isFloat[T SignalTypes]() bool {
+	var v T = 1
+	v /= 2
+	return v != 0
+}
+
+// mustValidBitDepth panics if bit depth cannot be held by the type.
+func mustValidBitDepth[T SignalTypes](bd BitDepth) {
+	if !validBitDepth[T](bd) {
+		panic(bitDepthOutOfRange)
+	}
+}
+
+// validBitDepth reports if bit depth can be held by the type.
+func validBitDepth[T SignalTypes](bd BitDepth) bool {
+	max := getBitDepth[T]()
+	if isFloat[T]() {
+		return bd == max
+	}
+	return bd != 0 && bd <= max
+}
@@ -13,3 +13,43 @@ func TestAlloc(t *testing.T) {
 		_ *signal.Buffer[int64]   = signal.Alloc[int64](signal.Allocator{})
 	)
 }
+
+func TestAllocBitDepth(t *testing.T) {
+	testOk := func(bd signal.BitDepth, fn func(signal.BitDepth) signal.BitDepth) func(*testing.T) {
+		return func(t *testing.T) {
+			t.Helper()
+			assertEqual(t, "bit depth", fn(bd), bd)
+		}
+	}
+	testPanic := func(bd signal.BitDepth, fn func(signal.BitDepth) signal.BitDepth) func(*testing.T) {
+		return func(t *testing.T) {
+			t.Helper()
+			assertPanic(t, func() {
+				fn(bd)
+			})
+		}
+	}
+	alloc := signal.Allocator{Channels: 2, Length: 4, Capacity: 4}
+	int32Alloc := func(bd signal.BitDepth) signal.BitDepth {
+		return signal.AllocBitDepth[int32](alloc, bd).BitDepth()
+	}
+	int16Alloc := func(bd signal.BitDepth) signal.BitDepth {
+		return signal.AllocBitDepth[int16](alloc, bd).BitDepth()
+	}
+	float32Alloc := func(bd signal.BitDepth) signal.BitDepth {
+		return signal.AllocBitDepth[float32](alloc, bd).BitDepth()
+	}
+	int32Pool := func(bd signal.BitDepth) signal.BitDepth {
+		p := signal.PoolAllocBitDepth[int32](alloc, bd)
+		return p.Get().BitDepth()
+	}
+	t.Run("24 bits in int32", testOk(signal.BitDepth24, int32Alloc))
+	t.Run("20 bits in int32", testOk(20, int32Alloc))
+	t.Run("12 bits in int16", testOk(12, int16Alloc))
+	t.Run("32 bits in float32", testOk(signal.BitDepth32, float32Alloc))
+	t.Run("24 bits in int32 pool", testOk(signal.BitDepth24, int32Pool))
+	t.Run("zero bits", testPanic(0, int32Alloc))
+	t.Run("24 bits in int16", testPanic(signal.BitDepth24, int16Alloc))
+	t.Run("24 bits in float32", testPanic(signal.BitDepth24, float32Alloc))
+	t.Run("64 bits in int32 pool", testPanic(signal.BitDepth64, int32Pool))
+}
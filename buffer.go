@@ -2,14 +2,17 @@ package signal
 
 import (
 	"math"
+	"time"
 )
 
 // Buffer is a buffer that contains digital signal of given type.
 // Each type is associated with certain bit depth, ie: int8 is 8 bits, float32 is 32 bits.
+// Buffer can optionally carry the sample rate of the signal.
 type Buffer[T SignalTypes] struct {
 	channels
 	data []T
 	bitDepth
+	sampleRate
 }
 
 // Slice the Buffer with respect to channels.
@@ -17,12 +20,31 @@ func (b *Buffer[T]) Slice(start, end int) *Buffer[T] {
 	start = b.BufferIndex(0, start)
 	end = b.BufferIndex(0, end)
 	return &Buffer[T]{
-		channels: b.channels,
-		data:     b.data[start:end],
-		bitDepth: b.bitDepth,
+		channels:   b.channels,
+		data:       b.data[start:end],
+		bitDepth:   b.bitDepth,
+		sampleRate: b.sampleRate,
 	}
 }
 
+// SliceDuration slices the Buffer with respect to channels by the time
+// range. Sample rate must be set, otherwise function will panic.
+func (b *Buffer[T]) SliceDuration(start, end time.Duration) *Buffer[T] {
+	if b.sampleRate == 0 {
+		panic(noSampleRate)
+	}
+	return b.Slice(b.SampleRate().Events(start), b.SampleRate().Events(end))
+}
+
+// Duration returns time duration of a single channel. Zero is returned if
+// sample rate is not set.
+func (b *Buffer[T]) Duration() time.Duration {
+	if b.sampleRate == 0 {
+		return 0
+	}
+	return b.SampleRate().Duration(b.Length())
+}
+
 // AppendSample appends sample at the end of the Buffer.
 // Sample is not appended if Buffer capacity is reached.
 func (b *Buffer[T]) AppendSample(v T) {
@@ -69,10 +91,12 @@ func (b *Buffer[T]) Sample(i int) T {
 }
 
 // Append appends [0:Length] samples from src to current Buffer.
-// Both buffers must have same number of channels and
-// bit depth, otherwise function will panic.
+// Both buffers must have same number of channels, bit depth and
+// sample rate, otherwise function will panic.
 func (dst *Buffer[D]) Append(src *Buffer[D]) {
 	mustSame(dst.Channels(), src.Channels(), diffChannels)
+	mustSame(dst.BitDepth(), src.BitDepth(), diffBitDepth)
+	mustSame(dst.SampleRate(), src.SampleRate(), diffSampleRate)
 	offset := dst.Len()
 	if dst.Cap() < dst.Len()+src.Len() {
 		dst.data = append(dst.data, make([]D, src.Len())...)
@@ -85,9 +109,12 @@ func (dst *Buffer[D]) Append(src *Buffer[D]) {
 	alignCapacity(&dst.data, dst.Channels(), dst.Cap())
 }
 
-// Channel returns a single channel of signal Buffer. It behaves exactly as
-// Floating Buffer, but Append and AppendSample cause panic.
+// Channel returns a single channel of signal Buffer. Channel must be in
+// range [0, Channels), otherwise function will panic.
 func (b *Buffer[T]) Channel(c int) C[T] {
+	if c < 0 || c >= b.Channels() {
+		panic(channelOutOfRange)
+	}
 	return C[T]{
 		Buffer:  b,
 		channel: c,
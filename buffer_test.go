@@ -2,6 +2,7 @@ package signal_test
 
 import (
 	"testing"
+	"time"
 
 	"golang.org/x/exp/constraints"
 	"pipelined.dev/signal"
@@ -121,3 +122,60 @@ func TestSlice(t *testing.T) {
 		)
 	}())
 }
+
+func TestSampleRate(t *testing.T) {
+	alloc := signal.Allocator{
+		Channels:   2,
+		Capacity:   100,
+		Length:     100,
+		SampleRate: 1000,
+	}
+	t.Run("duration", func(t *testing.T) {
+		b := signal.Alloc[float64](alloc)
+		assertEqual(t, "sample rate", b.SampleRate(), signal.Frequency(1000))
+		assertEqual(t, "duration", b.Duration(), 100*time.Millisecond)
+		assertEqual(t, "slice duration", b.Slice(10, 30).Duration(), 20*time.Millisecond)
+	})
+	t.Run("duration not set", func(t *testing.T) {
+		b := signal.Alloc[float64](signal.Allocator{Channels: 2, Length: 100, Capacity: 100})
+		assertEqual(t, "duration", b.Duration(), time.Duration(0))
+		assertPanic(t, func() {
+			b.SliceDuration(0, time.Millisecond)
+		})
+	})
+	t.Run("slice duration", func(t *testing.T) {
+		b := signal.Alloc[int32](alloc)
+		for i := 0; i < b.Len(); i++ {
+			b.SetSample(i, int32(i))
+		}
+		s := b.SliceDuration(10*time.Millisecond, 15*time.Millisecond)
+		assertEqual(t, "length", s.Length(), 5)
+		assertEqual(t, "sample rate", s.SampleRate(), signal.Frequency(1000))
+		assertEqual(t, "first sample", s.Sample(0), int32(20))
+	})
+	t.Run("append", func(t *testing.T) {
+		b := signal.Alloc[float64](alloc)
+		b.Append(signal.Alloc[float64](alloc))
+		assertEqual(t, "duration", b.Duration(), 200*time.Millisecond)
+		assertPanic(t, func() {
+			b.Append(signal.Alloc[float64](signal.Allocator{
+				Channels:   2,
+				Length:     10,
+				Capacity:   10,
+				SampleRate: 44100,
+			}))
+		})
+	})
+	t.Run("pool", func(t *testing.T) {
+		p := signal.PoolAlloc[float64](alloc)
+		b := p.Get()
+		assertEqual(t, "sample rate", b.SampleRate(), signal.Frequency(1000))
+		p.Put(b)
+		assertPanic(t, func() {
+			p.Put(signal.Alloc[float64](signal.Allocator{
+				Channels: 2,
+				Capacity: 100,
+			}))
+		})
+	})
+}
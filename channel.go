@@ -1,13 +1,19 @@
 package signal
 
+// C is a single channel of signal Buffer. It doesn't hold any samples,
+// but addresses the samples of the channel in the underlying Buffer, so
+// changes made through C are visible in the Buffer and vice versa. C
+// implements Samples and can be used as a mono Buffer in Read, Write and
+// conversion functions.
 type C[T SignalTypes] struct {
 	Buffer  *Buffer[T]
 	channel int
 }
 
-// BufferIndex returns sample index in the channel of signal Buffer.
+// BufferIndex returns index of the sample in the underlying Buffer. C has
+// a single channel, so the channel argument is ignored.
 func (c C[T]) BufferIndex(channel int, index int) int {
-	return c.channel * index
+	return c.Buffer.BufferIndex(c.channel, index)
 }
 
 // Channels always returns 1.
@@ -15,6 +21,16 @@ func (c C[T]) Channels() int {
 	return 1
 }
 
+// BitDepth returns bit depth of the underlying Buffer.
+func (c C[T]) BitDepth() BitDepth {
+	return c.Buffer.BitDepth()
+}
+
+// SampleRate returns sample rate of the underlying Buffer.
+func (c C[T]) SampleRate() Frequency {
+	return c.Buffer.SampleRate()
+}
+
 // Capacity returns capacity of the channel.
 func (c C[T]) Capacity() int {
 	return c.Buffer.Capacity()
@@ -22,15 +38,53 @@ func (c C[T]) Capacity() int {
 
 // Length returns length of the channel.
 func (c C[T]) Length() int {
-	return c.Buffer.Length()
+	if c.Buffer.Len() <= c.channel {
+		return 0
+	}
+	return ChannelLength(c.Buffer.Len()-c.channel, c.Buffer.Channels())
+}
+
+// Len returns length of the channel. C has a single channel, so it's
+// always equal to Length.
+func (c C[T]) Len() int {
+	return c.Length()
 }
 
-// Sample returns signal value for provided channel and index.
+// Sample returns signal value for provided index in the channel.
 func (c C[T]) Sample(index int) T {
-	return c.Buffer.Sample(index * c.channel)
+	return c.Buffer.Sample(c.BufferIndex(0, index))
 }
 
-// SetSample sets sample value for provided index.
+// SetSample sets sample value for provided index in the channel.
 func (c C[T]) SetSample(index int, s T) {
-	c.Buffer.SetSample(c.Buffer.BufferIndex(c.channel, index), s)
+	c.Buffer.SetSample(c.BufferIndex(0, index), s)
+}
+
+// Slice the channel. The underlying Buffer is sliced with respect to
+// channels, so the result addresses the same samples.
+func (c C[T]) Slice(start, end int) C[T] {
+	return C[T]{
+		Buffer:  c.Buffer.Slice(start, end),
+		channel: c.channel,
+	}
+}
+
+// Read copies [0:Length] samples of the channel into provided slice.
+// Returns a number of samples read.
+func (c C[T]) Read(dst []T) int {
+	length := min(c.Length(), len(dst))
+	for i := 0; i < length; i++ {
+		dst[i] = c.Sample(i)
+	}
+	return length
+}
+
+// Write copies samples from provided slice into [0:Length] samples of
+// the channel. Returns a number of samples written.
+func (c C[T]) Write(src []T) int {
+	length := min(c.Length(), len(src))
+	for i := 0; i < length; i++ {
+		c.SetSample(i, src[i])
+	}
+	return length
 }
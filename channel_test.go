@@ -35,3 +35,68 @@ func TestChannel(t *testing.T) {
 		Capacity: 3,
 	})))
 }
+
+func TestChannelView(t *testing.T) {
+	alloc := signal.Allocator{Channels: 3, Length: 4, Capacity: 4}
+	buf := signal.Alloc[int16](alloc)
+	signal.Write([]int16{
+		0, 10, 20,
+		1, 11, 21,
+		2, 12, 22,
+		3, 13, 23,
+	}, buf)
+
+	t.Run("addressing", func(t *testing.T) {
+		for c := 0; c < buf.Channels(); c++ {
+			result := make([]int16, 4)
+			n := buf.Channel(c).Read(result)
+			assertEqual(t, "read", n, 4)
+			assertEqual(t, "samples", result, []int16{int16(10 * c), int16(10*c + 1), int16(10*c + 2), int16(10*c + 3)})
+		}
+	})
+	t.Run("length", func(t *testing.T) {
+		// the last frame is incomplete.
+		partial := signal.Alloc[int16](alloc)
+		signal.Write([]int16{1, 2, 3, 4}, partial)
+		partial = partial.Slice(0, 2)
+		assertEqual(t, "first", partial.Channel(0).Length(), 2)
+		assertEqual(t, "len", partial.Channel(0).Len(), 2)
+		assertEqual(t, "second", partial.Channel(1).Length(), 2)
+		assertEqual(t, "third", partial.Channel(2).Length(), 2)
+		assertEqual(t, "empty", buf.Slice(0, 0).Channel(2).Length(), 0)
+	})
+	t.Run("slice", func(t *testing.T) {
+		c := buf.Channel(1).Slice(1, 3)
+		assertEqual(t, "length", c.Length(), 2)
+		assertEqual(t, "first", c.Sample(0), int16(11))
+		assertEqual(t, "last", c.Sample(1), int16(12))
+	})
+	t.Run("write", func(t *testing.T) {
+		b := signal.Alloc[int16](alloc)
+		n := b.Channel(2).Write([]int16{1, 2, 3, 4, 5})
+		assertEqual(t, "written", n, 4)
+		result := make([]int16, b.Len())
+		signal.Read(b, result)
+		assertEqual(t, "samples", result, []int16{0, 0, 1, 0, 0, 2, 0, 0, 3, 0, 0, 4})
+	})
+	t.Run("samples", func(t *testing.T) {
+		// channel is accepted as a mono Buffer.
+		mono := signal.Alloc[float64](signal.Allocator{Channels: 1, Length: 4, Capacity: 4})
+		n := signal.SignedAsFloat(buf.Channel(1), mono)
+		assertEqual(t, "converted", n, 4)
+		assertEqual(t, "bit depth", buf.Channel(1).BitDepth(), signal.BitDepth16)
+
+		dst := signal.Alloc[int16](alloc)
+		signal.FloatAsSigned(mono, dst.Channel(0))
+		result := make([]int16, 4)
+		signal.Read(dst.Channel(0), result)
+		assertEqual(t, "round trip", result, []int16{10, 11, 12, 13})
+
+		signal.Write([]int16{7, 8}, dst.Channel(1))
+		assertEqual(t, "write", dst.Sample(dst.BufferIndex(1, 1)), int16(8))
+	})
+	t.Run("out of range", func(t *testing.T) {
+		assertPanic(t, func() { buf.Channel(3) })
+		assertPanic(t, func() { buf.Channel(-1) })
+	})
+}
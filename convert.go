@@ -0,0 +1,57 @@
+package signal
+
+// kind of the signal type.
+type kind uint8
+
+const (
+	floating kind = iota
+	signed
+	unsigned
+)
+
+// Converter converts samples from the source of S type into the
+// destination of D type. The kinds of types are determined once, when
+// Converter is created, so it can be used in generic code without type
+// dispatch overhead.
+type Converter[S, D SignalTypes] struct {
+	src, dst kind
+}
+
+// NewConverter returns Converter for provided type parameters.
+func NewConverter[S, D SignalTypes]() Converter[S, D] {
+	return Converter[S, D]{
+		src: kindOf[S](),
+		dst: kindOf[D](),
+	}
+}
+
+// Convert converts samples from the source into the destination the same
+// way as the corresponding *As* function, ie: FloatAsSigned is used to
+// convert float64 into int32. Source and destination can be of any
+// Samples type, ie: Buffer and Planar. They must have the same number of
+// channels, otherwise function will panic. Returns a number of samples
+// written per channel.
+func (c Converter[S, D]) Convert(src Samples[S], dst Samples[D]) int {
+	return convert(src, dst, c.src, c.dst)
+}
+
+// Convert converts samples from the source into the destination. The
+// conversion function is selected by the type parameters on every call,
+// Converter should be used to select it once. Source and destination
+// must have the same number of channels, otherwise function will panic.
+// Returns a number of samples written per channel.
+func Convert[S, D SignalTypes](src Samples[S], dst Samples[D]) int {
+	return NewConverter[S, D]().Convert(src, dst)
+}
+
+// kindOf returns the kind of the type parameter.
+func kindOf[T SignalTypes]() kind {
+	if isFloat[T]() {
+		return floating
+	}
+	var v T
+	if v-1 < 0 {
+		return signed
+	}
+	return unsigned
+}
@@ -0,0 +1,128 @@
+package signal_test
+
+import (
+	"testing"
+
+	"pipelined.dev/signal"
+)
+
+func TestConvert(t *testing.T) {
+	alloc := signal.Allocator{
+		Channels: 2,
+		Capacity: 3,
+		Length:   3,
+	}
+	floats := signal.Alloc[float64](alloc)
+	signal.Write([]float64{-1, -0.5, 0, 0.25, 0.5, 1}, floats)
+	ints := signal.AllocBitDepth[int32](alloc, signal.BitDepth24)
+	signal.Write([]int32{-8388608, -100, 0, 100, 4194304, 8388607}, ints)
+	uints := signal.Alloc[uint16](alloc)
+	signal.Write([]uint16{0, 100, 32768, 40000, 50000, 65535}, uints)
+
+	testOk := func(converted, expected any) func(*testing.T) {
+		return func(t *testing.T) {
+			t.Helper()
+			assertEqual(t, "converted", converted, expected)
+		}
+	}
+
+	expected, result := signal.Alloc[float32](alloc), signal.Alloc[float32](alloc)
+	signal.FloatAsFloat(floats, expected)
+	signal.Convert(floats, result)
+	t.Run("float as float", testOk(result, expected))
+
+	i8, ri8 := signal.Alloc[int8](alloc), signal.Alloc[int8](alloc)
+	signal.FloatAsSigned(floats, i8)
+	signal.Convert(floats, ri8)
+	t.Run("float as signed", testOk(ri8, i8))
+
+	u8, ru8 := signal.Alloc[uint8](alloc), signal.Alloc[uint8](alloc)
+	signal.FloatAsUnsigned(floats, u8)
+	signal.Convert(floats, ru8)
+	t.Run("float as unsigned", testOk(ru8, u8))
+
+	f64, rf64 := signal.Alloc[float64](alloc), signal.Alloc[float64](alloc)
+	signal.SignedAsFloat(ints, f64)
+	signal.Convert(ints, rf64)
+	t.Run("signed as float", testOk(rf64, f64))
+
+	i16, ri16 := signal.Alloc[int16](alloc), signal.Alloc[int16](alloc)
+	signal.SignedAsSigned(ints, i16)
+	signal.Convert(ints, ri16)
+	t.Run("signed as signed", testOk(ri16, i16))
+
+	u64, ru64 := signal.Alloc[uint64](alloc), signal.Alloc[uint64](alloc)
+	signal.SignedAsUnsigned(ints, u64)
+	signal.Convert(ints, ru64)
+	t.Run("signed as unsigned", testOk(ru64, u64))
+
+	f32, rf32 := signal.Alloc[float32](alloc), signal.Alloc[float32](alloc)
+	signal.UnsignedAsFloat(uints, f32)
+	signal.Convert(uints, rf32)
+	t.Run("unsigned as float", testOk(rf32, f32))
+
+	i64, ri64 := signal.Alloc[int64](alloc), signal.Alloc[int64](alloc)
+	signal.UnsignedAsSigned(uints, i64)
+	signal.Convert(uints, ri64)
+	t.Run("unsigned as signed", testOk(ri64, i64))
+
+	u32, ru32 := signal.AllocBitDepth[uint32](alloc, signal.BitDepth24), signal.AllocBitDepth[uint32](alloc, signal.BitDepth24)
+	signal.UnsignedAsUnsigned(uints, u32)
+	signal.Convert(uints, ru32)
+	t.Run("unsigned as unsigned", testOk(ru32, u32))
+
+	t.Run("channels", func(t *testing.T) {
+		assertPanic(t, func() {
+			signal.Convert(floats, signal.Alloc[int8](signal.Allocator{Channels: 1, Length: 1, Capacity: 1}))
+		})
+	})
+}
+
+func TestConverter(t *testing.T) {
+	alloc := signal.Allocator{
+		Channels: 2,
+		Capacity: 512,
+		Length:   512,
+	}
+	src, dst := signal.Alloc[float32](alloc), signal.Alloc[int16](alloc)
+	c := signal.NewConverter[float32, int16]()
+	allocs := testing.AllocsPerRun(10, func() {
+		c.Convert(src, dst)
+	})
+	assertEqual(t, "allocs", allocs, 0.0)
+	assertEqual(t, "length", c.Convert(src, dst), 512)
+}
+
+func TestConvertSamples(t *testing.T) {
+	// longer than a single chunk of conversion.
+	const length = 1000
+	alloc := signal.Allocator{
+		Channels: 2,
+		Capacity: length,
+		Length:   length,
+	}
+	src := signal.Alloc[int32](alloc)
+	for i := 0; i < src.Len(); i++ {
+		src.SetSample(i, int32(i*1000))
+	}
+	expected := signal.Alloc[float64](alloc)
+	signal.Convert(src, expected)
+
+	planar := signal.AllocPlanar[int32](alloc)
+	signal.Deinterleave(src, planar)
+	fromPlanar := signal.Alloc[float64](alloc)
+	assertEqual(t, "length", signal.Convert(planar, fromPlanar), length)
+	assertEqual(t, "from planar", fromPlanar, expected)
+
+	toPlanar := signal.AllocPlanar[float64](alloc)
+	signal.SignedAsFloat(src, toPlanar)
+	result := signal.Alloc[float64](alloc)
+	signal.Interleave(toPlanar, result)
+	assertEqual(t, "to planar", result, expected)
+
+	c := signal.NewConverter[int32, float64]()
+	allocs := testing.AllocsPerRun(10, func() {
+		c.Convert(planar, toPlanar)
+	})
+	assertEqual(t, "allocs", allocs, 0.0)
+}
@@ -0,0 +1,344 @@
+// Package convolve provides partitioned convolution of signal buffers with
+// long impulse responses.
+//
+// Impulse response is split into partitions, which are convolved in the
+// frequency domain with overlap-save method. The size of the first
+// partitions defines the latency and the following partitions grow up to
+// the maximum size to reduce the cost of long responses:
+//
+//	c, err := convolve.New[float64](2, ir, convolve.Options{Block: 128, MaxBlock: 4096})
+//	if err != nil {
+//		return err
+//	}
+//	c.Process(in, out)
+//
+// Impulse response Buffer defines routing of channels. Every channel is
+// convolved with its own channel of response if they have the same number
+// of channels, and with the only channel of mono response. Stereo signal
+// is convolved with 4-channel true-stereo response, where channels are
+// left-to-left, left-to-right, right-to-left and right-to-right responses.
+package convolve
+
+import (
+	"errors"
+
+	"golang.org/x/exp/constraints"
+	"pipelined.dev/signal"
+	"pipelined.dev/signal/fft"
+)
+
+var (
+	// ErrChannels is returned when number of channels is not positive.
+	ErrChannels = errors.New("convolve: invalid number of channels")
+	// ErrImpulse is returned when impulse response is empty.
+	ErrImpulse = errors.New("convolve: empty impulse response")
+	// ErrRouting is returned when number of channels of impulse response
+	// doesn't match the number of channels.
+	ErrRouting = errors.New("convolve: unsupported channels of impulse response")
+	// ErrBlock is returned when block size is not positive or maximum
+	// block size is not a power of two multiple of block size.
+	ErrBlock = errors.New("convolve: invalid block size")
+)
+
+// panic messages.
+const (
+	diffChannels = "convolve: different number of channels"
+)
+
+// Options of the convolver.
+type Options struct {
+	// Block is the size of the first partitions. It defines the latency
+	// of the convolver.
+	Block int
+	// MaxBlock is the size of the largest partitions, it must be a power
+	// of two multiple of Block. Sizes of partitions are doubled after
+	// every two partitions until MaxBlock is reached. Zero value results
+	// in uniform partitions of the Block size.
+	MaxBlock int
+}
+
+// route defines the channel of impulse response that is applied to the
+// input channel and added to the output channel.
+type route struct {
+	in, out, ir int
+}
+
+// Convolver is a streaming partitioned convolution processor. It's not
+// safe for concurrent use.
+type Convolver[T constraints.Float] struct {
+	channels int
+	block    int
+	routes   []route
+	segments []*segment
+	// input is the current block of input samples for every channel.
+	input [][]float64
+	// output is the previous block of output samples for every channel.
+	output [][]float64
+	pos    int
+	// accumulator is a ring of future output samples for every channel.
+	accumulator [][]float64
+	head        int
+}
+
+// segment is a uniformly partitioned part of impulse response.
+type segment struct {
+	block  int
+	offset int
+	plan   *fft.RealPlan
+	// spectra of partitions for every route.
+	partitions [][][]complex128
+	// frames of input samples of size 2*block for every channel.
+	frames [][]float64
+	filled int
+	// delay line of input spectra for every channel.
+	delay  [][][]complex128
+	cursor int
+	// scratch buffers.
+	spectra [][]complex128
+	result  []float64
+}
+
+// New returns Convolver for provided number of channels with the impulse
+// response.
+func New[T constraints.Float](channels int, ir *signal.Buffer[T], opts Options) (*Convolver[T], error) {
+	if channels <= 0 {
+		return nil, ErrChannels
+	}
+	if ir.Length() == 0 {
+		return nil, ErrImpulse
+	}
+	routes, ok := routing(channels, ir.Channels())
+	if !ok {
+		return nil, ErrRouting
+	}
+	maxBlock := opts.MaxBlock
+	if maxBlock == 0 {
+		maxBlock = opts.Block
+	}
+	if opts.Block <= 0 || maxBlock < opts.Block || maxBlock%opts.Block != 0 || !powerOfTwo(maxBlock/opts.Block) {
+		return nil, ErrBlock
+	}
+
+	c := Convolver[T]{
+		channels:    channels,
+		block:       opts.Block,
+		routes:      routes,
+		input:       make([][]float64, channels),
+		output:      make([][]float64, channels),
+		accumulator: make([][]float64, channels),
+	}
+	// partitions grow after every two partitions.
+	length := ir.Length()
+	offset, ring := 0, opts.Block
+	for size := opts.Block; offset < length; size *= 2 {
+		count := 2
+		if size >= maxBlock {
+			size = maxBlock
+			count = (length - offset + size - 1) / size
+		}
+		s, err := newSegment(ir, routes, channels, offset, size, count)
+		if err != nil {
+			return nil, err
+		}
+		c.segments = append(c.segments, s)
+		ring = max(ring, opts.Block+offset)
+		offset += size * count
+	}
+	for ch := 0; ch < channels; ch++ {
+		c.input[ch] = make([]float64, opts.Block)
+		c.output[ch] = make([]float64, opts.Block)
+		c.accumulator[ch] = make([]float64, ring)
+	}
+	return &c, nil
+}
+
+// Latency returns a delay of output signal in samples.
+func (c *Convolver[T]) Latency() int {
+	return c.block
+}
+
+// Process convolves [0:Length] samples of src Buffer and writes the
+// result into dst Buffer. Buffers can be the same. Buffers must have the
+// same number of channels as the convolver, otherwise function will
+// panic. Returns a number of samples processed per channel.
+func (c *Convolver[T]) Process(src, dst *signal.Buffer[T]) int {
+	mustSame(c.channels, src.Channels(), diffChannels)
+	mustSame(c.channels, dst.Channels(), diffChannels)
+	length := min(src.Length(), dst.Length())
+	for i := 0; i < length; i++ {
+		for ch := 0; ch < c.channels; ch++ {
+			c.input[ch][c.pos] = float64(src.Sample(src.BufferIndex(ch, i)))
+			dst.SetSample(dst.BufferIndex(ch, i), T(c.output[ch][c.pos]))
+		}
+		c.pos++
+		if c.pos == c.block {
+			c.processBlock()
+			c.pos = 0
+		}
+	}
+	return length
+}
+
+// Reset discards the state of convolver, so it can be used for a new
+// stream.
+func (c *Convolver[T]) Reset() {
+	for ch := 0; ch < c.channels; ch++ {
+		clear(c.input[ch])
+		clear(c.output[ch])
+		clear(c.accumulator[ch])
+	}
+	for _, s := range c.segments {
+		s.reset()
+	}
+	c.pos = 0
+	c.head = 0
+}
+
+// processBlock passes the complete input block to segments and moves the
+// next output block out of accumulator.
+func (c *Convolver[T]) processBlock() {
+	for _, s := range c.segments {
+		s.write(c.input, c.routes, c.accumulator, c.head)
+	}
+	for ch := range c.accumulator {
+		acc := c.accumulator[ch]
+		for i := range c.output[ch] {
+			idx := (c.head + i) % len(acc)
+			c.output[ch][i] = acc[idx]
+			acc[idx] = 0
+		}
+	}
+	c.head = (c.head + c.block) % len(c.accumulator[0])
+}
+
+func newSegment[T constraints.Float](ir *signal.Buffer[T], routes []route, channels, offset, block, count int) (*segment, error) {
+	plan, err := fft.NewRealPlan(2 * block)
+	if err != nil {
+		return nil, err
+	}
+	s := segment{
+		block:      block,
+		offset:     offset,
+		plan:       plan,
+		partitions: make([][][]complex128, len(routes)),
+		frames:     make([][]float64, channels),
+		delay:      make([][][]complex128, channels),
+		spectra:    make([][]complex128, channels),
+		result:     make([]float64, 2*block),
+	}
+	frame := make([]float64, 2*block)
+	for r, rt := range routes {
+		s.partitions[r] = make([][]complex128, count)
+		for p := range s.partitions[r] {
+			clear(frame)
+			start := offset + p*block
+			for i := 0; i < block && start+i < ir.Length(); i++ {
+				frame[i] = float64(ir.Sample(ir.BufferIndex(rt.ir, start+i)))
+			}
+			s.partitions[r][p] = make([]complex128, plan.SpectrumLen())
+			plan.Forward(s.partitions[r][p], frame)
+		}
+	}
+	for ch := 0; ch < channels; ch++ {
+		s.frames[ch] = make([]float64, 2*block)
+		s.spectra[ch] = make([]complex128, plan.SpectrumLen())
+		s.delay[ch] = make([][]complex128, count)
+		for p := range s.delay[ch] {
+			s.delay[ch][p] = make([]complex128, plan.SpectrumLen())
+		}
+	}
+	return &s, nil
+}
+
+// write appends the input block to frames. When frames are filled, the
+// block of output is computed and added to accumulator at the segment
+// offset.
+func (s *segment) write(input [][]float64, routes []route, accumulator [][]float64, head int) {
+	for ch := range input {
+		copy(s.frames[ch][s.block+s.filled:], input[ch])
+	}
+	s.filled += len(input[0])
+	if s.filled < s.block {
+		return
+	}
+	s.filled = 0
+
+	// the newest spectrum replaces the oldest in the delay line.
+	count := len(s.delay[0])
+	s.cursor = (s.cursor + count - 1) % count
+	for ch := range s.frames {
+		s.plan.Forward(s.delay[ch][s.cursor], s.frames[ch])
+		copy(s.frames[ch], s.frames[ch][s.block:])
+	}
+	// output samples of the block are delayed by the segment offset
+	// relative to the start of the last input block.
+	base := len(input[0]) - s.block + s.offset
+	for out := range s.spectra {
+		spectrum := s.spectra[out]
+		clear(spectrum)
+		for r, rt := range routes {
+			if rt.out != out {
+				continue
+			}
+			for p, h := range s.partitions[r] {
+				x := s.delay[rt.in][(s.cursor+p)%count]
+				for k := range spectrum {
+					spectrum[k] += x[k] * h[k]
+				}
+			}
+		}
+		// first half of circular convolution is aliased.
+		s.plan.Inverse(s.result, spectrum)
+		acc := accumulator[out]
+		for i, v := range s.result[s.block:] {
+			acc[(head+base+i)%len(acc)] += v
+		}
+	}
+}
+
+func (s *segment) reset() {
+	for ch := range s.frames {
+		clear(s.frames[ch])
+		for p := range s.delay[ch] {
+			clear(s.delay[ch][p])
+		}
+	}
+	s.filled = 0
+	s.cursor = 0
+}
+
+// routing returns routes for the number of channels of signal and
+// impulse response.
+func routing(channels, irChannels int) ([]route, bool) {
+	var routes []route
+	switch {
+	case irChannels == channels:
+		for c := 0; c < channels; c++ {
+			routes = append(routes, route{in: c, out: c, ir: c})
+		}
+	case irChannels == 1:
+		for c := 0; c < channels; c++ {
+			routes = append(routes, route{in: c, out: c, ir: 0})
+		}
+	case irChannels == 4 && channels == 2:
+		routes = []route{
+			{in: 0, out: 0, ir: 0},
+			{in: 0, out: 1, ir: 1},
+			{in: 1, out: 0, ir: 2},
+			{in: 1, out: 1, ir: 3},
+		}
+	default:
+		return nil, false
+	}
+	return routes, true
+}
+
+func powerOfTwo(v int) bool {
+	return v > 0 && v&(v-1) == 0
+}
+
+func mustSame[T comparable](a, b T, panicStr string) {
+	if a != b {
+		panic(panicStr)
+	}
+}
@@ -0,0 +1,142 @@
+package convolve_test
+
+import (
+	"math"
+	"math/rand"
+	"reflect"
+	"testing"
+
+	"pipelined.dev/signal"
+	"pipelined.dev/signal/convolve"
+)
+
+func TestConvolver(t *testing.T) {
+	const length = 8000
+	random := func(channels, length int, seed int64) *signal.Buffer[float64] {
+		b := signal.Alloc[float64](signal.Allocator{Channels: channels, Length: length, Capacity: length})
+		r := rand.New(rand.NewSource(seed))
+		for i := 0; i < b.Len(); i++ {
+			b.SetSample(i, r.Float64()*2-1)
+		}
+		return b
+	}
+	// direct is a direct computation of linear convolution of the
+	// channel with the channel of impulse response.
+	direct := func(dst, src, ir *signal.Buffer[float64], in, out, channel, delay int) {
+		for i := delay; i < dst.Length(); i++ {
+			var sum float64
+			for k := 0; k < ir.Length() && k <= i-delay; k++ {
+				sum += ir.Sample(ir.BufferIndex(channel, k)) * src.Sample(src.BufferIndex(in, i-delay-k))
+			}
+			idx := dst.BufferIndex(out, i)
+			dst.SetSample(idx, dst.Sample(idx)+sum)
+		}
+	}
+	testOk := func(channels int, ir *signal.Buffer[float64], opts convolve.Options, chunk int) func(*testing.T) {
+		return func(t *testing.T) {
+			t.Helper()
+			input := random(channels, length, 1)
+			expected := signal.Alloc[float64](signal.Allocator{Channels: channels, Length: length, Capacity: length})
+			switch ir.Channels() {
+			case channels:
+				for c := 0; c < channels; c++ {
+					direct(expected, input, ir, c, c, c, opts.Block)
+				}
+			case 1:
+				for c := 0; c < channels; c++ {
+					direct(expected, input, ir, c, c, 0, opts.Block)
+				}
+			case 4:
+				direct(expected, input, ir, 0, 0, 0, opts.Block)
+				direct(expected, input, ir, 0, 1, 1, opts.Block)
+				direct(expected, input, ir, 1, 0, 2, opts.Block)
+				direct(expected, input, ir, 1, 1, 3, opts.Block)
+			}
+
+			c, err := convolve.New[float64](channels, ir, opts)
+			assertNoError(t, err)
+			assertEqual(t, "latency", c.Latency(), opts.Block)
+			output := signal.Alloc[float64](signal.Allocator{Channels: channels, Length: length, Capacity: length})
+			for offset := 0; offset < length; offset += chunk {
+				end := min(offset+chunk, length)
+				assertEqual(t, "processed", c.Process(input.Slice(offset, end), output.Slice(offset, end)), end-offset)
+			}
+			assertClose(t, output, expected)
+
+			// in-place processing after reset.
+			c.Reset()
+			c.Process(input, input)
+			assertClose(t, input, expected)
+		}
+	}
+	t.Run("uniform mono", testOk(1, random(1, 1000, 2), convolve.Options{Block: 64}, 64))
+	t.Run("uniform short", testOk(2, random(1, 10, 2), convolve.Options{Block: 64}, 100))
+	t.Run("non-uniform mono", testOk(1, random(1, 5000, 2), convolve.Options{Block: 32, MaxBlock: 512}, 50))
+	t.Run("non-uniform parallel", testOk(2, random(2, 3000, 2), convolve.Options{Block: 16, MaxBlock: 256}, 1000))
+	t.Run("non-uniform true stereo", testOk(2, random(4, 3000, 2), convolve.Options{Block: 64, MaxBlock: 1024}, 7))
+	t.Run("shared mono", testOk(3, random(1, 300, 2), convolve.Options{Block: 32, MaxBlock: 64}, 33))
+
+	testError := func(channels, irChannels int, opts convolve.Options, expected error) func(*testing.T) {
+		return func(t *testing.T) {
+			t.Helper()
+			_, err := convolve.New[float64](channels, random(irChannels, 100, 1), opts)
+			assertEqual(t, "error", err, expected)
+		}
+	}
+	t.Run("channels", testError(0, 1, convolve.Options{Block: 64}, convolve.ErrChannels))
+	t.Run("routing", testError(3, 2, convolve.Options{Block: 64}, convolve.ErrRouting))
+	t.Run("true stereo routing", testError(1, 4, convolve.Options{Block: 64}, convolve.ErrRouting))
+	t.Run("zero block", testError(1, 1, convolve.Options{}, convolve.ErrBlock))
+	t.Run("max block smaller", testError(1, 1, convolve.Options{Block: 64, MaxBlock: 32}, convolve.ErrBlock))
+	t.Run("max block not power of two", testError(1, 1, convolve.Options{Block: 64, MaxBlock: 192}, convolve.ErrBlock))
+	_, err := convolve.New[float64](1, random(1, 0, 1), convolve.Options{Block: 64})
+	assertEqual(t, "impulse error", err, convolve.ErrImpulse)
+
+	c, _ := convolve.New[float64](2, random(1, 100, 1), convolve.Options{Block: 64})
+	assertPanic(t, func() {
+		c.Process(random(1, 10, 1), random(2, 10, 1))
+	})
+}
+
+func BenchmarkConvolver(b *testing.B) {
+	ir := signal.Alloc[float64](signal.Allocator{Channels: 4, Length: 96000, Capacity: 96000})
+	c, _ := convolve.New[float64](2, ir, convolve.Options{Block: 128, MaxBlock: 8192})
+	buf := signal.Alloc[float64](signal.Allocator{Channels: 2, Length: 4096, Capacity: 4096})
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		c.Process(buf, buf)
+	}
+}
+
+func assertClose(t *testing.T, result, expected *signal.Buffer[float64]) {
+	t.Helper()
+	for i := 0; i < expected.Len(); i++ {
+		if math.Abs(result.Sample(i)-expected.Sample(i)) > 1e-9 {
+			t.Fatalf("sample %d: %v expected: %v", i, result.Sample(i), expected.Sample(i))
+		}
+	}
+}
+
+func assertEqual(t *testing.T, name string, result, expected interface{}) {
+	t.Helper()
+	if !reflect.DeepEqual(expected, result) {
+		t.Fatalf("%v\nresult: \t%T\t%+v \nexpected: \t%T\t%+v", name, result, result, expected, expected)
+	}
+}
+
+func assertNoError(t *testing.T, err error) {
+	t.Helper()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func assertPanic(t *testing.T, fn func()) {
+	t.Helper()
+	defer func() {
+		if r := recover(); r == nil {
+			t.Fatalf("didn't panic")
+		}
+	}()
+	fn()
+}
@@ -0,0 +1,293 @@
+package signal
+
+import (
+	"math"
+	"math/rand"
+
+	"golang.org/x/exp/constraints"
+)
+
+// DitherNoise defines the distribution of noise added to the signal
+// before quantization.
+type DitherNoise uint8
+
+const (
+	// NoDither disables dither noise, samples are rounded to the nearest
+	// quantization value.
+	NoDither DitherNoise = iota
+	// Rectangular is a noise with uniform distribution and 1 LSB
+	// peak-to-peak amplitude.
+	Rectangular
+	// Triangular is a noise with triangular probability density function
+	// and 2 LSB peak-to-peak amplitude. It makes the quantization error
+	// independent of the signal.
+	Triangular
+	// HighPass is a triangular noise with spectrum tilted to high
+	// frequencies, which makes it less audible.
+	HighPass
+)
+
+// NoiseShaping is a set of error feedback filter coefficients. The
+// quantization error of previous samples is filtered and subtracted from
+// the signal, so the spectrum of the error is shaped by the filter
+// 1 - c[0]*z^-1 - c[1]*z^-2 - ...
+type NoiseShaping []float64
+
+var (
+	// FirstOrderShaping moves the quantization noise to high frequencies
+	// with first-order differentiator.
+	FirstOrderShaping = NoiseShaping{1}
+	// SecondOrderShaping moves the quantization noise to high frequencies
+	// with second-order differentiator.
+	SecondOrderShaping = NoiseShaping{2, -1}
+	// LipshitzShaping is a psychoacoustically optimized 5-tap E-weighted
+	// filter for 44.1 kHz sample rate.
+	LipshitzShaping = NoiseShaping{2.033, -2.165, 1.959, -1.590, 0.6149}
+)
+
+// Dither holds the state of dithering and noise shaping applied when
+// samples are quantized to the lower bit depth. Random numbers are
+// produced by the generator with provided seed, so the output is
+// reproducible. State is kept per channel, so the Dither should be used
+// for a single stream. It's not safe for concurrent use.
+type Dither struct {
+	noise   DitherNoise
+	shaping NoiseShaping
+	seed    int64
+	rand    *rand.Rand
+	// channels state: previous random values of high-pass noise and
+	// quantization errors history.
+	prev []float64
+	errs [][]float64
+}
+
+// NewDither returns Dither with provided noise, noise shaping and seed
+// for the random numbers generator. Shaping can be nil to disable noise
+// shaping.
+func NewDither(noise DitherNoise, shaping NoiseShaping, seed int64) *Dither {
+	return &Dither{
+		noise:   noise,
+		shaping: shaping,
+		seed:    seed,
+		rand:    rand.New(rand.NewSource(seed)),
+	}
+}
+
+// Reset discards the state of noise shaping and restarts the random
+// numbers generator with initial seed.
+func (d *Dither) Reset() {
+	d.rand.Seed(d.seed)
+	d.prev = nil
+	d.errs = nil
+}
+
+// FloatAsSignedDither converts floating-point samples into signed
+// fixed-point the same way as FloatAsSigned, but samples are dithered and
+// rounded instead of truncation. Buffers must have the same number of
+// channels, otherwise function will panic. Returns a number of samples
+// written per channel.
+func FloatAsSignedDither[S constraints.Float, D constraints.Signed](src Samples[S], dst Samples[D], d *Dither) int {
+	mustSame(src.Channels(), dst.Channels(), diffChannels)
+	// cap length to destination capacity.
+	length := min(src.Len(), dst.Len())
+	if length == 0 {
+		return 0
+	}
+	d.init(dst.Channels())
+	msv := float64(dst.BitDepth().MaxSignedValue())
+	lo, hi := -msv-1, msv
+	for i := 0; i < length; i++ {
+		dst.SetSample(i, D(d.quantize(i%dst.Channels(), floatValue(float64(src.Sample(i)), msv), lo, hi)))
+	}
+	return min(src.Length(), dst.Length())
+}
+
+// FloatAsUnsignedDither converts floating-point samples into unsigned
+// fixed-point the same way as FloatAsUnsigned, but samples are dithered
+// and rounded instead of truncation. Buffers must have the same number of
+// channels, otherwise function will panic. Returns a number of samples
+// written per channel.
+func FloatAsUnsignedDither[S constraints.Float, D constraints.Unsigned](src Samples[S], dst Samples[D], d *Dither) int {
+	mustSame(src.Channels(), dst.Channels(), diffChannels)
+	// cap length to destination capacity.
+	length := min(src.Len(), dst.Len())
+	if length == 0 {
+		return 0
+	}
+	d.init(dst.Channels())
+	msv := float64(dst.BitDepth().MaxSignedValue())
+	lo, hi := -msv-1, msv
+	offset := uint64(dst.BitDepth().MaxSignedValue()) + 1
+	for i := 0; i < length; i++ {
+		sample := d.quantize(i%dst.Channels(), floatValue(float64(src.Sample(i)), msv), lo, hi)
+		dst.SetSample(i, D(uint64(int64(sample))+offset))
+	}
+	return min(src.Length(), dst.Length())
+}
+
+// SignedAsSignedDither appends signed fixed-point samples to the signed
+// fixed-point destination Buffer. If destination bit depth is lower,
+// samples are dithered and rounded, otherwise it behaves exactly as
+// SignedAsSigned. Buffers must have the same number of channels,
+// otherwise function will panic. Returns a number of samples written per
+// channel.
+func SignedAsSignedDither[S, D constraints.Signed](src Samples[S], dst Samples[D], d *Dither) int {
+	if src.BitDepth() <= dst.BitDepth() {
+		return SignedAsSigned(src, dst)
+	}
+	mustSame(src.Channels(), dst.Channels(), diffChannels)
+	// cap length to destination capacity.
+	length := min(src.Len(), dst.Len())
+	if length == 0 {
+		return 0
+	}
+	d.init(dst.Channels())
+	scale := float64(Scale[uint64](src.BitDepth(), dst.BitDepth()))
+	lo, hi := float64(dst.BitDepth().MinSignedValue()), float64(dst.BitDepth().MaxSignedValue())
+	for i := 0; i < length; i++ {
+		dst.SetSample(i, D(d.quantize(i%dst.Channels(), float64(src.Sample(i))/scale, lo, hi)))
+	}
+	return min(src.Length(), dst.Length())
+}
+
+// SignedAsUnsignedDither converts signed fixed-point samples into
+// unsigned fixed-point. If destination bit depth is lower, samples are
+// dithered and rounded, otherwise it behaves exactly as SignedAsUnsigned.
+// Buffers must have the same number of channels, otherwise function will
+// panic. Returns a number of samples written per channel.
+func SignedAsUnsignedDither[S constraints.Signed, D constraints.Unsigned](src Samples[S], dst Samples[D], d *Dither) int {
+	if src.BitDepth() <= dst.BitDepth() {
+		return SignedAsUnsigned(src, dst)
+	}
+	mustSame(src.Channels(), dst.Channels(), diffChannels)
+	// cap length to destination capacity.
+	length := min(src.Len(), dst.Len())
+	if length == 0 {
+		return 0
+	}
+	d.init(dst.Channels())
+	scale := float64(Scale[uint64](src.BitDepth(), dst.BitDepth()))
+	lo, hi := float64(dst.BitDepth().MinSignedValue()), float64(dst.BitDepth().MaxSignedValue())
+	offset := uint64(dst.BitDepth().MaxSignedValue()) + 1
+	for i := 0; i < length; i++ {
+		sample := d.quantize(i%dst.Channels(), float64(src.Sample(i))/scale, lo, hi)
+		dst.SetSample(i, D(uint64(int64(sample))+offset))
+	}
+	return min(src.Length(), dst.Length())
+}
+
+// UnsignedAsSignedDither converts unsigned fixed-point samples into
+// signed fixed-point. If destination bit depth is lower, samples are
+// dithered and rounded, otherwise it behaves exactly as UnsignedAsSigned.
+// Buffers must have the same number of channels, otherwise function will
+// panic. Returns a number of samples written per channel.
+func UnsignedAsSignedDither[S constraints.Unsigned, D constraints.Signed](src Samples[S], dst Samples[D], d *Dither) int {
+	if src.BitDepth() <= dst.BitDepth() {
+		return UnsignedAsSigned(src, dst)
+	}
+	mustSame(src.Channels(), dst.Channels(), diffChannels)
+	// cap length to destination capacity.
+	length := min(src.Len(), dst.Len())
+	if length == 0 {
+		return 0
+	}
+	d.init(dst.Channels())
+	scale := float64(Scale[uint64](src.BitDepth(), dst.BitDepth()))
+	lo, hi := float64(dst.BitDepth().MinSignedValue()), float64(dst.BitDepth().MaxSignedValue())
+	offset := uint64(src.BitDepth().MaxSignedValue()) + 1
+	for i := 0; i < length; i++ {
+		sample := float64(int64(uint64(src.Sample(i))-offset)) / scale
+		dst.SetSample(i, D(d.quantize(i%dst.Channels(), sample, lo, hi)))
+	}
+	return min(src.Length(), dst.Length())
+}
+
+// UnsignedAsUnsignedDither appends unsigned fixed-point samples to the
+// unsigned fixed-point destination Buffer. If destination bit depth is
+// lower, samples are dithered and rounded, otherwise it behaves exactly
+// as UnsignedAsUnsigned. Buffers must have the same number of channels,
+// otherwise function will panic. Returns a number of samples written per
+// channel.
+func UnsignedAsUnsignedDither[S, D constraints.Unsigned](src Samples[S], dst Samples[D], d *Dither) int {
+	if src.BitDepth() <= dst.BitDepth() {
+		return UnsignedAsUnsigned(src, dst)
+	}
+	mustSame(src.Channels(), dst.Channels(), diffChannels)
+	// cap length to destination capacity.
+	length := min(src.Len(), dst.Len())
+	if length == 0 {
+		return 0
+	}
+	d.init(dst.Channels())
+	scale := float64(Scale[uint64](src.BitDepth(), dst.BitDepth()))
+	lo, hi := float64(dst.BitDepth().MinSignedValue()), float64(dst.BitDepth().MaxSignedValue())
+	srcOffset := uint64(src.BitDepth().MaxSignedValue()) + 1
+	dstOffset := uint64(dst.BitDepth().MaxSignedValue()) + 1
+	for i := 0; i < length; i++ {
+		// dither is applied to the signal centered around zero.
+		sample := float64(int64(uint64(src.Sample(i))-srcOffset)) / scale
+		sample = d.quantize(i%dst.Channels(), sample, lo, hi)
+		dst.SetSample(i, D(uint64(int64(sample))+dstOffset))
+	}
+	return min(src.Length(), dst.Length())
+}
+
+// floatValue maps floating-point sample to the signed fixed-point range
+// without quantization.
+func floatValue(f, msv float64) float64 {
+	if f > 0 {
+		return f * msv
+	}
+	return f * (msv + 1)
+}
+
+// init allocates the state for provided number of channels.
+func (d *Dither) init(channels int) {
+	if len(d.errs) == channels {
+		return
+	}
+	d.prev = make([]float64, channels)
+	d.errs = make([][]float64, channels)
+	for c := range d.errs {
+		d.errs[c] = make([]float64, len(d.shaping))
+	}
+}
+
+// quantize applies noise shaping and dither to the value of the channel
+// and rounds it to the nearest integer within [lo, hi] range.
+func (d *Dither) quantize(channel int, v, lo, hi float64) float64 {
+	errs := d.errs[channel]
+	for k, c := range d.shaping {
+		v -= c * errs[k]
+	}
+	q := math.Round(v + d.dither(channel))
+	if len(errs) > 0 {
+		copy(errs[1:], errs)
+		errs[0] = q - v
+	}
+	switch {
+	case q < lo:
+		return lo
+	case q >= hi:
+		// the largest value of 64 bits is not representable by float64
+		// and must not overflow on conversion.
+		return math.Min(hi, math.Nextafter(hi+1, 0))
+	}
+	return q
+}
+
+// dither returns the noise value for the channel.
+func (d *Dither) dither(channel int) float64 {
+	switch d.noise {
+	case Rectangular:
+		return d.rand.Float64() - 0.5
+	case Triangular:
+		return d.rand.Float64() - d.rand.Float64()
+	case HighPass:
+		r := d.rand.Float64() - 0.5
+		v := r - d.prev[channel]
+		d.prev[channel] = r
+		return v
+	}
+	return 0
+}
@@ -0,0 +1,176 @@
+package signal_test
+
+import (
+	"math"
+	"testing"
+
+	"pipelined.dev/signal"
+)
+
+func TestDither(t *testing.T) {
+	alloc := signal.Allocator{
+		Channels: 2,
+		Length:   4096,
+		Capacity: 4096,
+	}
+	// constant signal at the 0.3 of 8-bit LSB.
+	level := 0.3 / 128
+	input := signal.Alloc[float64](alloc)
+	for i := 0; i < input.Len(); i++ {
+		input.SetSample(i, level)
+	}
+	mean := func(b *signal.Buffer[int8]) float64 {
+		var sum float64
+		for i := 0; i < b.Len(); i++ {
+			sum += float64(b.Sample(i))
+		}
+		return sum / float64(b.Len())
+	}
+
+	t.Run("truncation", func(t *testing.T) {
+		output := signal.Alloc[int8](alloc)
+		signal.FloatAsSigned(input, output)
+		assertEqual(t, "mean", mean(output), 0.0)
+	})
+	t.Run("no dither", func(t *testing.T) {
+		output := signal.Alloc[int8](alloc)
+		signal.FloatAsSignedDither(input, output, signal.NewDither(signal.NoDither, nil, 1))
+		assertEqual(t, "mean", mean(output), 0.0)
+	})
+	for _, noise := range []signal.DitherNoise{signal.Rectangular, signal.Triangular, signal.HighPass} {
+		output := signal.Alloc[int8](alloc)
+		n := signal.FloatAsSignedDither(input, output, signal.NewDither(noise, nil, 1))
+		assertEqual(t, "length", n, alloc.Length)
+		if m := mean(output); math.Abs(m-0.3) > 0.05 {
+			t.Fatalf("noise %v: invalid mean: %v", noise, m)
+		}
+	}
+	t.Run("reproducible", func(t *testing.T) {
+		d := signal.NewDither(signal.Triangular, signal.SecondOrderShaping, 42)
+		result1 := signal.Alloc[int8](alloc)
+		signal.FloatAsSignedDither(input, result1, d)
+		d.Reset()
+		result2 := signal.Alloc[int8](alloc)
+		signal.FloatAsSignedDither(input, result2, d)
+		result3 := signal.Alloc[int8](alloc)
+		signal.FloatAsSignedDither(input, result3, signal.NewDither(signal.Triangular, signal.SecondOrderShaping, 42))
+		assertEqual(t, "reset", result2, result1)
+		assertEqual(t, "new", result3, result1)
+	})
+}
+
+func TestNoiseShaping(t *testing.T) {
+	alloc := signal.Allocator{
+		Channels: 1,
+		Length:   8192,
+		Capacity: 8192,
+	}
+	input := signal.Alloc[float64](alloc)
+	for i := 0; i < input.Len(); i++ {
+		input.SetSample(i, 0.5*math.Sin(2*math.Pi*float64(i)/100))
+	}
+	// lowRatio returns the ratio of low to high frequency energy of the
+	// quantization error.
+	lowRatio := func(shaping signal.NoiseShaping) float64 {
+		output := signal.Alloc[int8](alloc)
+		signal.FloatAsSignedDither(input, output, signal.NewDither(signal.Triangular, shaping, 1))
+		errs := make([]float64, output.Len())
+		for i := range errs {
+			errs[i] = float64(output.Sample(i)) - input.Sample(i)*128
+		}
+		var low, high float64
+		for i := 1; i < len(errs); i++ {
+			low += (errs[i] + errs[i-1]) * (errs[i] + errs[i-1])
+			high += (errs[i] - errs[i-1]) * (errs[i] - errs[i-1])
+		}
+		return low / high
+	}
+	flat := lowRatio(nil)
+	if flat < 0.5 || flat > 2 {
+		t.Fatalf("unshaped error is not white: %v", flat)
+	}
+	first := lowRatio(signal.FirstOrderShaping)
+	second := lowRatio(signal.SecondOrderShaping)
+	if first > flat/2 || second > first {
+		t.Fatalf("error is not shaped: flat %v first %v second %v", flat, first, second)
+	}
+}
+
+func TestDitherConversions(t *testing.T) {
+	alloc := signal.Allocator{
+		Channels: 1,
+		Length:   5,
+		Capacity: 5,
+	}
+	noDither := func() *signal.Dither {
+		return signal.NewDither(signal.NoDither, nil, 0)
+	}
+	t.Run("float unsigned", func(t *testing.T) {
+		input := signal.Alloc[float32](alloc)
+		signal.Write([]float32{-1.5, -1, 0, 0.5, 1.5}, input)
+		output := signal.Alloc[uint8](alloc)
+		signal.FloatAsUnsignedDither(input, output, noDither())
+		assertEqual(t, "samples", samples(output), []uint8{0, 0, 128, 192, 255})
+	})
+	t.Run("float signed 64", func(t *testing.T) {
+		input := signal.Alloc[float64](alloc)
+		signal.Write([]float64{-1, 0, 1, 1, 1}, input)
+		output := signal.Alloc[int64](alloc)
+		signal.FloatAsSignedDither(input, output, noDither())
+		if output.Sample(0) != math.MinInt64 || output.Sample(2) <= 0 {
+			t.Fatalf("invalid samples: %v", samples(output))
+		}
+	})
+	t.Run("signed signed", func(t *testing.T) {
+		input := signal.Alloc[int16](alloc)
+		signal.Write([]int16{-32768, -129, 127, 128, 32767}, input)
+		output := signal.Alloc[int8](alloc)
+		signal.SignedAsSignedDither(input, output, noDither())
+		assertEqual(t, "samples", samples(output), []int8{-128, -1, 0, 1, 127})
+	})
+	t.Run("signed unsigned", func(t *testing.T) {
+		input := signal.Alloc[int16](alloc)
+		signal.Write([]int16{-32768, -129, 127, 128, 32767}, input)
+		output := signal.Alloc[uint8](alloc)
+		signal.SignedAsUnsignedDither(input, output, noDither())
+		assertEqual(t, "samples", samples(output), []uint8{0, 127, 128, 129, 255})
+	})
+	t.Run("unsigned signed", func(t *testing.T) {
+		input := signal.Alloc[uint16](alloc)
+		signal.Write([]uint16{0, 32639, 32895, 32896, 65535}, input)
+		output := signal.Alloc[int8](alloc)
+		signal.UnsignedAsSignedDither(input, output, noDither())
+		assertEqual(t, "samples", samples(output), []int8{-128, -1, 0, 1, 127})
+	})
+	t.Run("unsigned unsigned", func(t *testing.T) {
+		input := signal.Alloc[uint16](alloc)
+		signal.Write([]uint16{0, 32639, 32895, 32896, 65535}, input)
+		output := signal.Alloc[uint8](alloc)
+		signal.UnsignedAsUnsignedDither(input, output, noDither())
+		assertEqual(t, "samples", samples(output), []uint8{0, 127, 128, 129, 255})
+	})
+	t.Run("upscale", func(t *testing.T) {
+		input := signal.Alloc[int8](alloc)
+		signal.Write([]int8{-128, -1, 0, 1, 127}, input)
+		expected := signal.Alloc[int16](alloc)
+		signal.SignedAsSigned(input, expected)
+		output := signal.Alloc[int16](alloc)
+		signal.SignedAsSignedDither(input, output, signal.NewDither(signal.Triangular, nil, 0))
+		assertEqual(t, "samples", output, expected)
+	})
+	t.Run("channels", func(t *testing.T) {
+		assertPanic(t, func() {
+			signal.FloatAsSignedDither(
+				signal.Alloc[float64](signal.Allocator{Channels: 1, Length: 1, Capacity: 1}),
+				signal.Alloc[int8](signal.Allocator{Channels: 2, Length: 1, Capacity: 1}),
+				noDither(),
+			)
+		})
+	})
+}
+
+func samples[T signal.SignalTypes](b *signal.Buffer[T]) []T {
+	s := make([]T, b.Len())
+	signal.Read(b, s)
+	return s
+}
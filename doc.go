@@ -38,13 +38,14 @@ signed/unsigned fixed-point signal buffers. They implement Floating, Signed
 and Unsigned interfaces respectively. Internally, signal buffers use a
 slice of built-in type to hold the data.
 
-Fixed-point buffers require a bit depth to be provided at allocation time.
-It allows to use the same type to hold values of various bit depths:
+By default, bit depth of the Buffer is determined by its type. Fixed-point
+buffers can be allocated with explicit bit depth. It allows to use the same
+type to hold values of various bit depths:
 
 	alloc := signal.Allocator{Channels: 2, Capacity: 512}
-	_ = alloc.Int64(signal.BitDepth32)      // int-64 Buffer with 32-bits per sample
-	_ = alloc.Uint32(signal.BitDepth24)     // uint-32 Buffer with 24-bits per sample
-	_ = alloc.Float64()                     // float-64 Buffer
+	_ = signal.AllocBitDepth[int64](alloc, signal.BitDepth32)  // int-64 Buffer with 32-bits per sample
+	_ = signal.AllocBitDepth[uint32](alloc, signal.BitDepth24) // uint-32 Buffer with 24-bits per sample
+	_ = signal.Alloc[float64](alloc)                           // float-64 Buffer
 
 Signal buffers have semantics of golang-slices - they can be sliced or
 apended one to another. All operations respect number of channels within
@@ -72,6 +73,47 @@ another Buffer needs to be explicitly allocated.
 The one can also iterate over signal buffers. Please, refer to examples for
 more details.
 
+# Channels
+
+Channel returns a view of a single channel of the Buffer. It doesn't copy
+samples and implements Samples interface, so it can be passed to Read,
+Write and conversion functions as a mono Buffer:
+
+	left := make([]float64, buf.Length())
+	signal.Read(buf.Channel(0), left)
+
+# Planar buffers
+
+Buffer holds samples of all channels interleaved. Planar holds samples of
+each channel in a separate contiguous slice, which is the layout most DSP
+kernels and many audio APIs expect. It's allocated, pooled, sliced and
+appended the same way as Buffer. Interleave and Deinterleave copy samples
+between the layouts without allocations:
+
+	p := signal.AllocPlanar[float32](alloc)
+	signal.Deinterleave(buf, p)
+	process(p.Channel(0))
+
+# Dithering
+
+Conversions to fixed-point buffers of lower resolution truncate samples.
+Dither variants of conversion functions add noise before quantization and
+optionally apply noise shaping. Dither keeps the state per channel and
+uses seeded random numbers, so results are reproducible:
+
+	d := signal.NewDither(signal.Triangular, signal.SecondOrderShaping, 1)
+	signal.FloatAsSignedDither(floats, ints, d)
+
+# Errors
+
+Functions panic when buffers don't match, ie: have different number of
+channels. Try* variants of these functions return Error instead, which
+can be matched with errors.Is:
+
+	if err := dst.TryAppend(src); errors.Is(err, signal.ErrChannels) {
+		return err
+	}
+
 # Pooling
 
 This package also provides a pool-backed allocator. It contains a sync.Pool
@@ -80,5 +122,13 @@ runtime when many buffers are allocated. The pool operates with the
 interface types (Signed, Unsigned, Floating) for convenience and alignment
 with other functions of this package. It is safe for concurrent use by
 multiple goroutines.
+
+PoolAllocator serves buffers of a single Allocator. Pool serves buffers of
+any number of channels and capacity, capacity is rounded up to the power
+of two to limit a number of classes:
+
+	pool := signal.NewPool[float32]()
+	buf := pool.Get(signal.Allocator{Channels: 2, Length: 480, Capacity: 480})
+	defer pool.Put(buf)
 */
 package signal
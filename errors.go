@@ -0,0 +1,296 @@
+package signal
+
+import "golang.org/x/exp/constraints"
+
+// Error is a sentinel error returned by Try* functions. These functions
+// behave exactly as their counterparts, but return an error instead of
+// panic. Errors can be matched with errors.Is.
+type Error string
+
+// Errors returned by Try* functions.
+const (
+	// ErrChannels is returned when number of channels doesn't match.
+	ErrChannels = Error(diffChannels)
+	// ErrCapacity is returned when capacity of the buffer doesn't match.
+	ErrCapacity = Error(diffCapacity)
+	// ErrBitDepth is returned when bit depth of the buffers doesn't
+	// match.
+	ErrBitDepth = Error(diffBitDepth)
+	// ErrBitDepthRange is returned when bit depth cannot be held by the
+	// type of samples.
+	ErrBitDepthRange = Error(bitDepthOutOfRange)
+	// ErrSampleRate is returned when sample rate of the buffers doesn't
+	// match.
+	ErrSampleRate = Error(diffSampleRate)
+)
+
+func (e Error) Error() string {
+	return "signal: " + string(e)
+}
+
+// TryAppend appends samples the same way as Append, but returns an error
+// if buffers have different number of channels, bit depth or sample rate.
+func (dst *Buffer[D]) TryAppend(src *Buffer[D]) error {
+	if err := checkAppend(dst, src); err != nil {
+		return err
+	}
+	dst.Append(src)
+	return nil
+}
+
+// TryAppend appends samples the same way as Append, but returns an error
+// if buffers have different number of channels, bit depth or sample rate.
+func (dst *Planar[T]) TryAppend(src *Planar[T]) error {
+	if err := checkAppend(dst, src); err != nil {
+		return err
+	}
+	dst.Append(src)
+	return nil
+}
+
+// TryPut returns the Buffer to the pool the same way as Put, but returns
+// an error if Buffer has different capacity or sample rate.
+func (p *PoolAllocator[T]) TryPut(b *Buffer[T]) error {
+	if err := p.checkPut(b.Cap(), b.SampleRate()); err != nil {
+		return err
+	}
+	p.Put(b)
+	return nil
+}
+
+// TryPutPlanar returns the Planar buffer to the pool the same way as
+// PutPlanar, but returns an error if buffer has different capacity or
+// sample rate.
+func (p *PoolAllocator[T]) TryPutPlanar(b *Planar[T]) error {
+	if err := p.checkPut(b.Cap(), b.SampleRate()); err != nil {
+		return err
+	}
+	p.PutPlanar(b)
+	return nil
+}
+
+// TryPut returns the Buffer to the pool the same way as Put, but returns
+// an error if Buffer has different bit depth or capacity that doesn't
+// match any class.
+func (p *Pool[T]) TryPut(b *Buffer[T]) error {
+	if err := p.checkPut(b.BitDepth(), b.Capacity()); err != nil {
+		return err
+	}
+	p.Put(b)
+	return nil
+}
+
+// TryPutPlanar returns the Planar buffer to the pool the same way as
+// PutPlanar, but returns an error if buffer has different bit depth or
+// capacity that doesn't match any class.
+func (p *Pool[T]) TryPutPlanar(b *Planar[T]) error {
+	if err := p.checkPut(b.BitDepth(), b.Capacity()); err != nil {
+		return err
+	}
+	p.PutPlanar(b)
+	return nil
+}
+
+// TryReadStriped reads values the same way as ReadStriped, but returns an
+// error if the length of provided slice doesn't match the number of
+// channels.
+func TryReadStriped[S, D SignalTypes](src Samples[S], dst [][]D) (int, error) {
+	if src.Channels() != len(dst) {
+		return 0, ErrChannels
+	}
+	return ReadStriped(src, dst), nil
+}
+
+// TryWriteStriped writes values the same way as WriteStriped, but returns
+// an error if the length of provided slice doesn't match the number of
+// channels.
+func TryWriteStriped[S, D SignalTypes](src [][]S, dst Samples[D]) (int, error) {
+	if dst.Channels() != len(src) {
+		return 0, ErrChannels
+	}
+	return WriteStriped(src, dst), nil
+}
+
+// TryConvert converts samples the same way as Convert, but returns an
+// error if source and destination have different number of channels or
+// bit depth that cannot be held by their types.
+func TryConvert[S, D SignalTypes](src Samples[S], dst Samples[D]) (int, error) {
+	return NewConverter[S, D]().TryConvert(src, dst)
+}
+
+// TryConvert converts samples the same way as Convert, but returns an
+// error if source and destination have different number of channels or
+// bit depth that cannot be held by their types.
+func (c Converter[S, D]) TryConvert(src Samples[S], dst Samples[D]) (int, error) {
+	if err := checkConvert(src, dst); err != nil {
+		return 0, err
+	}
+	return c.Convert(src, dst), nil
+}
+
+// TryFloatAsFloat is FloatAsFloat that returns an error instead of panic.
+func TryFloatAsFloat[S, D constraints.Float](src Samples[S], dst Samples[D]) (int, error) {
+	return tryConvert(src, dst, FloatAsFloat[S, D])
+}
+
+// TryFloatAsSigned is FloatAsSigned that returns an error instead of
+// panic.
+func TryFloatAsSigned[S constraints.Float, D constraints.Signed](src Samples[S], dst Samples[D]) (int, error) {
+	return tryConvert(src, dst, FloatAsSigned[S, D])
+}
+
+// TryFloatAsUnsigned is FloatAsUnsigned that returns an error instead of
+// panic.
+func TryFloatAsUnsigned[S constraints.Float, D constraints.Unsigned](src Samples[S], dst Samples[D]) (int, error) {
+	return tryConvert(src, dst, FloatAsUnsigned[S, D])
+}
+
+// TrySignedAsFloat is SignedAsFloat that returns an error instead of
+// panic.
+func TrySignedAsFloat[S constraints.Signed, D constraints.Float](src Samples[S], dst Samples[D]) (int, error) {
+	return tryConvert(src, dst, SignedAsFloat[S, D])
+}
+
+// TrySignedAsSigned is SignedAsSigned that returns an error instead of
+// panic.
+func TrySignedAsSigned[S, D constraints.Signed](src Samples[S], dst Samples[D]) (int, error) {
+	return tryConvert(src, dst, SignedAsSigned[S, D])
+}
+
+// TrySignedAsUnsigned is SignedAsUnsigned that returns an error instead
+// of panic.
+func TrySignedAsUnsigned[S constraints.Signed, D constraints.Unsigned](src Samples[S], dst Samples[D]) (int, error) {
+	return tryConvert(src, dst, SignedAsUnsigned[S, D])
+}
+
+// TryUnsignedAsFloat is UnsignedAsFloat that returns an error instead of
+// panic.
+func TryUnsignedAsFloat[S constraints.Unsigned, D constraints.Float](src Samples[S], dst Samples[D]) (int, error) {
+	return tryConvert(src, dst, UnsignedAsFloat[S, D])
+}
+
+// TryUnsignedAsSigned is UnsignedAsSigned that returns an error instead
+// of panic.
+func TryUnsignedAsSigned[S constraints.Unsigned, D constraints.Signed](src Samples[S], dst Samples[D]) (int, error) {
+	return tryConvert(src, dst, UnsignedAsSigned[S, D])
+}
+
+// TryUnsignedAsUnsigned is UnsignedAsUnsigned that returns an error
+// instead of panic.
+func TryUnsignedAsUnsigned[S, D constraints.Unsigned](src Samples[S], dst Samples[D]) (int, error) {
+	return tryConvert(src, dst, UnsignedAsUnsigned[S, D])
+}
+
+// TryFloatAsSignedDither is FloatAsSignedDither that returns an error
+// instead of panic.
+func TryFloatAsSignedDither[S constraints.Float, D constraints.Signed](src Samples[S], dst Samples[D], d *Dither) (int, error) {
+	return tryConvert(src, dst, func(src Samples[S], dst Samples[D]) int {
+		return FloatAsSignedDither(src, dst, d)
+	})
+}
+
+// TryFloatAsUnsignedDither is FloatAsUnsignedDither that returns an error
+// instead of panic.
+func TryFloatAsUnsignedDither[S constraints.Float, D constraints.Unsigned](src Samples[S], dst Samples[D], d *Dither) (int, error) {
+	return tryConvert(src, dst, func(src Samples[S], dst Samples[D]) int {
+		return FloatAsUnsignedDither(src, dst, d)
+	})
+}
+
+// TrySignedAsSignedDither is SignedAsSignedDither that returns an error
+// instead of panic.
+func TrySignedAsSignedDither[S, D constraints.Signed](src Samples[S], dst Samples[D], d *Dither) (int, error) {
+	return tryConvert(src, dst, func(src Samples[S], dst Samples[D]) int {
+		return SignedAsSignedDither(src, dst, d)
+	})
+}
+
+// TrySignedAsUnsignedDither is SignedAsUnsignedDither that returns an
+// error instead of panic.
+func TrySignedAsUnsignedDither[S constraints.Signed, D constraints.Unsigned](src Samples[S], dst Samples[D], d *Dither) (int, error) {
+	return tryConvert(src, dst, func(src Samples[S], dst Samples[D]) int {
+		return SignedAsUnsignedDither(src, dst, d)
+	})
+}
+
+// TryUnsignedAsSignedDither is UnsignedAsSignedDither that returns an
+// error instead of panic.
+func TryUnsignedAsSignedDither[S constraints.Unsigned, D constraints.Signed](src Samples[S], dst Samples[D], d *Dither) (int, error) {
+	return tryConvert(src, dst, func(src Samples[S], dst Samples[D]) int {
+		return UnsignedAsSignedDither(src, dst, d)
+	})
+}
+
+// TryUnsignedAsUnsignedDither is UnsignedAsUnsignedDither that returns an
+// error instead of panic.
+func TryUnsignedAsUnsignedDither[S, D constraints.Unsigned](src Samples[S], dst Samples[D], d *Dither) (int, error) {
+	return tryConvert(src, dst, func(src Samples[S], dst Samples[D]) int {
+		return UnsignedAsUnsignedDither(src, dst, d)
+	})
+}
+
+// tryConvert checks source and destination and calls conversion
+// function.
+func tryConvert[S, D SignalTypes](src Samples[S], dst Samples[D], fn func(Samples[S], Samples[D]) int) (int, error) {
+	if err := checkConvert(src, dst); err != nil {
+		return 0, err
+	}
+	return fn(src, dst), nil
+}
+
+// checkConvert returns an error if source cannot be converted into the
+// destination.
+func checkConvert[S, D SignalTypes](src Samples[S], dst Samples[D]) error {
+	if src.Channels() != dst.Channels() {
+		return ErrChannels
+	}
+	if !validBitDepth[S](src.BitDepth()) || !validBitDepth[D](dst.BitDepth()) {
+		return ErrBitDepthRange
+	}
+	return nil
+}
+
+// appender is a buffer that can be appended.
+type appender interface {
+	Channels() int
+	BitDepth() BitDepth
+	SampleRate() Frequency
+}
+
+// checkAppend returns an error if source cannot be appended to the
+// destination.
+func checkAppend(dst, src appender) error {
+	switch {
+	case dst.Channels() != src.Channels():
+		return ErrChannels
+	case dst.BitDepth() != src.BitDepth():
+		return ErrBitDepth
+	case dst.SampleRate() != src.SampleRate():
+		return ErrSampleRate
+	}
+	return nil
+}
+
+// checkPut returns an error if buffer of provided capacity and sample
+// rate cannot be put into the pool.
+func (p *PoolAllocator[T]) checkPut(capacity int, sr Frequency) error {
+	switch {
+	case p.alloc.Capacity*p.alloc.Channels != capacity:
+		return ErrCapacity
+	case p.alloc.SampleRate != sr:
+		return ErrSampleRate
+	}
+	return nil
+}
+
+// checkPut returns an error if buffer of provided bit depth and capacity
+// cannot be put into the pool.
+func (p *Pool[T]) checkPut(bd BitDepth, capacity int) error {
+	switch {
+	case p.bitDepth != bd:
+		return ErrBitDepth
+	case capacityClass(capacity) != capacity:
+		return ErrCapacity
+	}
+	return nil
+}
@@ -0,0 +1,97 @@
+package signal_test
+
+import (
+	"errors"
+	"testing"
+
+	"pipelined.dev/signal"
+)
+
+func TestTry(t *testing.T) {
+	stereo := signal.Allocator{Channels: 2, Length: 4, Capacity: 4, SampleRate: 44100}
+	mono := signal.Allocator{Channels: 1, Length: 4, Capacity: 4, SampleRate: 44100}
+	testErr := func(fn func() error, expected error) func(*testing.T) {
+		return func(t *testing.T) {
+			t.Helper()
+			err := fn()
+			if !errors.Is(err, expected) {
+				t.Fatalf("error: %v expected: %v", err, expected)
+			}
+		}
+	}
+
+	t.Run("append", testErr(func() error {
+		return signal.Alloc[int16](stereo).TryAppend(signal.Alloc[int16](mono))
+	}, signal.ErrChannels))
+	t.Run("append bit depth", testErr(func() error {
+		return signal.Alloc[int32](stereo).TryAppend(signal.AllocBitDepth[int32](stereo, signal.BitDepth24))
+	}, signal.ErrBitDepth))
+	t.Run("append sample rate", testErr(func() error {
+		return signal.Alloc[int16](stereo).TryAppend(signal.Alloc[int16](signal.Allocator{Channels: 2}))
+	}, signal.ErrSampleRate))
+	t.Run("append planar", testErr(func() error {
+		return signal.AllocPlanar[int16](stereo).TryAppend(signal.AllocPlanar[int16](mono))
+	}, signal.ErrChannels))
+	t.Run("append ok", func(t *testing.T) {
+		b := signal.Alloc[int16](stereo)
+		assertNoError(t, b.TryAppend(signal.Alloc[int16](stereo)))
+		assertEqual(t, "length", b.Length(), 8)
+	})
+
+	t.Run("read striped", testErr(func() error {
+		_, err := signal.TryReadStriped(signal.Alloc[int16](stereo), make([][]int16, 1))
+		return err
+	}, signal.ErrChannels))
+	t.Run("write striped", testErr(func() error {
+		_, err := signal.TryWriteStriped(make([][]int16, 3), signal.Alloc[int16](stereo))
+		return err
+	}, signal.ErrChannels))
+	t.Run("striped ok", func(t *testing.T) {
+		b := signal.Alloc[int16](stereo)
+		n, err := signal.TryWriteStriped([][]int16{{1, 2}, {3}}, b)
+		assertNoError(t, err)
+		assertEqual(t, "written", n, 2)
+		n, err = signal.TryReadStriped(b, [][]int16{make([]int16, 4), nil})
+		assertNoError(t, err)
+		assertEqual(t, "read", n, 4)
+	})
+
+	t.Run("convert", testErr(func() error {
+		_, err := signal.TryFloatAsSigned(signal.Alloc[float64](stereo), signal.Alloc[int16](mono))
+		return err
+	}, signal.ErrChannels))
+	t.Run("convert bit depth", testErr(func() error {
+		// zero value Buffer has no bit depth.
+		_, err := signal.TryConvert[int16, float64](&signal.Buffer[int16]{}, signal.Alloc[float64](signal.Allocator{}))
+		return err
+	}, signal.ErrBitDepthRange))
+	t.Run("convert dither", testErr(func() error {
+		d := signal.NewDither(signal.Triangular, nil, 1)
+		_, err := signal.TryFloatAsSignedDither(signal.Alloc[float64](stereo), signal.Alloc[int16](mono), d)
+		return err
+	}, signal.ErrChannels))
+	t.Run("convert ok", func(t *testing.T) {
+		n, err := signal.TryConvert(signal.Alloc[int32](stereo), signal.AllocPlanar[float32](stereo))
+		assertNoError(t, err)
+		assertEqual(t, "converted", n, 4)
+		n, err = signal.TryUnsignedAsUnsigned(signal.Alloc[uint16](stereo), signal.Alloc[uint8](stereo))
+		assertNoError(t, err)
+		assertEqual(t, "converted", n, 4)
+	})
+
+	t.Run("pool capacity", testErr(func() error {
+		p := signal.PoolAlloc[int16](stereo)
+		return p.TryPut(signal.Alloc[int16](signal.Allocator{Channels: 2, Capacity: 8, SampleRate: 44100}))
+	}, signal.ErrCapacity))
+	t.Run("pool sample rate", testErr(func() error {
+		p := signal.PoolAlloc[int16](stereo)
+		return p.TryPutPlanar(signal.AllocPlanar[int16](signal.Allocator{Channels: 2, Capacity: 4}))
+	}, signal.ErrSampleRate))
+	t.Run("pool ok", func(t *testing.T) {
+		p := signal.PoolAlloc[int16](stereo)
+		assertNoError(t, p.TryPut(p.Get()))
+		assertNoError(t, p.TryPutPlanar(p.GetPlanar()))
+	})
+
+	assertEqual(t, "message", signal.ErrChannels.Error(), "signal: different number of channels")
+}
@@ -0,0 +1,56 @@
+package fft
+
+import (
+	"sync"
+
+	"golang.org/x/exp/constraints"
+	"pipelined.dev/signal"
+)
+
+// samples is a pool of real sequences used to read and write buffers.
+var samples = sync.Pool{
+	New: func() any {
+		return new([]float64)
+	},
+}
+
+// Forward computes the spectrum of the channel of src Buffer and writes
+// it into dst. First Len samples of the channel are transformed, the
+// channel is padded with zeros if it's shorter. Length of dst must be
+// SpectrumLen, otherwise function will panic.
+func Forward[T constraints.Float](p *RealPlan, src *signal.Buffer[T], channel int, dst []complex128) {
+	s := getSamples(p.n)
+	defer samples.Put(s)
+	length := min(p.n, src.Length())
+	for i := 0; i < length; i++ {
+		(*s)[i] = float64(src.Sample(src.BufferIndex(channel, i)))
+	}
+	for i := length; i < p.n; i++ {
+		(*s)[i] = 0
+	}
+	p.Forward(dst, *s)
+}
+
+// Inverse computes the inverse transform of src spectrum and writes the
+// result into the channel of dst Buffer. At most Len samples are written
+// to the [0:Length] of the channel. Length of src must be SpectrumLen,
+// otherwise function will panic. Returns a number of samples written.
+func Inverse[T constraints.Float](p *RealPlan, src []complex128, dst *signal.Buffer[T], channel int) int {
+	s := getSamples(p.n)
+	defer samples.Put(s)
+	p.Inverse(*s, src)
+	length := min(p.n, dst.Length())
+	for i := 0; i < length; i++ {
+		dst.SetSample(dst.BufferIndex(channel, i), T((*s)[i]))
+	}
+	return length
+}
+
+func getSamples(n int) *[]float64 {
+	s := samples.Get().(*[]float64)
+	if cap(*s) < n {
+		*s = make([]float64, n)
+	}
+	*s = (*s)[:n]
+	return s
+}
@@ -0,0 +1,214 @@
+// Package fft provides fast Fourier transform of complex and real
+// sequences and signal buffers.
+//
+// Transforms are computed with precomputed plans. Plan is created once
+// for the size of transform and can be shared by multiple goroutines:
+//
+//	p, err := fft.NewRealPlan(1024)
+//	if err != nil {
+//		return err
+//	}
+//	spectrum := make([]complex128, p.SpectrumLen())
+//	for c := 0; c < buf.Channels(); c++ {
+//		fft.Forward(p, buf, c, spectrum)
+//		// process spectrum
+//		fft.Inverse(p, spectrum, buf, c)
+//	}
+//
+// Sizes that factor into small primes are the most efficient. Forward
+// transforms are not normalized and inverse transforms are scaled by
+// 1/n, so the round trip restores the input.
+package fft
+
+import (
+	"errors"
+	"math"
+	"math/cmplx"
+	"sync"
+)
+
+// ErrSize is returned when the size of transform is not positive.
+var ErrSize = errors.New("fft: invalid size")
+
+// panic messages.
+const (
+	diffLength = "fft: different length"
+)
+
+// Plan is a precomputed complex transform of fixed size. It is safe for
+// concurrent use by multiple goroutines.
+type Plan struct {
+	n       int
+	factors []int
+	// forward and inverse twiddle factors.
+	forward []complex128
+	inverse []complex128
+	// pool of []complex128 buffers for the generic radix butterflies and
+	// in-place transforms.
+	scratch sync.Pool
+}
+
+// NewPlan returns a complex transform plan of size n.
+func NewPlan(n int) (*Plan, error) {
+	if n <= 0 {
+		return nil, ErrSize
+	}
+	p := Plan{
+		n:       n,
+		factors: factorize(n),
+		forward: make([]complex128, n),
+		inverse: make([]complex128, n),
+	}
+	for i := range p.forward {
+		p.forward[i] = cmplx.Rect(1, -2*math.Pi*float64(i)/float64(n))
+		p.inverse[i] = cmplx.Conj(p.forward[i])
+	}
+	p.scratch.New = func() any {
+		s := make([]complex128, n)
+		return &s
+	}
+	return &p, nil
+}
+
+// Len returns the size of transform.
+func (p *Plan) Len() int {
+	return p.n
+}
+
+// Forward computes the discrete Fourier transform of src and writes it
+// into dst. Both slices must have the plan length, otherwise function
+// will panic. Slices may be the same.
+func (p *Plan) Forward(dst, src []complex128) {
+	p.transform(dst, src, p.forward)
+}
+
+// Inverse computes the inverse discrete Fourier transform of src scaled
+// by 1/n and writes it into dst. Both slices must have the plan length,
+// otherwise function will panic. Slices may be the same.
+func (p *Plan) Inverse(dst, src []complex128) {
+	p.transform(dst, src, p.inverse)
+	scale := complex(1/float64(p.n), 0)
+	for i := range dst {
+		dst[i] *= scale
+	}
+}
+
+func (p *Plan) transform(dst, src, twiddles []complex128) {
+	mustSame(p.n, len(src), diffLength)
+	mustSame(p.n, len(dst), diffLength)
+	scratch := p.scratch.Get().(*[]complex128)
+	defer p.scratch.Put(scratch)
+	// transform is out of place, the input is copied if slices overlap.
+	if &dst[0] == &src[0] {
+		copy(*scratch, src)
+		src = *scratch
+		tmp := p.scratch.Get().(*[]complex128)
+		defer p.scratch.Put(tmp)
+		scratch = tmp
+	}
+	p.work(dst, src, 1, p.factors, twiddles, *scratch)
+}
+
+// work performs recursive mixed-radix decimation in time. The input is
+// read from src with provided stride.
+func (p *Plan) work(dst, src []complex128, stride int, factors []int, twiddles, scratch []complex128) {
+	radix := factors[0]
+	m := len(dst) / radix
+	if m == 1 {
+		for i := range dst {
+			dst[i] = src[i*stride]
+		}
+	} else {
+		for i := 0; i < radix; i++ {
+			p.work(dst[i*m:(i+1)*m], src[i*stride:], stride*radix, factors[1:], twiddles, scratch)
+		}
+	}
+
+	switch radix {
+	case 2:
+		butterfly2(dst, m, stride, twiddles)
+	case 4:
+		butterfly4(dst, m, stride, twiddles, twiddles[p.n/4])
+	default:
+		butterfly(dst, m, radix, stride, twiddles, scratch[:radix])
+	}
+}
+
+func butterfly2(dst []complex128, m, stride int, twiddles []complex128) {
+	for u := 0; u < m; u++ {
+		t := dst[u+m] * twiddles[u*stride]
+		dst[u+m] = dst[u] - t
+		dst[u] += t
+	}
+}
+
+// butterfly4 uses rotation by -i for forward and i for inverse transform.
+func butterfly4(dst []complex128, m, stride int, twiddles []complex128, rotation complex128) {
+	for u := 0; u < m; u++ {
+		a0 := dst[u]
+		a1 := dst[u+m] * twiddles[u*stride]
+		a2 := dst[u+2*m] * twiddles[2*u*stride]
+		a3 := dst[u+3*m] * twiddles[3*u*stride]
+		s0, s1 := a0+a2, a0-a2
+		s2, s3 := a1+a3, (a1-a3)*rotation
+		dst[u] = s0 + s2
+		dst[u+m] = s1 + s3
+		dst[u+2*m] = s0 - s2
+		dst[u+3*m] = s1 - s3
+	}
+}
+
+// butterfly is a generic radix butterfly with quadratic complexity.
+func butterfly(dst []complex128, m, radix, stride int, twiddles, scratch []complex128) {
+	n := len(twiddles)
+	for u := 0; u < m; u++ {
+		for q := 0; q < radix; q++ {
+			scratch[q] = dst[u+q*m]
+		}
+		for q1 := 0; q1 < radix; q1++ {
+			k := u + q1*m
+			v := scratch[0]
+			idx := 0
+			for q := 1; q < radix; q++ {
+				idx += stride * k
+				idx %= n
+				v += scratch[q] * twiddles[idx]
+			}
+			dst[k] = v
+		}
+	}
+}
+
+// factorize returns radices of the transform. Radix 4 is preferred, then
+// 2 and odd factors.
+func factorize(n int) []int {
+	var factors []int
+	for n%4 == 0 {
+		factors = append(factors, 4)
+		n /= 4
+	}
+	for n%2 == 0 && n > 1 {
+		factors = append(factors, 2)
+		n /= 2
+	}
+	for f := 3; n > 1; f += 2 {
+		for n%f == 0 {
+			factors = append(factors, f)
+			n /= f
+		}
+		if f*f > n && n > 1 {
+			factors = append(factors, n)
+			break
+		}
+	}
+	if len(factors) == 0 {
+		factors = append(factors, 1)
+	}
+	return factors
+}
+
+func mustSame[T comparable](a, b T, panicStr string) {
+	if a != b {
+		panic(panicStr)
+	}
+}
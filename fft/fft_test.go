@@ -0,0 +1,213 @@
+package fft_test
+
+import (
+	"math"
+	"math/cmplx"
+	"math/rand"
+	"reflect"
+	"sync"
+	"testing"
+
+	"pipelined.dev/signal"
+	"pipelined.dev/signal/fft"
+)
+
+const tolerance = 1e-9
+
+func TestPlan(t *testing.T) {
+	testOk := func(n int) func(*testing.T) {
+		return func(t *testing.T) {
+			t.Helper()
+			p, err := fft.NewPlan(n)
+			assertNoError(t, err)
+			assertEqual(t, "length", p.Len(), n)
+			src := randomComplex(n)
+			dst := make([]complex128, n)
+			p.Forward(dst, src)
+			assertClose(t, "forward", dst, dft(src, -1))
+
+			inverse := make([]complex128, n)
+			p.Inverse(inverse, dst)
+			assertClose(t, "inverse", inverse, src)
+
+			// in-place transform.
+			p.Forward(inverse, inverse)
+			assertClose(t, "in-place", inverse, dst)
+		}
+	}
+	for _, n := range []int{1, 2, 3, 4, 5, 6, 7, 8, 9, 12, 16, 25, 30, 49, 64, 97, 100, 128, 210, 1024} {
+		t.Run("", testOk(n))
+	}
+
+	_, err := fft.NewPlan(0)
+	assertEqual(t, "error", err, fft.ErrSize)
+	p, _ := fft.NewPlan(4)
+	assertPanic(t, func() {
+		p.Forward(make([]complex128, 4), make([]complex128, 3))
+	})
+}
+
+func TestRealPlan(t *testing.T) {
+	testOk := func(n int) func(*testing.T) {
+		return func(t *testing.T) {
+			t.Helper()
+			p, err := fft.NewRealPlan(n)
+			assertNoError(t, err)
+			assertEqual(t, "length", p.Len(), n)
+			assertEqual(t, "spectrum length", p.SpectrumLen(), n/2+1)
+			src := make([]float64, n)
+			input := make([]complex128, n)
+			for i := range src {
+				src[i] = rand.Float64()*2 - 1
+				input[i] = complex(src[i], 0)
+			}
+			dst := make([]complex128, p.SpectrumLen())
+			p.Forward(dst, src)
+			assertClose(t, "forward", dst, dft(input, -1)[:p.SpectrumLen()])
+
+			inverse := make([]float64, n)
+			p.Inverse(inverse, dst)
+			for i := range src {
+				if math.Abs(inverse[i]-src[i]) > tolerance {
+					t.Fatalf("inverse: sample %d: %v expected: %v", i, inverse[i], src[i])
+				}
+			}
+		}
+	}
+	for _, n := range []int{1, 2, 3, 4, 5, 6, 8, 10, 15, 16, 30, 64, 100, 1000, 1024} {
+		t.Run("", testOk(n))
+	}
+}
+
+func TestBuffer(t *testing.T) {
+	const n = 64
+	p, err := fft.NewRealPlan(n)
+	assertNoError(t, err)
+	buf := signal.Alloc[float32](signal.Allocator{Channels: 2, Length: n, Capacity: n})
+	for i := 0; i < n; i++ {
+		buf.SetSample(buf.BufferIndex(0, i), float32(math.Cos(2*math.Pi*4*float64(i)/n)))
+		buf.SetSample(buf.BufferIndex(1, i), float32(math.Sin(2*math.Pi*8*float64(i)/n)))
+	}
+	spectrum := make([]complex128, p.SpectrumLen())
+
+	fft.Forward(p, buf, 0, spectrum)
+	for k, v := range spectrum {
+		expected := 0.0
+		if k == 4 {
+			expected = n / 2
+		}
+		if math.Abs(cmplx.Abs(v)-expected) > 1e-4 {
+			t.Fatalf("channel 0: bin %d: %v expected: %v", k, cmplx.Abs(v), expected)
+		}
+	}
+
+	fft.Forward(p, buf, 1, spectrum)
+	if math.Abs(cmplx.Abs(spectrum[8])-n/2) > 1e-4 {
+		t.Fatalf("channel 1: bin 8: %v", cmplx.Abs(spectrum[8]))
+	}
+	result := signal.Alloc[float32](signal.Allocator{Channels: 2, Length: n, Capacity: n})
+	assertEqual(t, "written", fft.Inverse(p, spectrum, result, 1), n)
+	for i := 0; i < n; i++ {
+		if d := result.Sample(result.BufferIndex(1, i)) - buf.Sample(buf.BufferIndex(1, i)); math.Abs(float64(d)) > 1e-6 {
+			t.Fatalf("inverse: sample %d differs by %v", i, d)
+		}
+		assertEqual(t, "other channel", result.Sample(result.BufferIndex(0, i)), float32(0))
+	}
+
+	// zero padding of short buffer.
+	short := signal.Alloc[float64](signal.Allocator{Channels: 1, Length: 1, Capacity: 1})
+	short.SetSample(0, 1)
+	fft.Forward(p, short, 0, spectrum)
+	for k, v := range spectrum {
+		if cmplx.Abs(v-1) > tolerance {
+			t.Fatalf("impulse: bin %d: %v", k, v)
+		}
+	}
+}
+
+func TestConcurrent(t *testing.T) {
+	const n = 360
+	p, err := fft.NewPlan(n)
+	assertNoError(t, err)
+	src := randomComplex(n)
+	expected := dft(src, -1)
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			dst := make([]complex128, n)
+			for j := 0; j < 10; j++ {
+				p.Forward(dst, src)
+				assertClose(t, "concurrent", dst, expected)
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func BenchmarkPlan(b *testing.B) {
+	p, _ := fft.NewPlan(4096)
+	src := randomComplex(4096)
+	dst := make([]complex128, 4096)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		p.Forward(dst, src)
+	}
+}
+
+// dft is a direct computation of discrete Fourier transform.
+func dft(src []complex128, sign float64) []complex128 {
+	n := len(src)
+	dst := make([]complex128, n)
+	for k := range dst {
+		for i, v := range src {
+			dst[k] += v * cmplx.Rect(1, sign*2*math.Pi*float64(i*k%n)/float64(n))
+		}
+	}
+	return dst
+}
+
+func randomComplex(n int) []complex128 {
+	r := rand.New(rand.NewSource(int64(n)))
+	s := make([]complex128, n)
+	for i := range s {
+		s[i] = complex(r.Float64()*2-1, r.Float64()*2-1)
+	}
+	return s
+}
+
+func assertClose(t *testing.T, name string, result, expected []complex128) {
+	t.Helper()
+	assertEqual(t, name+" length", len(result), len(expected))
+	for i := range result {
+		if cmplx.Abs(result[i]-expected[i]) > tolerance*float64(len(result)) {
+			t.Errorf("%v: index %d: %v expected: %v", name, i, result[i], expected[i])
+			return
+		}
+	}
+}
+
+func assertEqual(t *testing.T, name string, result, expected interface{}) {
+	t.Helper()
+	if !reflect.DeepEqual(expected, result) {
+		t.Fatalf("%v\nresult: \t%T\t%+v \nexpected: \t%T\t%+v", name, result, result, expected, expected)
+	}
+}
+
+func assertNoError(t *testing.T, err error) {
+	t.Helper()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func assertPanic(t *testing.T, fn func()) {
+	t.Helper()
+	defer func() {
+		if r := recover(); r == nil {
+			t.Fatalf("didn't panic")
+		}
+	}()
+	fn()
+}
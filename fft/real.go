@@ -0,0 +1,132 @@
+package fft
+
+import (
+	"math"
+	"math/cmplx"
+	"sync"
+)
+
+// RealPlan is a precomputed transform of real sequences of fixed size.
+// Only non-negative frequencies are computed, so the spectrum has
+// n/2+1 values. Even sizes are computed with complex transform of half
+// size. It is safe for concurrent use by multiple goroutines.
+type RealPlan struct {
+	n    int
+	plan *Plan
+	// twiddles used to split the half size transform.
+	twiddles []complex128
+	scratch  sync.Pool
+}
+
+// NewRealPlan returns a real transform plan of size n.
+func NewRealPlan(n int) (*RealPlan, error) {
+	if n <= 0 {
+		return nil, ErrSize
+	}
+	size := n
+	if n%2 == 0 {
+		size = n / 2
+	}
+	plan, err := NewPlan(size)
+	if err != nil {
+		return nil, err
+	}
+	p := RealPlan{
+		n:    n,
+		plan: plan,
+	}
+	if n%2 == 0 {
+		p.twiddles = make([]complex128, size)
+		for k := range p.twiddles {
+			p.twiddles[k] = cmplx.Rect(1, -2*math.Pi*float64(k)/float64(n))
+		}
+	}
+	p.scratch.New = func() any {
+		s := make([]complex128, size)
+		return &s
+	}
+	return &p, nil
+}
+
+// Len returns the size of transform.
+func (p *RealPlan) Len() int {
+	return p.n
+}
+
+// SpectrumLen returns the length of spectrum.
+func (p *RealPlan) SpectrumLen() int {
+	return p.n/2 + 1
+}
+
+// Forward computes the discrete Fourier transform of real src and writes
+// non-negative frequencies into dst. Length of src must be the plan length
+// and length of dst must be SpectrumLen, otherwise function will panic.
+func (p *RealPlan) Forward(dst []complex128, src []float64) {
+	mustSame(p.n, len(src), diffLength)
+	mustSame(p.SpectrumLen(), len(dst), diffLength)
+	scratch := p.scratch.Get().(*[]complex128)
+	defer p.scratch.Put(scratch)
+	z := *scratch
+	if p.twiddles == nil {
+		for i, v := range src {
+			z[i] = complex(v, 0)
+		}
+		p.plan.Forward(z, z)
+		copy(dst, z)
+		return
+	}
+
+	// even samples are packed into real and odd into imaginary parts.
+	for k := range z {
+		z[k] = complex(src[2*k], src[2*k+1])
+	}
+	p.plan.Forward(z, z)
+	half := len(z)
+	dst[0] = complex(real(z[0])+imag(z[0]), 0)
+	dst[half] = complex(real(z[0])-imag(z[0]), 0)
+	for k := 1; k < half; k++ {
+		even := (z[k] + cmplx.Conj(z[half-k])) / 2
+		odd := (z[k] - cmplx.Conj(z[half-k])) / complex(0, 2)
+		dst[k] = even + p.twiddles[k]*odd
+	}
+}
+
+// Inverse computes the inverse discrete Fourier transform of the
+// spectrum with non-negative frequencies and writes real result scaled
+// by 1/n into dst. Length of src must be SpectrumLen and length of dst
+// must be the plan length, otherwise function will panic. Imaginary parts
+// of DC and Nyquist frequencies are ignored.
+func (p *RealPlan) Inverse(dst []float64, src []complex128) {
+	mustSame(p.SpectrumLen(), len(src), diffLength)
+	mustSame(p.n, len(dst), diffLength)
+	scratch := p.scratch.Get().(*[]complex128)
+	defer p.scratch.Put(scratch)
+	z := *scratch
+	if p.twiddles == nil {
+		// restore negative frequencies with hermitian symmetry.
+		copy(z, src)
+		for k := len(src); k < p.n; k++ {
+			z[k] = cmplx.Conj(src[p.n-k])
+		}
+		z[0] = complex(real(z[0]), 0)
+		p.plan.Inverse(z, z)
+		for i := range dst {
+			dst[i] = real(z[i])
+		}
+		return
+	}
+
+	half := len(z)
+	for k := 0; k < half; k++ {
+		even := (src[k] + cmplx.Conj(src[half-k])) / 2
+		odd := (src[k] - cmplx.Conj(src[half-k])) / 2 * cmplx.Conj(p.twiddles[k])
+		z[k] = even + complex(0, 1)*odd
+	}
+	// DC and Nyquist frequencies are real.
+	z[0] = complex((real(src[0])+real(src[half]))/2, (real(src[0])-real(src[half]))/2)
+	p.plan.Inverse(z, z)
+	for k := range z {
+		dst[2*k] = real(z[k])
+		dst[2*k+1] = imag(z[k])
+	}
+}
@@ -0,0 +1,115 @@
+package filter
+
+import (
+	"golang.org/x/exp/constraints"
+	"pipelined.dev/signal"
+)
+
+// panic messages.
+const (
+	diffChannels = "filter: different number of channels"
+)
+
+// Biquad is a second-order IIR filter that processes every channel of the
+// Buffer with transposed direct form II structure. State of channels is
+// kept between calls. When coefficients are changed, they are linearly
+// interpolated over the smoothing period to avoid zipper noise. Biquad is
+// not safe for concurrent use.
+type Biquad[T constraints.Float] struct {
+	current   Coefficients
+	target    Coefficients
+	step      Coefficients
+	smoothing int
+	remaining int
+	state     [][2]float64
+}
+
+// NewBiquad returns Biquad for provided number of channels with initial
+// coefficients. Smoothing is a number of samples to interpolate
+// coefficients over when they are changed, zero value means instant
+// change.
+func NewBiquad[T constraints.Float](channels int, c Coefficients, smoothing int) *Biquad[T] {
+	return &Biquad[T]{
+		current:   c,
+		target:    c,
+		smoothing: smoothing,
+		state:     make([][2]float64, channels),
+	}
+}
+
+// Coefficients returns current coefficients of the filter. They differ
+// from the last set coefficients until smoothing is completed.
+func (b *Biquad[T]) Coefficients() Coefficients {
+	return b.current
+}
+
+// Set changes coefficients of the filter. The change is smoothed over
+// the smoothing period starting from current coefficients.
+func (b *Biquad[T]) Set(c Coefficients) {
+	b.target = c
+	if b.smoothing <= 0 {
+		b.current = c
+		b.remaining = 0
+		return
+	}
+	n := float64(b.smoothing)
+	b.step = Coefficients{
+		B0: (c.B0 - b.current.B0) / n,
+		B1: (c.B1 - b.current.B1) / n,
+		B2: (c.B2 - b.current.B2) / n,
+		A1: (c.A1 - b.current.A1) / n,
+		A2: (c.A2 - b.current.A2) / n,
+	}
+	b.remaining = b.smoothing
+}
+
+// Process filters [0:Length] samples of src Buffer and writes the result
+// into dst Buffer. Buffers can be the same. Buffers must have the same
+// number of channels as the filter, otherwise function will panic.
+// Returns a number of samples processed per channel.
+func (b *Biquad[T]) Process(src, dst *signal.Buffer[T]) int {
+	mustSame(len(b.state), src.Channels(), diffChannels)
+	mustSame(len(b.state), dst.Channels(), diffChannels)
+	length := min(src.Length(), dst.Length())
+	for i := 0; i < length; i++ {
+		if b.remaining > 0 {
+			b.interpolate()
+		}
+		c := b.current
+		for ch := range b.state {
+			s := &b.state[ch]
+			idx := src.BufferIndex(ch, i)
+			x := float64(src.Sample(idx))
+			y := c.B0*x + s[0]
+			s[0] = c.B1*x - c.A1*y + s[1]
+			s[1] = c.B2*x - c.A2*y
+			dst.SetSample(idx, T(y))
+		}
+	}
+	return length
+}
+
+// Reset clears the state of all channels.
+func (b *Biquad[T]) Reset() {
+	clear(b.state)
+}
+
+func (b *Biquad[T]) interpolate() {
+	b.remaining--
+	if b.remaining == 0 {
+		// avoid accumulated rounding errors.
+		b.current = b.target
+		return
+	}
+	b.current.B0 += b.step.B0
+	b.current.B1 += b.step.B1
+	b.current.B2 += b.step.B2
+	b.current.A1 += b.step.A1
+	b.current.A2 += b.step.A2
+}
+
+func mustSame[T comparable](a, b T, panicStr string) {
+	if a != b {
+		panic(panicStr)
+	}
+}
@@ -0,0 +1,120 @@
+package filter_test
+
+import (
+	"math"
+	"testing"
+
+	"pipelined.dev/signal"
+	"pipelined.dev/signal/filter"
+)
+
+func TestBiquad(t *testing.T) {
+	const length = 4800
+	sine := func(channels int, freq float64) *signal.Buffer[float64] {
+		b := signal.Alloc[float64](signal.Allocator{Channels: channels, Length: length, Capacity: length})
+		for i := 0; i < length; i++ {
+			for c := 0; c < channels; c++ {
+				b.SetSample(b.BufferIndex(c, i), math.Sin(2*math.Pi*freq*float64(i)/float64(sampleRate)))
+			}
+		}
+		return b
+	}
+	peak := func(b *signal.Buffer[float64], channel int) float64 {
+		var p float64
+		// skip transient.
+		for i := length / 2; i < length; i++ {
+			p = math.Max(p, math.Abs(b.Sample(b.BufferIndex(channel, i))))
+		}
+		return p
+	}
+
+	t.Run("response", func(t *testing.T) {
+		c := filter.LowPass(sampleRate, 1000, filter.Butterworth)
+		for _, freq := range []float64{100, 1000, 5000} {
+			b := filter.NewBiquad[float64](2, c, 0)
+			input := sine(2, freq)
+			output := signal.Alloc[float64](signal.Allocator{Channels: 2, Length: length, Capacity: length})
+			assertEqual(t, "processed", b.Process(input, output), length)
+			expected := math.Pow(10, c.Gain(sampleRate, signal.Frequency(freq))/20)
+			for ch := 0; ch < 2; ch++ {
+				if p := peak(output, ch); math.Abs(p-expected) > 1e-3 {
+					t.Fatalf("%v Hz channel %d: peak %v expected: %v", freq, ch, p, expected)
+				}
+			}
+		}
+	})
+	t.Run("chunks", func(t *testing.T) {
+		c := filter.Peaking(sampleRate, 1000, 2, 6)
+		input := sine(2, 440)
+		expected := signal.Alloc[float32](signal.Allocator{Channels: 2, Length: length, Capacity: length})
+		signal.FloatAsFloat(input, expected)
+		filter.NewBiquad[float32](2, c, 0).Process(expected, expected)
+
+		result := signal.Alloc[float32](signal.Allocator{Channels: 2, Length: length, Capacity: length})
+		signal.FloatAsFloat(input, result)
+		b := filter.NewBiquad[float32](2, c, 0)
+		for i := 0; i < length; i += 100 {
+			chunk := result.Slice(i, i+100)
+			b.Process(chunk, chunk)
+		}
+		assertEqual(t, "samples", result, expected)
+	})
+	t.Run("smoothing", func(t *testing.T) {
+		from := filter.LowPass(sampleRate, 1000, filter.Butterworth)
+		to := filter.LowPass(sampleRate, 5000, filter.Butterworth)
+		b := filter.NewBiquad[float64](1, from, 100)
+		b.Set(to)
+		assertEqual(t, "not changed", b.Coefficients(), from)
+		buf := signal.Alloc[float64](signal.Allocator{Channels: 1, Length: 50, Capacity: 50})
+		b.Process(buf, buf)
+		c := b.Coefficients()
+		if math.Abs(c.B0-(from.B0+to.B0)/2) > 1e-9 || math.Abs(c.A1-(from.A1+to.A1)/2) > 1e-9 {
+			t.Fatalf("coefficients are not interpolated: %+v", c)
+		}
+		b.Process(buf, buf)
+		assertEqual(t, "changed", b.Coefficients(), to)
+
+		instant := filter.NewBiquad[float64](1, from, 0)
+		instant.Set(to)
+		assertEqual(t, "instant", instant.Coefficients(), to)
+	})
+	t.Run("zipper", func(t *testing.T) {
+		// maximum sample to sample difference of the constant signal
+		// filtered with coefficient change.
+		jump := func(smoothing int) float64 {
+			buf := signal.Alloc[float64](signal.Allocator{Channels: 1, Length: length, Capacity: length})
+			for i := 0; i < length; i++ {
+				buf.SetSample(i, 1)
+			}
+			b := filter.NewBiquad[float64](1, filter.LowShelf(sampleRate, 100, filter.Butterworth, 12), smoothing)
+			b.Process(buf.Slice(0, length/2), buf.Slice(0, length/2))
+			b.Set(filter.LowShelf(sampleRate, 100, filter.Butterworth, -12))
+			b.Process(buf.Slice(length/2, length), buf.Slice(length/2, length))
+			var d float64
+			for i := length / 2; i < length; i++ {
+				d = math.Max(d, math.Abs(buf.Sample(i)-buf.Sample(i-1)))
+			}
+			return d
+		}
+		if abrupt, smooth := jump(0), jump(480); smooth >= abrupt/2 {
+			t.Fatalf("change is not smoothed: abrupt %v smooth %v", abrupt, smooth)
+		}
+	})
+	t.Run("reset", func(t *testing.T) {
+		b := filter.NewBiquad[float64](1, filter.LowPass(sampleRate, 1000, filter.Butterworth), 0)
+		input := sine(1, 440)
+		first := signal.Alloc[float64](signal.Allocator{Channels: 1, Length: length, Capacity: length})
+		b.Process(input, first)
+		b.Reset()
+		second := signal.Alloc[float64](signal.Allocator{Channels: 1, Length: length, Capacity: length})
+		b.Process(input, second)
+		assertEqual(t, "samples", second, first)
+	})
+	t.Run("channels", func(t *testing.T) {
+		b := filter.NewBiquad[float64](2, filter.Coefficients{B0: 1}, 0)
+		assertPanic(t, func() {
+			buf := sine(1, 440)
+			b.Process(buf, buf)
+		})
+	})
+}
@@ -0,0 +1,76 @@
+package filter
+
+import (
+	"golang.org/x/exp/constraints"
+	"pipelined.dev/signal"
+)
+
+// Cascade is a higher-order IIR filter that processes every channel of the
+// Buffer with a series of second-order sections. State of channels is kept
+// between calls. Cascade is not safe for concurrent use.
+type Cascade[T constraints.Float] struct {
+	sections []*Biquad[T]
+	channels int
+}
+
+// NewCascade returns Cascade for provided number of channels with the
+// sections.
+func NewCascade[T constraints.Float](channels int, s Sections) *Cascade[T] {
+	c := Cascade[T]{
+		sections: make([]*Biquad[T], len(s)),
+		channels: channels,
+	}
+	for i := range s {
+		c.sections[i] = NewBiquad[T](channels, s[i], 0)
+	}
+	return &c
+}
+
+// Sections returns coefficients of the filter sections.
+func (c *Cascade[T]) Sections() Sections {
+	s := make(Sections, len(c.sections))
+	for i := range c.sections {
+		s[i] = c.sections[i].Coefficients()
+	}
+	return s
+}
+
+// Process filters [0:Length] samples of src Buffer and writes the result
+// into dst Buffer. Buffers can be the same. Buffers must have the same
+// number of channels as the filter, otherwise function will panic.
+// Returns a number of samples processed per channel.
+func (c *Cascade[T]) Process(src, dst *signal.Buffer[T]) int {
+	mustSame(c.channels, src.Channels(), diffChannels)
+	mustSame(c.channels, dst.Channels(), diffChannels)
+	if len(c.sections) == 0 {
+		return copyBuffer(src, dst)
+	}
+	length := c.sections[0].Process(src, dst)
+	dst = dst.Slice(0, length)
+	for _, s := range c.sections[1:] {
+		s.Process(dst, dst)
+	}
+	return length
+}
+
+// Reset clears the state of all channels.
+func (c *Cascade[T]) Reset() {
+	for _, s := range c.sections {
+		s.Reset()
+	}
+}
+
+// copyBuffer copies [0:Length] samples of src Buffer into dst Buffer.
+// Returns a number of samples copied per channel.
+func copyBuffer[T constraints.Float](src, dst *signal.Buffer[T]) int {
+	length := min(src.Length(), dst.Length())
+	if src == dst {
+		return length
+	}
+	for c := 0; c < src.Channels(); c++ {
+		for i := 0; i < length; i++ {
+			dst.SetSample(dst.BufferIndex(c, i), src.Sample(src.BufferIndex(c, i)))
+		}
+	}
+	return length
+}
@@ -0,0 +1,143 @@
+package filter
+
+import (
+	"errors"
+
+	"golang.org/x/exp/constraints"
+	"pipelined.dev/signal"
+)
+
+var (
+	// ErrChannels is returned when number of channels is not positive.
+	ErrChannels = errors.New("filter: invalid number of channels")
+	// ErrOrder is returned when order of Linkwitz-Riley crossover is not
+	// positive and even.
+	ErrOrder = errors.New("filter: invalid crossover order")
+	// ErrFrequency is returned when crossover frequencies are not
+	// ascending or not in range (0, Nyquist).
+	ErrFrequency = errors.New("filter: invalid crossover frequency")
+)
+
+// panic messages.
+const (
+	diffBands = "filter: different number of bands"
+)
+
+// Crossover splits every channel of the Buffer into frequency bands with
+// Linkwitz-Riley filters. Lower bands are passed through all-pass filters
+// of higher crossover frequencies, so all bands are phase-aligned and sum
+// to the signal with flat magnitude response. Crossover is not safe for
+// concurrent use.
+type Crossover[T constraints.Float] struct {
+	channels int
+	low      []*Cascade[T]
+	high     []*Cascade[T]
+	// allPass[k][j] compensates phase of band j for crossover k.
+	allPass [][]*Cascade[T]
+	// invert high-pass output for orders not divisible by four.
+	invert bool
+}
+
+// NewCrossover returns Crossover for provided number of channels. Order
+// of Linkwitz-Riley filters must be positive and even. Frequencies define
+// the boundaries between bands, so number of bands is one more than
+// number of frequencies.
+func NewCrossover[T constraints.Float](channels int, sampleRate signal.Frequency, order int, frequencies ...signal.Frequency) (*Crossover[T], error) {
+	if channels <= 0 {
+		return nil, ErrChannels
+	}
+	if order <= 0 || order%2 != 0 {
+		return nil, ErrOrder
+	}
+	for i, f := range frequencies {
+		if f <= 0 || f >= sampleRate/2 || (i > 0 && f <= frequencies[i-1]) {
+			return nil, ErrFrequency
+		}
+	}
+	p := LinkwitzRileyPrototype(order)
+	x := Crossover[T]{
+		channels: channels,
+		low:      make([]*Cascade[T], len(frequencies)),
+		high:     make([]*Cascade[T], len(frequencies)),
+		allPass:  make([][]*Cascade[T], len(frequencies)),
+		invert:   (order/2)%2 == 1,
+	}
+	for k, f := range frequencies {
+		x.low[k] = NewCascade[T](channels, p.LowPass(sampleRate, f))
+		x.high[k] = NewCascade[T](channels, p.HighPass(sampleRate, f))
+		ap := allPass(ButterworthPrototype(order/2).LowPass(sampleRate, f))
+		x.allPass[k] = make([]*Cascade[T], k)
+		for j := range x.allPass[k] {
+			x.allPass[k][j] = NewCascade[T](channels, ap)
+		}
+	}
+	return &x, nil
+}
+
+// Bands returns the number of bands.
+func (x *Crossover[T]) Bands() int {
+	return len(x.low) + 1
+}
+
+// Process splits [0:Length] samples of src Buffer into bands from the
+// lowest to the highest. Src Buffer can be one of the bands. Buffers must
+// have the same number of channels as the crossover and number of bands
+// must match, otherwise function will panic. Returns a number of samples
+// processed per channel.
+func (x *Crossover[T]) Process(src *signal.Buffer[T], bands ...*signal.Buffer[T]) int {
+	mustSame(x.Bands(), len(bands), diffBands)
+	mustSame(x.channels, src.Channels(), diffChannels)
+	length := src.Length()
+	for _, b := range bands {
+		mustSame(x.channels, b.Channels(), diffChannels)
+		length = min(length, b.Length())
+	}
+	sliced := make([]*signal.Buffer[T], len(bands))
+	for i := range bands {
+		sliced[i] = bands[i].Slice(0, length)
+	}
+	// the highest band accumulates the rest of the signal.
+	rest := sliced[len(sliced)-1]
+	if src != bands[len(bands)-1] {
+		copyBuffer(src.Slice(0, length), rest)
+	}
+	for k := range x.low {
+		x.low[k].Process(rest, sliced[k])
+		x.high[k].Process(rest, rest)
+		if x.invert {
+			for i := 0; i < rest.Len(); i++ {
+				rest.SetSample(i, -rest.Sample(i))
+			}
+		}
+		for j, ap := range x.allPass[k] {
+			ap.Process(sliced[j], sliced[j])
+		}
+	}
+	return length
+}
+
+// Reset clears the state of all filters.
+func (x *Crossover[T]) Reset() {
+	for k := range x.low {
+		x.low[k].Reset()
+		x.high[k].Reset()
+		for _, ap := range x.allPass[k] {
+			ap.Reset()
+		}
+	}
+}
+
+// allPass returns all-pass sections with the same poles as provided
+// sections.
+func allPass(s Sections) Sections {
+	result := make(Sections, len(s))
+	for i, c := range s {
+		if c.A2 == 0 && c.B2 == 0 {
+			// first-order section.
+			result[i] = Coefficients{B0: c.A1, B1: 1, A1: c.A1}
+			continue
+		}
+		result[i] = Coefficients{B0: c.A2, B1: c.A1, B2: 1, A1: c.A1, A2: c.A2}
+	}
+	return result
+}
@@ -0,0 +1,98 @@
+package filter_test
+
+import (
+	"math"
+	"testing"
+
+	"pipelined.dev/signal"
+	"pipelined.dev/signal/filter"
+)
+
+func TestCrossover(t *testing.T) {
+	const length = 9600
+	alloc := func(channels int) *signal.Buffer[float64] {
+		return signal.Alloc[float64](signal.Allocator{Channels: channels, Length: length, Capacity: length})
+	}
+	testFlat := func(order int, frequencies ...signal.Frequency) func(*testing.T) {
+		return func(t *testing.T) {
+			t.Helper()
+			x, err := filter.NewCrossover[float64](2, sampleRate, order, frequencies...)
+			assertNoError(t, err)
+			assertEqual(t, "bands", x.Bands(), len(frequencies)+1)
+			for _, freq := range []float64{50, 300, 1000, 3000, 10000} {
+				x.Reset()
+				input := alloc(2)
+				for i := 0; i < length; i++ {
+					for c := 0; c < 2; c++ {
+						input.SetSample(input.BufferIndex(c, i), math.Sin(2*math.Pi*freq*float64(i)/float64(sampleRate)))
+					}
+				}
+				bands := make([]*signal.Buffer[float64], x.Bands())
+				for i := range bands {
+					bands[i] = alloc(2)
+				}
+				assertEqual(t, "processed", x.Process(input, bands...), length)
+				// sum of bands has flat magnitude response.
+				var power float64
+				for i := length / 2; i < length; i++ {
+					var sum float64
+					for _, b := range bands {
+						sum += b.Sample(b.BufferIndex(1, i))
+					}
+					power += sum * sum
+				}
+				if amplitude := math.Sqrt(4 * power / length); math.Abs(amplitude-1) > 1e-3 {
+					t.Fatalf("sum of bands at %v Hz: %v", freq, amplitude)
+				}
+			}
+		}
+	}
+	t.Run("LR2 two bands", testFlat(2, 1000))
+	t.Run("LR4 two bands", testFlat(4, 1000))
+	t.Run("LR4 three bands", testFlat(4, 200, 2000))
+	t.Run("LR8 four bands", testFlat(8, 200, 1000, 5000))
+	t.Run("LR2 four bands", testFlat(2, 200, 1000, 5000))
+
+	t.Run("band separation", func(t *testing.T) {
+		x, err := filter.NewCrossover[float64](1, sampleRate, 4, 1000)
+		assertNoError(t, err)
+		input := alloc(1)
+		for i := 0; i < length; i++ {
+			input.SetSample(i, math.Sin(2*math.Pi*100*float64(i)/float64(sampleRate)))
+		}
+		low := alloc(1)
+		// src buffer can be one of the bands.
+		x.Process(input, low, input)
+		var lowPeak, highPeak float64
+		for i := length / 2; i < length; i++ {
+			lowPeak = math.Max(lowPeak, math.Abs(low.Sample(i)))
+			highPeak = math.Max(highPeak, math.Abs(input.Sample(i)))
+		}
+		if math.Abs(lowPeak-1) > 1e-3 || highPeak > 1e-3 {
+			t.Fatalf("low: %v high: %v", lowPeak, highPeak)
+		}
+	})
+
+	testError := func(order int, expected error, frequencies ...signal.Frequency) func(*testing.T) {
+		return func(t *testing.T) {
+			t.Helper()
+			_, err := filter.NewCrossover[float64](2, sampleRate, order, frequencies...)
+			assertEqual(t, "error", err, expected)
+		}
+	}
+	t.Run("odd order", testError(3, filter.ErrOrder, 1000))
+	t.Run("zero order", testError(0, filter.ErrOrder, 1000))
+	t.Run("zero frequency", testError(4, filter.ErrFrequency, 0))
+	t.Run("nyquist frequency", testError(4, filter.ErrFrequency, 24000))
+	t.Run("not ascending", testError(4, filter.ErrFrequency, 2000, 1000))
+	_, err := filter.NewCrossover[float64](0, sampleRate, 4, 1000)
+	assertEqual(t, "channels error", err, filter.ErrChannels)
+
+	x, _ := filter.NewCrossover[float64](2, sampleRate, 4, 1000)
+	assertPanic(t, func() {
+		x.Process(alloc(2), alloc(2))
+	})
+	assertPanic(t, func() {
+		x.Process(alloc(2), alloc(1), alloc(2))
+	})
+}
@@ -0,0 +1,344 @@
+package filter
+
+import (
+	"math"
+	"math/cmplx"
+	"sort"
+
+	"pipelined.dev/signal"
+)
+
+// panic messages.
+const (
+	invalidOrder = "filter: invalid order"
+)
+
+// Sections is a cascade of second-order sections.
+type Sections []Coefficients
+
+// Response returns the complex frequency response of the cascade at the
+// frequency.
+func (s Sections) Response(sampleRate, freq signal.Frequency) complex128 {
+	r := complex(1, 0)
+	for _, c := range s {
+		r *= c.Response(sampleRate, freq)
+	}
+	return r
+}
+
+// Gain returns the magnitude response of the cascade at the frequency in
+// decibels.
+func (s Sections) Gain(sampleRate, freq signal.Frequency) float64 {
+	return 20 * math.Log10(cmplx.Abs(s.Response(sampleRate, freq)))
+}
+
+// Prototype is an analog low-pass filter with the cut-off angular
+// frequency of 1 rad/s, defined by its poles, zeros and the gain at zero
+// frequency. Digital filters are designed from prototypes with bilinear
+// transform.
+type Prototype struct {
+	Poles []complex128
+	Zeros []complex128
+	Gain  float64
+}
+
+// ButterworthPrototype returns a prototype of Butterworth filter with
+// maximally flat pass-band. The gain at the cut-off frequency is -3 dB.
+func ButterworthPrototype(order int) Prototype {
+	mustValidOrder(order)
+	p := Prototype{Gain: 1}
+	for k := 0; k < order; k++ {
+		p.Poles = append(p.Poles, cmplx.Rect(1, math.Pi*float64(2*k+order+1)/float64(2*order)))
+	}
+	return p
+}
+
+// ChebyshevIPrototype returns a prototype of Chebyshev type I filter with
+// the pass-band ripple in decibels. The gain at the cut-off frequency is
+// -ripple dB.
+func ChebyshevIPrototype(order int, ripple float64) Prototype {
+	mustValidOrder(order)
+	eps := math.Sqrt(math.Pow(10, ripple/10) - 1)
+	mu := math.Asinh(1/eps) / float64(order)
+	p := Prototype{Gain: 1}
+	if order%2 == 0 {
+		p.Gain = 1 / math.Sqrt(1+eps*eps)
+	}
+	for k := 0; k < order; k++ {
+		theta := math.Pi * float64(2*k+1) / float64(2*order)
+		p.Poles = append(p.Poles, complex(-math.Sinh(mu)*math.Sin(theta), math.Cosh(mu)*math.Cos(theta)))
+	}
+	return p
+}
+
+// ChebyshevIIPrototype returns a prototype of Chebyshev type II filter
+// with the stop-band attenuation in decibels. The gain at the cut-off
+// frequency is -attenuation dB.
+func ChebyshevIIPrototype(order int, attenuation float64) Prototype {
+	mustValidOrder(order)
+	eps := 1 / math.Sqrt(math.Pow(10, attenuation/10)-1)
+	mu := math.Asinh(1/eps) / float64(order)
+	p := Prototype{Gain: 1}
+	for k := 0; k < order; k++ {
+		theta := math.Pi * float64(2*k+1) / float64(2*order)
+		p.Poles = append(p.Poles, 1/complex(-math.Sinh(mu)*math.Sin(theta), math.Cosh(mu)*math.Cos(theta)))
+		// zero of the odd order is at infinity.
+		if 2*k+1 != order {
+			p.Zeros = append(p.Zeros, complex(0, 1/math.Cos(theta)))
+		}
+	}
+	return p
+}
+
+// BesselPrototype returns a prototype of Bessel filter with maximally flat
+// group delay. The gain at the cut-off frequency is -3 dB.
+func BesselPrototype(order int) Prototype {
+	mustValidOrder(order)
+	// coefficients of the reverse Bessel polynomial.
+	coefs := make([]float64, order+1)
+	for k := 0; k <= order; k++ {
+		coefs[k] = math.Exp(lgamma(2*order-k+1) - float64(order-k)*math.Ln2 - lgamma(k+1) - lgamma(order-k+1))
+	}
+	p := Prototype{
+		Poles: roots(coefs),
+		Gain:  1,
+	}
+	// scale poles to have -3 dB gain at 1 rad/s.
+	magnitude := func(w float64) float64 {
+		h := complex(1, 0)
+		for _, pole := range p.Poles {
+			h *= -pole / (complex(0, w) - pole)
+		}
+		return cmplx.Abs(h)
+	}
+	lo, hi := 0.0, 1.0
+	for magnitude(hi) > math.Sqrt2/2 {
+		hi *= 2
+	}
+	for i := 0; i < 100; i++ {
+		mid := (lo + hi) / 2
+		if magnitude(mid) > math.Sqrt2/2 {
+			lo = mid
+		} else {
+			hi = mid
+		}
+	}
+	for i := range p.Poles {
+		p.Poles[i] /= complex(lo, 0)
+	}
+	return p
+}
+
+// LinkwitzRileyPrototype returns a prototype of Linkwitz-Riley filter,
+// which is a squared Butterworth filter. Order must be even, otherwise
+// function will panic. The gain at the cut-off frequency is -6 dB. Low-pass
+// and high-pass filters of the same order sum to all-pass response if the
+// high-pass output is inverted for orders not divisible by four.
+func LinkwitzRileyPrototype(order int) Prototype {
+	if order%2 != 0 {
+		panic(invalidOrder)
+	}
+	b := ButterworthPrototype(order / 2)
+	return Prototype{
+		Poles: append(b.Poles, b.Poles...),
+		Gain:  1,
+	}
+}
+
+// LowPass returns sections of the digital low-pass filter with the
+// cut-off frequency.
+func (p Prototype) LowPass(sampleRate, cutoff signal.Frequency) Sections {
+	w := prewarp(sampleRate, cutoff)
+	poles := make([]complex128, len(p.Poles))
+	for i, pole := range p.Poles {
+		poles[i] = pole * complex(w, 0)
+	}
+	zeros := make([]complex128, len(p.Zeros))
+	for i, zero := range p.Zeros {
+		zeros[i] = zero * complex(w, 0)
+	}
+	return design(sampleRate, poles, zeros, -1, p.Gain, 0)
+}
+
+// HighPass returns sections of the digital high-pass filter with the
+// cut-off frequency.
+func (p Prototype) HighPass(sampleRate, cutoff signal.Frequency) Sections {
+	w := prewarp(sampleRate, cutoff)
+	poles := make([]complex128, len(p.Poles))
+	for i, pole := range p.Poles {
+		poles[i] = complex(w, 0) / pole
+	}
+	zeros := make([]complex128, len(p.Zeros))
+	for i, zero := range p.Zeros {
+		zeros[i] = complex(w, 0) / zero
+	}
+	return design(sampleRate, poles, zeros, 1, p.Gain, sampleRate/2)
+}
+
+// prewarp returns analog angular frequency that is mapped to the digital
+// frequency by bilinear transform.
+func prewarp(sampleRate, freq signal.Frequency) float64 {
+	return 2 * float64(sampleRate) * math.Tan(math.Pi*float64(freq)/float64(sampleRate))
+}
+
+// design applies bilinear transform to analog poles and zeros and groups
+// them into second-order sections. Analog zeros at infinity are mapped to
+// provided digital zero. The gain of the cascade at the reference
+// frequency is set to provided gain.
+func design(sampleRate signal.Frequency, poles, zeros []complex128, infinity complex128, gain float64, ref signal.Frequency) Sections {
+	fs2 := complex(2*float64(sampleRate), 0)
+	bilinear := func(s complex128) complex128 {
+		return (fs2 + s) / (fs2 - s)
+	}
+	dpoles := make([]complex128, len(poles))
+	for i := range poles {
+		dpoles[i] = bilinear(poles[i])
+	}
+	dzeros := make([]complex128, 0, len(poles))
+	for i := range zeros {
+		dzeros = append(dzeros, bilinear(zeros[i]))
+	}
+	for len(dzeros) < len(dpoles) {
+		dzeros = append(dzeros, infinity)
+	}
+
+	polePairs := pairs(dpoles)
+	zeroPairs := pairs(dzeros)
+	// sections with poles closest to the unit circle come last.
+	sort.SliceStable(polePairs, func(i, j int) bool {
+		return cmplx.Abs(polePairs[i][0]) < cmplx.Abs(polePairs[j][0])
+	})
+	sections := make(Sections, 0, len(polePairs))
+	for _, pp := range polePairs {
+		// pick the closest zeros with the same number of roots.
+		best := -1
+		for i, zp := range zeroPairs {
+			if len(zp) != len(pp) {
+				continue
+			}
+			if best == -1 || cmplx.Abs(zp[0]-pp[0]) < cmplx.Abs(zeroPairs[best][0]-pp[0]) {
+				best = i
+			}
+		}
+		zp := zeroPairs[best]
+		zeroPairs = append(zeroPairs[:best], zeroPairs[best+1:]...)
+		c := section(pp, zp)
+		// normalize section to unity gain at the reference frequency.
+		scale := 1 / real(c.Response(sampleRate, ref))
+		c.B0 *= scale
+		c.B1 *= scale
+		c.B2 *= scale
+		sections = append(sections, c)
+	}
+	if len(sections) > 0 {
+		sections[0].B0 *= gain
+		sections[0].B1 *= gain
+		sections[0].B2 *= gain
+	}
+	return sections
+}
+
+// pairs groups roots into complex conjugate pairs and pairs of real roots.
+// The last group contains a single real root if the number of real roots
+// is odd.
+func pairs(roots []complex128) [][]complex128 {
+	const eps = 1e-9
+	var (
+		result [][]complex128
+		reals  []complex128
+	)
+	for _, r := range roots {
+		switch {
+		case imag(r) > eps:
+			result = append(result, []complex128{r, cmplx.Conj(r)})
+		case imag(r) < -eps:
+			// conjugate is added with positive root.
+		default:
+			reals = append(reals, complex(real(r), 0))
+		}
+	}
+	for i := 0; i+1 < len(reals); i += 2 {
+		result = append(result, []complex128{reals[i], reals[i+1]})
+	}
+	if len(reals)%2 == 1 {
+		result = append(result, []complex128{reals[len(reals)-1]})
+	}
+	return result
+}
+
+// section returns coefficients of the section with provided roots.
+func section(poles, zeros []complex128) Coefficients {
+	if len(poles) == 1 {
+		return Coefficients{
+			B0: 1,
+			B1: -real(zeros[0]),
+			A1: -real(poles[0]),
+		}
+	}
+	return Coefficients{
+		B0: 1,
+		B1: -real(zeros[0] + zeros[1]),
+		B2: real(zeros[0] * zeros[1]),
+		A1: -real(poles[0] + poles[1]),
+		A2: real(poles[0] * poles[1]),
+	}
+}
+
+// roots returns roots of the polynomial with coefficients in ascending
+// order using Durand-Kerner method.
+func roots(coefs []float64) []complex128 {
+	n := len(coefs) - 1
+	// scale the variable, so roots have unity magnitude on average.
+	scale := math.Pow(math.Abs(coefs[0]/coefs[n]), 1/float64(n))
+	monic := make([]complex128, n+1)
+	for k := range coefs {
+		monic[k] = complex(coefs[k]/coefs[n]/math.Pow(scale, float64(n-k)), 0)
+	}
+	eval := func(x complex128) complex128 {
+		v := complex(0, 0)
+		for k := n; k >= 0; k-- {
+			v = v*x + monic[k]
+		}
+		return v
+	}
+	result := make([]complex128, n)
+	for i := range result {
+		result[i] = cmplx.Pow(complex(0.4, 0.9), complex(float64(i), 0))
+	}
+	for iter := 0; iter < 1000; iter++ {
+		var delta float64
+		for i := range result {
+			den := complex(1, 0)
+			for j := range result {
+				if i != j {
+					den *= result[i] - result[j]
+				}
+			}
+			d := eval(result[i]) / den
+			result[i] -= d
+			delta = math.Max(delta, cmplx.Abs(d))
+		}
+		if delta < 1e-15 {
+			break
+		}
+	}
+	for i := range result {
+		result[i] *= complex(scale, 0)
+		// polish imaginary parts of real roots.
+		if math.Abs(imag(result[i])) < 1e-12*cmplx.Abs(result[i]) {
+			result[i] = complex(real(result[i]), 0)
+		}
+	}
+	return result
+}
+
+func lgamma(x int) float64 {
+	v, _ := math.Lgamma(float64(x))
+	return v
+}
+
+func mustValidOrder(order int) {
+	if order < 1 {
+		panic(invalidOrder)
+	}
+}
@@ -0,0 +1,139 @@
+package filter_test
+
+import (
+	"math"
+	"testing"
+
+	"pipelined.dev/signal"
+	"pipelined.dev/signal/filter"
+)
+
+func TestPrototype(t *testing.T) {
+	type gain struct {
+		freq signal.Frequency
+		db   float64
+	}
+	testOk := func(s filter.Sections, sections int, expected ...gain) func(*testing.T) {
+		return func(t *testing.T) {
+			t.Helper()
+			assertEqual(t, "sections", len(s), sections)
+			for _, e := range expected {
+				if g := s.Gain(sampleRate, e.freq); math.Abs(g-e.db) > 0.05 {
+					t.Fatalf("gain at %v Hz: %v dB expected: %v dB", e.freq, g, e.db)
+				}
+			}
+		}
+	}
+	for _, order := range []int{1, 2, 3, 4, 7, 8} {
+		// magnitude of butterworth filter with prewarped frequencies.
+		butterworth := func(ratio float64) float64 {
+			return -10 * math.Log10(1+math.Pow(ratio, float64(2*order)))
+		}
+		warp := func(f float64) float64 {
+			return math.Tan(math.Pi * f / float64(sampleRate))
+		}
+		t.Run("butterworth low pass", testOk(filter.ButterworthPrototype(order).LowPass(sampleRate, 1000), (order+1)/2,
+			gain{0, 0}, gain{1000, -3.01}, gain{100, 0}, gain{4000, butterworth(warp(4000) / warp(1000))}))
+		t.Run("butterworth high pass", testOk(filter.ButterworthPrototype(order).HighPass(sampleRate, 1000), (order+1)/2,
+			gain{24000, 0}, gain{1000, -3.01}, gain{10000, 0}, gain{250, butterworth(warp(1000) / warp(250))}))
+		t.Run("bessel low pass", testOk(filter.BesselPrototype(order).LowPass(sampleRate, 1000), (order+1)/2,
+			gain{0, 0}, gain{1000, -3.01}))
+		t.Run("bessel high pass", testOk(filter.BesselPrototype(order).HighPass(sampleRate, 1000), (order+1)/2,
+			gain{24000, 0}, gain{1000, -3.01}))
+		t.Run("chebyshev I low pass", testOk(filter.ChebyshevIPrototype(order, 1).LowPass(sampleRate, 1000), (order+1)/2,
+			gain{1000, -1}))
+		t.Run("chebyshev I high pass", testOk(filter.ChebyshevIPrototype(order, 1).HighPass(sampleRate, 1000), (order+1)/2,
+			gain{1000, -1}))
+		t.Run("chebyshev II low pass", testOk(filter.ChebyshevIIPrototype(order, 40).LowPass(sampleRate, 1000), (order+1)/2,
+			gain{0, 0}, gain{1000, -40}))
+		t.Run("chebyshev II high pass", testOk(filter.ChebyshevIIPrototype(order, 40).HighPass(sampleRate, 1000), (order+1)/2,
+			gain{24000, 0}, gain{1000, -40}))
+	}
+	t.Run("linkwitz-riley", testOk(filter.LinkwitzRileyPrototype(4).LowPass(sampleRate, 1000), 2,
+		gain{0, 0}, gain{1000, -6.02}))
+
+	// pass-band ripple and stop-band attenuation.
+	cheby1 := filter.ChebyshevIPrototype(5, 0.5).LowPass(sampleRate, 1000)
+	cheby2 := filter.ChebyshevIIPrototype(5, 60).LowPass(sampleRate, 1000)
+	for f := signal.Frequency(10); f < 1000; f += 10 {
+		if g := cheby1.Gain(sampleRate, f); g > 1e-9 || g < -0.5-1e-9 {
+			t.Fatalf("chebyshev I ripple at %v Hz: %v dB", f, g)
+		}
+		if g := cheby2.Gain(sampleRate, 1000+f*20); g > -60+1e-6 {
+			t.Fatalf("chebyshev II attenuation at %v Hz: %v dB", 1000+f*20, g)
+		}
+	}
+
+	t.Run("bessel group delay", func(t *testing.T) {
+		s := filter.BesselPrototype(6).LowPass(sampleRate, 1000)
+		delay := func(f signal.Frequency) float64 {
+			const df = 1
+			p1, p2 := s.Response(sampleRate, f), s.Response(sampleRate, f+df)
+			return -phaseDiff(p2, p1) / (2 * math.Pi * df)
+		}
+		d0 := delay(10)
+		if d := delay(500); math.Abs(d-d0)/d0 > 0.01 {
+			t.Fatalf("group delay: %v expected: %v", d, d0)
+		}
+	})
+
+	assertPanic(t, func() {
+		filter.ButterworthPrototype(0)
+	})
+	assertPanic(t, func() {
+		filter.LinkwitzRileyPrototype(3)
+	})
+}
+
+func TestCascade(t *testing.T) {
+	const length = 4800
+	s := filter.ButterworthPrototype(6).LowPass(sampleRate, 2000)
+	impulse := signal.Alloc[float64](signal.Allocator{Channels: 2, Length: length, Capacity: length})
+	impulse.SetSample(impulse.BufferIndex(0, 0), 1)
+	impulse.SetSample(impulse.BufferIndex(1, 0), 1)
+
+	c := filter.NewCascade[float64](2, s)
+	assertEqual(t, "sections", c.Sections(), s)
+	output := signal.Alloc[float64](signal.Allocator{Channels: 2, Length: length, Capacity: length})
+	assertEqual(t, "processed", c.Process(impulse, output), length)
+
+	// impulse response is processed section by section.
+	expected := signal.Alloc[float64](signal.Allocator{Channels: 2, Length: length, Capacity: length})
+	expected.Append(impulse)
+	expected = expected.Slice(length, 2*length)
+	for _, sc := range s {
+		filter.NewBiquad[float64](2, sc, 0).Process(expected, expected)
+	}
+	assertEqual(t, "impulse response", output, expected)
+
+	// sum of impulse response is a gain at zero frequency.
+	var sum float64
+	for i := 0; i < length; i++ {
+		sum += output.Sample(output.BufferIndex(1, i))
+	}
+	if math.Abs(sum-1) > 1e-9 {
+		t.Fatalf("dc gain: %v", sum)
+	}
+
+	c.Reset()
+	processed := signal.Alloc[float64](signal.Allocator{Channels: 2, Length: length, Capacity: length})
+	c.Process(impulse.Slice(0, length/2), processed.Slice(0, length/2))
+	c.Process(impulse.Slice(length/2, length), processed.Slice(length/2, length))
+	assertEqual(t, "streamed", processed, output)
+
+	assertPanic(t, func() {
+		c.Process(signal.Alloc[float64](signal.Allocator{Channels: 1, Length: 1, Capacity: 1}), output)
+	})
+}
+
+// phaseDiff returns unwrapped phase difference of two responses.
+func phaseDiff(a, b complex128) float64 {
+	d := math.Atan2(imag(a), real(a)) - math.Atan2(imag(b), real(b))
+	for d > math.Pi {
+		d -= 2 * math.Pi
+	}
+	for d < -math.Pi {
+		d += 2 * math.Pi
+	}
+	return d
+}
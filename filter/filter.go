@@ -0,0 +1,163 @@
+// Package filter provides IIR filters for signal buffers.
+//
+// Filters are defined by coefficients of second-order sections. Designer
+// functions return coefficients for the sample rate and parameters of the
+// filter, Biquad applies them to every channel of the Buffer:
+//
+//	b := filter.NewBiquad[float64](2, filter.LowPass(44100, 1000, filter.Butterworth), 64)
+//	b.Process(buf, buf)
+//	// coefficients are smoothly changed over 64 samples.
+//	b.Set(filter.LowPass(44100, 2000, filter.Butterworth))
+//	b.Process(buf, buf)
+//
+// Higher-order filters are designed from analog prototypes and applied
+// as a cascade of second-order sections:
+//
+//	s := filter.ButterworthPrototype(8).LowPass(44100, 18000)
+//	c := filter.NewCascade[float64](2, s)
+//	c.Process(buf, buf)
+//
+// Crossover splits the Buffer into phase-aligned bands with
+// Linkwitz-Riley filters:
+//
+//	x, err := filter.NewCrossover[float64](2, 44100, 4, 200, 2000)
+//	if err != nil {
+//		return err
+//	}
+//	x.Process(buf, low, mid, high)
+package filter
+
+import (
+	"math"
+	"math/cmplx"
+
+	"pipelined.dev/signal"
+)
+
+// Butterworth is a Q factor of the second-order section with maximally
+// flat pass-band.
+const Butterworth = math.Sqrt2 / 2
+
+// Coefficients of the second-order section normalized by a0:
+//
+//	H(z) = (B0 + B1*z^-1 + B2*z^-2) / (1 + A1*z^-1 + A2*z^-2)
+type Coefficients struct {
+	B0, B1, B2 float64
+	A1, A2     float64
+}
+
+// Response returns the complex frequency response of the section at the
+// frequency.
+func (c Coefficients) Response(sampleRate, freq signal.Frequency) complex128 {
+	w := 2 * math.Pi * float64(freq) / float64(sampleRate)
+	z1 := cmplx.Rect(1, -w)
+	z2 := z1 * z1
+	num := complex(c.B0, 0) + complex(c.B1, 0)*z1 + complex(c.B2, 0)*z2
+	den := 1 + complex(c.A1, 0)*z1 + complex(c.A2, 0)*z2
+	return num / den
+}
+
+// Gain returns the magnitude response of the section at the frequency in
+// decibels.
+func (c Coefficients) Gain(sampleRate, freq signal.Frequency) float64 {
+	return 20 * math.Log10(cmplx.Abs(c.Response(sampleRate, freq)))
+}
+
+// LowPass returns coefficients of the second-order low-pass filter.
+func LowPass(sampleRate, cutoff signal.Frequency, q float64) Coefficients {
+	cos, alpha := params(sampleRate, cutoff, q)
+	return normalize(
+		(1-cos)/2, 1-cos, (1-cos)/2,
+		1+alpha, -2*cos, 1-alpha,
+	)
+}
+
+// HighPass returns coefficients of the second-order high-pass filter.
+func HighPass(sampleRate, cutoff signal.Frequency, q float64) Coefficients {
+	cos, alpha := params(sampleRate, cutoff, q)
+	return normalize(
+		(1+cos)/2, -(1 + cos), (1+cos)/2,
+		1+alpha, -2*cos, 1-alpha,
+	)
+}
+
+// BandPass returns coefficients of the second-order band-pass filter with
+// 0 dB gain at the center frequency.
+func BandPass(sampleRate, center signal.Frequency, q float64) Coefficients {
+	cos, alpha := params(sampleRate, center, q)
+	return normalize(
+		alpha, 0, -alpha,
+		1+alpha, -2*cos, 1-alpha,
+	)
+}
+
+// Notch returns coefficients of the second-order band-stop filter.
+func Notch(sampleRate, center signal.Frequency, q float64) Coefficients {
+	cos, alpha := params(sampleRate, center, q)
+	return normalize(
+		1, -2*cos, 1,
+		1+alpha, -2*cos, 1-alpha,
+	)
+}
+
+// AllPass returns coefficients of the second-order all-pass filter with
+// -180 degrees phase shift at the center frequency.
+func AllPass(sampleRate, center signal.Frequency, q float64) Coefficients {
+	cos, alpha := params(sampleRate, center, q)
+	return normalize(
+		1-alpha, -2*cos, 1+alpha,
+		1+alpha, -2*cos, 1-alpha,
+	)
+}
+
+// Peaking returns coefficients of the peaking equalizer with the gain in
+// decibels at the center frequency.
+func Peaking(sampleRate, center signal.Frequency, q, gain float64) Coefficients {
+	cos, alpha := params(sampleRate, center, q)
+	a := math.Pow(10, gain/40)
+	return normalize(
+		1+alpha*a, -2*cos, 1-alpha*a,
+		1+alpha/a, -2*cos, 1-alpha/a,
+	)
+}
+
+// LowShelf returns coefficients of the low-shelf filter with the gain in
+// decibels below the corner frequency.
+func LowShelf(sampleRate, corner signal.Frequency, q, gain float64) Coefficients {
+	cos, alpha := params(sampleRate, corner, q)
+	a := math.Pow(10, gain/40)
+	sqrt := 2 * math.Sqrt(a) * alpha
+	return normalize(
+		a*((a+1)-(a-1)*cos+sqrt), 2*a*((a-1)-(a+1)*cos), a*((a+1)-(a-1)*cos-sqrt),
+		(a+1)+(a-1)*cos+sqrt, -2*((a-1)+(a+1)*cos), (a+1)+(a-1)*cos-sqrt,
+	)
+}
+
+// HighShelf returns coefficients of the high-shelf filter with the gain
+// in decibels above the corner frequency.
+func HighShelf(sampleRate, corner signal.Frequency, q, gain float64) Coefficients {
+	cos, alpha := params(sampleRate, corner, q)
+	a := math.Pow(10, gain/40)
+	sqrt := 2 * math.Sqrt(a) * alpha
+	return normalize(
+		a*((a+1)+(a-1)*cos+sqrt), -2*a*((a-1)+(a+1)*cos), a*((a+1)+(a-1)*cos-sqrt),
+		(a+1)-(a-1)*cos+sqrt, 2*((a-1)-(a+1)*cos), (a+1)-(a-1)*cos-sqrt,
+	)
+}
+
+// params returns cosine of the normalized angular frequency and alpha
+// parameter of the cookbook formulae.
+func params(sampleRate, freq signal.Frequency, q float64) (cos, alpha float64) {
+	w := 2 * math.Pi * float64(freq) / float64(sampleRate)
+	return math.Cos(w), math.Sin(w) / (2 * q)
+}
+
+func normalize(b0, b1, b2, a0, a1, a2 float64) Coefficients {
+	return Coefficients{
+		B0: b0 / a0,
+		B1: b1 / a0,
+		B2: b2 / a0,
+		A1: a1 / a0,
+		A2: a2 / a0,
+	}
+}
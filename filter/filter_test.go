@@ -0,0 +1,76 @@
+package filter_test
+
+import (
+	"math"
+	"math/cmplx"
+	"reflect"
+	"testing"
+
+	"pipelined.dev/signal"
+	"pipelined.dev/signal/filter"
+)
+
+const sampleRate signal.Frequency = 48000
+
+func TestDesign(t *testing.T) {
+	type gain struct {
+		freq signal.Frequency
+		db   float64
+	}
+	testOk := func(c filter.Coefficients, expected ...gain) func(*testing.T) {
+		return func(t *testing.T) {
+			t.Helper()
+			for _, e := range expected {
+				if g := c.Gain(sampleRate, e.freq); math.Abs(g-e.db) > 0.1 {
+					t.Fatalf("gain at %v Hz: %v dB expected: %v dB", e.freq, g, e.db)
+				}
+			}
+		}
+	}
+	t.Run("low pass", testOk(filter.LowPass(sampleRate, 1000, filter.Butterworth),
+		gain{0, 0}, gain{1000, -3.01}, gain{10000, -42.74}))
+	t.Run("high pass", testOk(filter.HighPass(sampleRate, 1000, filter.Butterworth),
+		gain{23999, 0}, gain{1000, -3.01}, gain{100, -40}))
+	t.Run("band pass", testOk(filter.BandPass(sampleRate, 1000, 2),
+		gain{1000, 0}, gain{10, -46.03}))
+	t.Run("notch", testOk(filter.Notch(sampleRate, 1000, 2),
+		gain{0, 0}, gain{23999, 0}, gain{999, -47.93}))
+	t.Run("all pass", testOk(filter.AllPass(sampleRate, 1000, 1),
+		gain{0, 0}, gain{1000, 0}, gain{10000, 0}))
+	t.Run("peaking", testOk(filter.Peaking(sampleRate, 1000, 1, 6),
+		gain{1000, 6}, gain{0, 0}, gain{23999, 0}))
+	t.Run("low shelf", testOk(filter.LowShelf(sampleRate, 1000, filter.Butterworth, -12),
+		gain{0, -12}, gain{1000, -6}, gain{23999, 0}))
+	t.Run("high shelf", testOk(filter.HighShelf(sampleRate, 1000, filter.Butterworth, 12),
+		gain{0, 0}, gain{1000, 6}, gain{23999, 12}))
+
+	// all-pass phase at center frequency.
+	phase := cmplx.Phase(filter.AllPass(sampleRate, 1000, 1).Response(sampleRate, 1000))
+	if math.Abs(math.Abs(phase)-math.Pi) > 1e-9 {
+		t.Fatalf("all-pass phase: %v", phase)
+	}
+}
+
+func assertEqual(t *testing.T, name string, result, expected interface{}) {
+	t.Helper()
+	if !reflect.DeepEqual(expected, result) {
+		t.Fatalf("%v\nresult: \t%T\t%+v \nexpected: \t%T\t%+v", name, result, result, expected, expected)
+	}
+}
+
+func assertPanic(t *testing.T, fn func()) {
+	t.Helper()
+	defer func() {
+		if r := recover(); r == nil {
+			t.Fatalf("didn't panic")
+		}
+	}()
+	fn()
+}
+
+func assertNoError(t *testing.T, err error) {
+	t.Helper()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
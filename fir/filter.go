@@ -0,0 +1,191 @@
+package fir
+
+import (
+	"errors"
+	"math"
+
+	"golang.org/x/exp/constraints"
+	"pipelined.dev/signal"
+	"pipelined.dev/signal/fft"
+)
+
+var (
+	// ErrChannels is returned when number of channels is not positive.
+	ErrChannels = errors.New("fir: invalid number of channels")
+)
+
+// panic messages.
+const (
+	diffChannels = "fir: different number of channels"
+)
+
+const (
+	// directBlock is a number of samples processed at once in direct form.
+	directBlock = 1024
+	// fftCost is an estimated cost of real transform per sample and
+	// log2(size) relative to multiply-add of direct form.
+	fftCost = 2
+)
+
+// Filter is a streaming FIR filter that processes every channel of the
+// Buffer. Samples are processed in blocks, each block is computed in
+// direct form or with FFT-based overlap-save method, whichever is cheaper
+// for the number of taps and block length. Both methods have no latency.
+// State of channels is kept between calls. Filter is not safe for
+// concurrent use.
+type Filter[T constraints.Float] struct {
+	taps    Taps
+	history [][]float64
+	// line contains history followed by the block of input samples.
+	line  []float64
+	block int
+	// plan is nil if overlap-save is never cheaper.
+	plan     *fft.RealPlan
+	response []complex128
+	spectrum []complex128
+	output   []float64
+}
+
+// New returns Filter for provided number of channels with the taps.
+func New[T constraints.Float](channels int, taps Taps) (*Filter[T], error) {
+	if channels <= 0 {
+		return nil, ErrChannels
+	}
+	if len(taps) == 0 {
+		return nil, ErrTaps
+	}
+	f := Filter[T]{
+		taps:    append(Taps(nil), taps...),
+		history: make([][]float64, channels),
+		block:   directBlock,
+	}
+	if size := transformSize(len(taps)); size > 0 {
+		plan, err := fft.NewRealPlan(size)
+		if err != nil {
+			return nil, err
+		}
+		padded := make([]float64, size)
+		copy(padded, taps)
+		f.plan = plan
+		f.block = size - len(taps) + 1
+		f.response = make([]complex128, plan.SpectrumLen())
+		f.spectrum = make([]complex128, plan.SpectrumLen())
+		plan.Forward(f.response, padded)
+	}
+	for c := range f.history {
+		f.history[c] = make([]float64, len(taps)-1)
+	}
+	f.line = make([]float64, len(taps)-1+f.block)
+	f.output = make([]float64, len(f.line))
+	return &f, nil
+}
+
+// Taps returns taps of the filter.
+func (f *Filter[T]) Taps() Taps {
+	return append(Taps(nil), f.taps...)
+}
+
+// Process filters [0:Length] samples of src Buffer and writes the result
+// into dst Buffer. Buffers can be the same. Buffers must have the same
+// number of channels as the filter, otherwise function will panic.
+// Returns a number of samples processed per channel.
+func (f *Filter[T]) Process(src, dst *signal.Buffer[T]) int {
+	mustSame(len(f.history), src.Channels(), diffChannels)
+	mustSame(len(f.history), dst.Channels(), diffChannels)
+	length := min(src.Length(), dst.Length())
+	for offset := 0; offset < length; offset += f.block {
+		n := min(f.block, length-offset)
+		for c, history := range f.history {
+			line := f.line[:len(history)+n]
+			copy(line, history)
+			for i := 0; i < n; i++ {
+				line[len(history)+i] = float64(src.Sample(src.BufferIndex(c, offset+i)))
+			}
+			if f.plan != nil && f.overlapSaveCheaper(n) {
+				f.overlapSave(line)
+			} else {
+				f.direct(line)
+			}
+			for i := 0; i < n; i++ {
+				dst.SetSample(dst.BufferIndex(c, offset+i), T(f.output[i]))
+			}
+			copy(history, line[n:])
+		}
+	}
+	return length
+}
+
+// Reset clears the state of all channels.
+func (f *Filter[T]) Reset() {
+	for _, h := range f.history {
+		clear(h)
+	}
+}
+
+// direct computes the convolution of the line in direct form.
+func (f *Filter[T]) direct(line []float64) {
+	offset := len(f.taps) - 1
+	n := len(line) - offset
+	for i := 0; i < n; i++ {
+		var sum float64
+		x := line[i : i+len(f.taps)]
+		for k, t := range f.taps {
+			sum += t * x[len(x)-1-k]
+		}
+		f.output[i] = sum
+	}
+}
+
+// overlapSave computes the convolution of the line with FFT. The first
+// samples of circular convolution are aliased and discarded.
+func (f *Filter[T]) overlapSave(line []float64) {
+	offset := len(f.taps) - 1
+	frame := f.line[:f.plan.Len()]
+	clear(frame[len(line):])
+	f.plan.Forward(f.spectrum, frame)
+	for k, v := range f.response {
+		f.spectrum[k] *= v
+	}
+	f.plan.Inverse(f.output, f.spectrum)
+	copy(f.output, f.output[offset:])
+}
+
+// overlapSaveCheaper reports if overlap-save is cheaper than direct form
+// for the block of n samples.
+func (f *Filter[T]) overlapSaveCheaper(n int) bool {
+	return n*len(f.taps) > transformCost(f.plan.Len())
+}
+
+// transformSize returns the size of transform for overlap-save method with
+// the lowest cost per sample. Zero is returned if direct form is cheaper.
+func transformSize(taps int) int {
+	best, bestCost := 0, float64(taps)
+	for size := 1 << bits(2*taps-1); size <= 64*taps && size <= 1<<20; size *= 2 {
+		cost := float64(transformCost(size)) / float64(size-taps+1)
+		if cost < bestCost {
+			best, bestCost = size, cost
+		}
+	}
+	return best
+}
+
+// transformCost returns the estimated cost of forward and inverse
+// transforms with spectrum multiplication.
+func transformCost(size int) int {
+	return int(fftCost*float64(size)*math.Log2(float64(size))) + 2*size
+}
+
+// bits returns the number of bits required to represent v.
+func bits(v int) int {
+	n := 0
+	for ; v > 0; v >>= 1 {
+		n++
+	}
+	return n
+}
+
+func mustSame[T comparable](a, b T, panicStr string) {
+	if a != b {
+		panic(panicStr)
+	}
+}
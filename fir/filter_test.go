@@ -0,0 +1,97 @@
+package fir_test
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"testing"
+
+	"pipelined.dev/signal"
+	"pipelined.dev/signal/fir"
+	"pipelined.dev/signal/window"
+)
+
+func TestFilter(t *testing.T) {
+	const length = 5000
+	input := signal.Alloc[float64](signal.Allocator{Channels: 2, Length: length, Capacity: length})
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < input.Len(); i++ {
+		input.SetSample(i, r.Float64()*2-1)
+	}
+	// convolve is a direct computation of linear convolution.
+	convolve := func(taps fir.Taps) *signal.Buffer[float64] {
+		result := signal.Alloc[float64](signal.Allocator{Channels: 2, Length: length, Capacity: length})
+		for c := 0; c < 2; c++ {
+			for i := 0; i < length; i++ {
+				var sum float64
+				for k := 0; k <= i && k < len(taps); k++ {
+					sum += taps[k] * input.Sample(input.BufferIndex(c, i-k))
+				}
+				result.SetSample(result.BufferIndex(c, i), sum)
+			}
+		}
+		return result
+	}
+	testOk := func(taps fir.Taps, chunks ...int) func(*testing.T) {
+		return func(t *testing.T) {
+			t.Helper()
+			expected := convolve(taps)
+			f, err := fir.New[float64](2, taps)
+			assertNoError(t, err)
+			assertEqual(t, "taps", f.Taps(), taps)
+			output := signal.Alloc[float64](signal.Allocator{Channels: 2, Length: length, Capacity: length})
+			// process in chunks of different size.
+			for offset, i := 0, 0; offset < length; i++ {
+				end := min(offset+chunks[i%len(chunks)], length)
+				processed := f.Process(input.Slice(offset, end), output.Slice(offset, end))
+				assertEqual(t, "processed", processed, end-offset)
+				offset = end
+			}
+			for i := 0; i < output.Len(); i++ {
+				if math.Abs(output.Sample(i)-expected.Sample(i)) > 1e-9 {
+					t.Fatalf("sample %d: %v expected: %v", i, output.Sample(i), expected.Sample(i))
+				}
+			}
+
+			// in-place processing after reset.
+			f.Reset()
+			inplace := signal.Alloc[float64](signal.Allocator{Channels: 2, Length: length, Capacity: length})
+			inplace.Append(input)
+			inplace = inplace.Slice(length, 2*length)
+			f.Process(inplace, inplace)
+			for i := 0; i < inplace.Len(); i++ {
+				if math.Abs(inplace.Sample(i)-expected.Sample(i)) > 1e-9 {
+					t.Fatalf("in-place sample %d: %v expected: %v", i, inplace.Sample(i), expected.Sample(i))
+				}
+			}
+		}
+	}
+	t.Run("single tap", testOk(fir.Taps{0.5}, length))
+	t.Run("direct", testOk(fir.LowPass(sampleRate, 1000, window.Hann(15)), 100, 1, 7))
+	t.Run("overlap-save", testOk(fir.LowPass(sampleRate, 1000, window.Hann(255)), length))
+	t.Run("overlap-save chunks", testOk(fir.LowPass(sampleRate, 1000, window.Hann(255)), 1000, 3, 700))
+	t.Run("overlap-save long", testOk(fir.LowPass(sampleRate, 1000, window.Hann(2001)), 2500, 1))
+
+	_, err := fir.New[float64](0, fir.Taps{1})
+	assertEqual(t, "channels error", err, fir.ErrChannels)
+	_, err = fir.New[float64](1, nil)
+	assertEqual(t, "taps error", err, fir.ErrTaps)
+
+	f, _ := fir.New[float64](2, fir.Taps{1})
+	assertPanic(t, func() {
+		f.Process(signal.Alloc[float64](signal.Allocator{Channels: 1, Length: 1, Capacity: 1}), input)
+	})
+}
+
+func BenchmarkFilter(b *testing.B) {
+	for _, taps := range []int{16, 64, 256, 1024} {
+		b.Run(fmt.Sprint(taps), func(b *testing.B) {
+			f, _ := fir.New[float64](1, fir.LowPass(sampleRate, 1000, window.Hann(taps)))
+			buf := signal.Alloc[float64](signal.Allocator{Channels: 1, Length: 4096, Capacity: 4096})
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				f.Process(buf, buf)
+			}
+		})
+	}
+}
@@ -0,0 +1,132 @@
+// Package fir provides design and streaming processing of FIR filters for
+// signal buffers.
+//
+// Taps of linear-phase filters are designed either with windowed-sinc
+// method or with Parks-McClellan algorithm, which results in equiripple
+// filters with the minimal number of taps for the specification:
+//
+//	taps := fir.LowPass(44100, 1000, window.Kaiser(101, 8))
+//	taps, err := fir.Equiripple(44100, 101,
+//		fir.Band{Low: 0, High: 1000, Gain: 1, Weight: 1},
+//		fir.Band{Low: 1500, High: 22050, Gain: 0, Weight: 10},
+//	)
+//
+// Filter applies taps to every channel of the Buffer. Short filters are
+// computed in direct form and long filters with FFT-based overlap-save
+// method:
+//
+//	f, err := fir.New[float64](2, taps)
+//	if err != nil {
+//		return err
+//	}
+//	f.Process(buf, buf)
+package fir
+
+import (
+	"math"
+	"math/cmplx"
+
+	"pipelined.dev/signal"
+	"pipelined.dev/signal/window"
+)
+
+// panic messages.
+const (
+	evenTaps = "fir: even number of taps"
+)
+
+// Taps are coefficients of the FIR filter.
+type Taps []float64
+
+// Response returns the complex frequency response of the filter at the
+// frequency.
+func (t Taps) Response(sampleRate, freq signal.Frequency) complex128 {
+	w := 2 * math.Pi * float64(freq) / float64(sampleRate)
+	var r complex128
+	for i, v := range t {
+		r += complex(v, 0) * cmplx.Rect(1, -w*float64(i))
+	}
+	return r
+}
+
+// Gain returns the magnitude response of the filter at the frequency in
+// decibels.
+func (t Taps) Gain(sampleRate, freq signal.Frequency) float64 {
+	return 20 * math.Log10(cmplx.Abs(t.Response(sampleRate, freq)))
+}
+
+// LowPass returns taps of the windowed-sinc low-pass filter with unity
+// gain at zero frequency. Number of taps is defined by the window length.
+func LowPass(sampleRate, cutoff signal.Frequency, w window.Window) Taps {
+	t := sinc(float64(cutoff)/float64(sampleRate), w)
+	var sum float64
+	for _, v := range t {
+		sum += v
+	}
+	for i := range t {
+		t[i] /= sum
+	}
+	return t
+}
+
+// HighPass returns taps of the windowed-sinc high-pass filter. Window
+// length must be odd, otherwise function will panic.
+func HighPass(sampleRate, cutoff signal.Frequency, w window.Window) Taps {
+	mustOdd(len(w))
+	// spectral inversion of the low-pass filter.
+	t := LowPass(sampleRate, cutoff, w)
+	for i := range t {
+		t[i] = -t[i]
+	}
+	t[len(t)/2] += 1
+	return t
+}
+
+// BandPass returns taps of the windowed-sinc band-pass filter with pass
+// band between low and high frequencies.
+func BandPass(sampleRate, low, high signal.Frequency, w window.Window) Taps {
+	t := LowPass(sampleRate, high, w)
+	l := LowPass(sampleRate, low, w)
+	for i := range t {
+		t[i] -= l[i]
+	}
+	return t
+}
+
+// Hilbert returns taps of the windowed Hilbert transformer, which shifts
+// the phase of positive frequencies by -90 degrees. Window length must be
+// odd, otherwise function will panic.
+func Hilbert(w window.Window) Taps {
+	mustOdd(len(w))
+	t := make(Taps, len(w))
+	center := len(w) / 2
+	for i := range t {
+		// ideal response is zero for even offsets.
+		if n := i - center; n%2 != 0 {
+			t[i] = 2 / (math.Pi * float64(n)) * w[i]
+		}
+	}
+	return t
+}
+
+// sinc returns windowed ideal low-pass response with the cut-off
+// frequency normalized by sample rate.
+func sinc(cutoff float64, w window.Window) Taps {
+	t := make(Taps, len(w))
+	center := float64(len(w)-1) / 2
+	for i := range t {
+		x := float64(i) - center
+		if x == 0 {
+			t[i] = 2 * cutoff * w[i]
+			continue
+		}
+		t[i] = math.Sin(2*math.Pi*cutoff*x) / (math.Pi * x) * w[i]
+	}
+	return t
+}
+
+func mustOdd(n int) {
+	if n%2 == 0 {
+		panic(evenTaps)
+	}
+}
@@ -0,0 +1,109 @@
+package fir_test
+
+import (
+	"math"
+	"math/cmplx"
+	"reflect"
+	"testing"
+
+	"pipelined.dev/signal"
+	"pipelined.dev/signal/fir"
+	"pipelined.dev/signal/window"
+)
+
+const sampleRate signal.Frequency = 48000
+
+type gain struct {
+	freq signal.Frequency
+	db   float64
+}
+
+func TestWindowedSinc(t *testing.T) {
+	testOk := func(taps fir.Taps, symmetric bool, expected ...gain) func(*testing.T) {
+		return func(t *testing.T) {
+			t.Helper()
+			assertEqual(t, "length", len(taps), 101)
+			assertLinearPhase(t, taps, symmetric)
+			assertGain(t, taps, 0.1, expected...)
+		}
+	}
+	w := window.Kaiser(101, 8)
+	t.Run("low pass", testOk(fir.LowPass(sampleRate, 4000, w), true,
+		gain{0, 0}, gain{2000, 0}, gain{4000, -6.02}, gain{7000, -80}))
+	t.Run("high pass", testOk(fir.HighPass(sampleRate, 4000, w), true,
+		gain{24000, 0}, gain{8000, 0}, gain{4000, -6.02}, gain{1000, -80}))
+	t.Run("band pass", testOk(fir.BandPass(sampleRate, 4000, 12000, w), true,
+		gain{8000, 0}, gain{4000, -6.02}, gain{12000, -6.02}, gain{0, -80}, gain{24000, -80}))
+	t.Run("hilbert", testOk(fir.Hilbert(window.Blackman(101)), false,
+		gain{2000, 0}, gain{12000, 0}, gain{22000, 0}))
+
+	// hilbert transformer shifts phase by -90 degrees.
+	h := fir.Hilbert(window.Blackman(101))
+	phase := cmplx.Phase(h.Response(sampleRate, 5000) * cmplx.Rect(1, 2*math.Pi*5000/float64(sampleRate)*50))
+	if math.Abs(phase+math.Pi/2) > 1e-9 {
+		t.Fatalf("hilbert phase: %v", phase)
+	}
+
+	assertPanic(t, func() {
+		fir.HighPass(sampleRate, 4000, window.Hann(100))
+	})
+	assertPanic(t, func() {
+		fir.Hilbert(window.Hann(100))
+	})
+}
+
+// assertLinearPhase checks the symmetry of taps.
+func assertLinearPhase(t *testing.T, taps fir.Taps, symmetric bool) {
+	t.Helper()
+	sign := 1.0
+	if !symmetric {
+		sign = -1
+	}
+	for i := range taps {
+		if math.Abs(taps[i]-sign*taps[len(taps)-1-i]) > 1e-12 {
+			t.Fatalf("tap %d: %v mirrored: %v", i, taps[i], taps[len(taps)-1-i])
+		}
+	}
+}
+
+// assertGain checks the gain at frequencies. Expected gain below -60 dB
+// is a maximum gain.
+func assertGain(t *testing.T, taps fir.Taps, tolerance float64, expected ...gain) {
+	t.Helper()
+	for _, e := range expected {
+		g := taps.Gain(sampleRate, e.freq)
+		if e.db <= -60 {
+			if g > e.db {
+				t.Fatalf("gain at %v Hz: %v dB expected below: %v dB", e.freq, g, e.db)
+			}
+			continue
+		}
+		if math.Abs(g-e.db) > tolerance {
+			t.Fatalf("gain at %v Hz: %v dB expected: %v dB", e.freq, g, e.db)
+		}
+	}
+}
+
+func assertEqual(t *testing.T, name string, result, expected interface{}) {
+	t.Helper()
+	if !reflect.DeepEqual(expected, result) {
+		t.Fatalf("%v\nresult: \t%T\t%+v \nexpected: \t%T\t%+v", name, result, result, expected, expected)
+	}
+}
+
+func assertNoError(t *testing.T, err error) {
+	t.Helper()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func assertPanic(t *testing.T, fn func()) {
+	t.Helper()
+	defer func() {
+		if r := recover(); r == nil {
+			t.Fatalf("didn't panic")
+		}
+	}()
+	fn()
+}
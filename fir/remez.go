@@ -0,0 +1,360 @@
+package fir
+
+import (
+	"errors"
+	"math"
+
+	"pipelined.dev/signal"
+)
+
+var (
+	// ErrTaps is returned when number of taps is too small for the
+	// design.
+	ErrTaps = errors.New("fir: invalid number of taps")
+	// ErrBands is returned when bands are empty, overlapping, not
+	// ascending, not in range [0, Nyquist] or have non-positive weight.
+	ErrBands = errors.New("fir: invalid bands")
+	// ErrConvergence is returned when equiripple design fails to converge.
+	ErrConvergence = errors.New("fir: equiripple design didn't converge")
+)
+
+const (
+	// gridDensity is a number of grid points per extremal frequency.
+	gridDensity = 16
+	// maxIterations of Remez exchange algorithm.
+	maxIterations = 100
+)
+
+// Band defines desired response of the equiripple filter in the range
+// [Low, High] of frequencies. Gain is a desired linear amplitude and
+// Weight is a relative importance of the error in the band, it must be
+// positive.
+type Band struct {
+	Low, High signal.Frequency
+	Gain      float64
+	Weight    float64
+}
+
+// Equiripple returns taps of the symmetric linear-phase filter designed
+// with Parks-McClellan algorithm. The weighted error between desired and
+// actual response has equal ripples in all bands. Frequencies between
+// bands are transition regions. Even number of taps results in zero gain
+// at Nyquist frequency.
+func Equiripple(sampleRate signal.Frequency, taps int, bands ...Band) (Taps, error) {
+	return remez(sampleRate, taps, bands, false)
+}
+
+// EquirippleHilbert returns taps of the antisymmetric Hilbert transformer
+// designed with Parks-McClellan algorithm, which shifts the phase of
+// positive frequencies between low and high by -90 degrees. Odd number of
+// taps results in zero gain at zero and Nyquist frequencies, even number
+// only at zero frequency.
+func EquirippleHilbert(sampleRate signal.Frequency, taps int, low, high signal.Frequency) (Taps, error) {
+	return remez(sampleRate, taps, []Band{{Low: low, High: high, Gain: 1, Weight: 1}}, true)
+}
+
+// remez designs linear-phase filter with Remez exchange algorithm. The
+// amplitude response is represented as A(f) = Q(f)P(f), where P is a
+// cosine polynomial of r terms and Q depends on symmetry and parity of
+// taps.
+func remez(sampleRate signal.Frequency, n int, bands []Band, antisymmetric bool) (Taps, error) {
+	if n < 3 {
+		return nil, ErrTaps
+	}
+	if !validBands(sampleRate, bands) {
+		return nil, ErrBands
+	}
+	odd := n%2 == 1
+	var (
+		r int
+		q func(f float64) float64
+	)
+	switch {
+	case !antisymmetric && odd:
+		r = (n + 1) / 2
+		q = func(float64) float64 { return 1 }
+	case !antisymmetric:
+		r = n / 2
+		q = func(f float64) float64 { return math.Cos(math.Pi * f) }
+	case odd:
+		r = (n - 1) / 2
+		q = func(f float64) float64 { return math.Sin(2 * math.Pi * f) }
+	default:
+		r = n / 2
+		q = func(f float64) float64 { return math.Sin(math.Pi * f) }
+	}
+
+	// dense grid of frequencies normalized by sample rate, excluding
+	// zeros of Q.
+	var grid, desired, weight []float64
+	var edges []bool
+	df := 0.5 / float64(gridDensity*r)
+	for _, b := range bands {
+		lo, hi := float64(b.Low)/float64(sampleRate), float64(b.High)/float64(sampleRate)
+		if antisymmetric && lo < df {
+			lo = df
+		}
+		if odd == antisymmetric && hi > 0.5-df {
+			hi = 0.5 - df
+		}
+		if lo > hi {
+			continue
+		}
+		count := max(int(math.Ceil((hi-lo)/df)), 1)
+		for i := 0; i <= count; i++ {
+			f := lo + (hi-lo)*float64(i)/float64(count)
+			grid = append(grid, f)
+			desired = append(desired, b.Gain/q(f))
+			weight = append(weight, b.Weight*q(f))
+			edges = append(edges, i == 0 || i == count)
+		}
+	}
+	if len(grid) < r+1 {
+		return nil, ErrTaps
+	}
+
+	ext := make([]int, r+1)
+	for i := range ext {
+		ext[i] = i * (len(grid) - 1) / r
+	}
+	var (
+		p         func(x float64) float64
+		converged bool
+		e         = make([]float64, len(grid))
+	)
+	for iter := 0; iter < maxIterations && !converged; iter++ {
+		var delta float64
+		p, delta = interpolate(grid, desired, weight, ext)
+		for i, f := range grid {
+			e[i] = weight[i] * (desired[i] - p(math.Cos(2*math.Pi*f)))
+		}
+		next := extremals(e, edges, r+1)
+		if len(next) != r+1 {
+			return nil, ErrConvergence
+		}
+		converged = equal(ext, next)
+		if !converged {
+			// stop when ripples are equal.
+			var maxErr float64
+			for _, i := range next {
+				maxErr = math.Max(maxErr, math.Abs(e[i]))
+			}
+			converged = maxErr-math.Abs(delta) <= 1e-9*maxErr
+		}
+		ext = next
+	}
+	if !converged {
+		return nil, ErrConvergence
+	}
+
+	// cosine coefficients of P are computed from its samples.
+	m := 2 * r
+	a := make([]float64, r)
+	for i := 0; i < m; i++ {
+		w := 2 * math.Pi * float64(i) / float64(m)
+		v := p(math.Cos(w))
+		for k := range a {
+			a[k] += v * math.Cos(float64(k)*w)
+		}
+	}
+	a[0] /= float64(m)
+	for k := 1; k < r; k++ {
+		a[k] *= 2 / float64(m)
+	}
+	return taps(a, n, antisymmetric), nil
+}
+
+// interpolate returns the polynomial in x = cos(2*pi*f) that alternates
+// around desired response at extremal frequencies with equal weighted
+// deviation delta.
+func interpolate(grid, desired, weight []float64, ext []int) (func(x float64) float64, float64) {
+	x := make([]float64, len(ext))
+	for i, idx := range ext {
+		x[i] = math.Cos(2 * math.Pi * grid[idx])
+	}
+	gamma := barycentric(x)
+	var num, den float64
+	sign := 1.0
+	for i, idx := range ext {
+		num += gamma[i] * desired[idx]
+		den += sign * gamma[i] / weight[idx]
+		sign = -sign
+	}
+	delta := num / den
+
+	// polynomial of degree r-1 passes through the first r points.
+	r := len(ext) - 1
+	c := make([]float64, r)
+	sign = 1.0
+	for i := range c {
+		c[i] = desired[ext[i]] - sign*delta/weight[ext[i]]
+		sign = -sign
+	}
+	beta := barycentric(x[:r])
+	return func(v float64) float64 {
+		var num, den float64
+		for i := range c {
+			d := v - x[i]
+			if math.Abs(d) < 1e-14 {
+				return c[i]
+			}
+			num += beta[i] * c[i] / d
+			den += beta[i] / d
+		}
+		return num / den
+	}, delta
+}
+
+// barycentric returns weights of the barycentric Lagrange interpolation.
+// Differences are doubled to avoid underflow.
+func barycentric(x []float64) []float64 {
+	w := make([]float64, len(x))
+	for i := range x {
+		p := 1.0
+		for j := range x {
+			if i != j {
+				p *= 2 * (x[i] - x[j])
+			}
+		}
+		w[i] = 1 / p
+	}
+	return w
+}
+
+// extremals returns indices of alternating local extremums of the error.
+func extremals(e []float64, edges []bool, count int) []int {
+	var candidates []int
+	for i, v := range e {
+		if v == 0 {
+			continue
+		}
+		if edges[i] {
+			candidates = append(candidates, i)
+			continue
+		}
+		if (v > 0 && v >= e[i-1] && v >= e[i+1]) || (v < 0 && v <= e[i-1] && v <= e[i+1]) {
+			candidates = append(candidates, i)
+		}
+	}
+	candidates = alternate(e, candidates)
+	for len(candidates) > count {
+		if len(candidates)-count == 1 {
+			// drop the smaller end.
+			if math.Abs(e[candidates[0]]) < math.Abs(e[candidates[len(candidates)-1]]) {
+				candidates = candidates[1:]
+			} else {
+				candidates = candidates[:len(candidates)-1]
+			}
+			continue
+		}
+		smallest := 0
+		for i, idx := range candidates {
+			if math.Abs(e[idx]) < math.Abs(e[candidates[smallest]]) {
+				smallest = i
+			}
+		}
+		candidates = alternate(e, append(candidates[:smallest], candidates[smallest+1:]...))
+	}
+	return candidates
+}
+
+// alternate merges consecutive extremums of the same sign, keeping the
+// largest one.
+func alternate(e []float64, candidates []int) []int {
+	result := candidates[:0]
+	for _, idx := range candidates {
+		if len(result) > 0 {
+			last := result[len(result)-1]
+			if (e[idx] > 0) == (e[last] > 0) {
+				if math.Abs(e[idx]) > math.Abs(e[last]) {
+					result[len(result)-1] = idx
+				}
+				continue
+			}
+		}
+		result = append(result, idx)
+	}
+	return result
+}
+
+// taps converts cosine coefficients of P into taps of the filter.
+func taps(a []float64, n int, antisymmetric bool) Taps {
+	r := len(a)
+	t := make(Taps, n)
+	half := n / 2
+	switch {
+	case !antisymmetric && n%2 == 1:
+		// A(w) = sum a[k]cos(kw).
+		t[half] = a[0]
+		for k := 1; k < r; k++ {
+			t[half-k] = a[k] / 2
+			t[half+k] = a[k] / 2
+		}
+	case !antisymmetric:
+		// A(w) = cos(w/2)P(w) = sum b[k]cos((k-1/2)w).
+		b := make([]float64, r+1)
+		b[1] += a[0]
+		for k := 1; k < r; k++ {
+			b[k] += a[k] / 2
+			b[k+1] += a[k] / 2
+		}
+		for k := 1; k <= r; k++ {
+			t[half-k] = b[k] / 2
+			t[half-1+k] = b[k] / 2
+		}
+	case n%2 == 1:
+		// A(w) = sin(w)P(w) = sum c[k]sin(kw).
+		c := make([]float64, r+2)
+		c[1] += a[0]
+		for k := 1; k < r; k++ {
+			c[k+1] += a[k] / 2
+			if k >= 2 {
+				c[k-1] -= a[k] / 2
+			}
+		}
+		for k := 1; k <= r; k++ {
+			t[half-k] = -c[k] / 2
+			t[half+k] = c[k] / 2
+		}
+	default:
+		// A(w) = sin(w/2)P(w) = sum d[k]sin((k-1/2)w).
+		d := make([]float64, r+1)
+		d[1] += a[0]
+		for k := 1; k < r; k++ {
+			d[k+1] += a[k] / 2
+			d[k] -= a[k] / 2
+		}
+		for k := 1; k <= r; k++ {
+			t[half-k] = -d[k] / 2
+			t[half-1+k] = d[k] / 2
+		}
+	}
+	return t
+}
+
+func validBands(sampleRate signal.Frequency, bands []Band) bool {
+	if len(bands) == 0 {
+		return false
+	}
+	for i, b := range bands {
+		if b.Low < 0 || b.High <= b.Low || b.High > sampleRate/2 || b.Weight <= 0 {
+			return false
+		}
+		if i > 0 && b.Low <= bands[i-1].High {
+			return false
+		}
+	}
+	return true
+}
+
+func equal(a, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
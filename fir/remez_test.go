@@ -0,0 +1,84 @@
+package fir_test
+
+import (
+	"math"
+	"math/cmplx"
+	"testing"
+
+	"pipelined.dev/signal/fir"
+)
+
+func TestEquiripple(t *testing.T) {
+	// ripple returns the maximum deviation from desired gain in the band.
+	ripple := func(taps fir.Taps, b fir.Band) float64 {
+		var r float64
+		for f := b.Low; f <= b.High; f += (b.High - b.Low) / 200 {
+			r = math.Max(r, math.Abs(cmplx.Abs(taps.Response(sampleRate, f))-b.Gain))
+		}
+		return r
+	}
+	testOk := func(taps int, expected float64, bands ...fir.Band) func(*testing.T) {
+		return func(t *testing.T) {
+			t.Helper()
+			result, err := fir.Equiripple(sampleRate, taps, bands...)
+			assertNoError(t, err)
+			assertEqual(t, "length", len(result), taps)
+			assertLinearPhase(t, result, true)
+			for _, b := range bands {
+				// weighted ripples are equal in all bands.
+				if r := ripple(result, b) * b.Weight; math.Abs(r-expected)/expected > 0.02 {
+					t.Fatalf("band %v-%v Hz weighted ripple: %v expected: %v", b.Low, b.High, r, expected)
+				}
+			}
+		}
+	}
+	t.Run("low pass", testOk(73, 0.0235,
+		fir.Band{Low: 0, High: 4000, Gain: 1, Weight: 1},
+		fir.Band{Low: 5000, High: 24000, Gain: 0, Weight: 1},
+	))
+	t.Run("low pass even", testOk(72, 0.0236,
+		fir.Band{Low: 0, High: 4000, Gain: 1, Weight: 1},
+		fir.Band{Low: 5000, High: 24000, Gain: 0, Weight: 1},
+	))
+	t.Run("high pass", testOk(73, 0.0618,
+		fir.Band{Low: 0, High: 4000, Gain: 0, Weight: 10},
+		fir.Band{Low: 5000, High: 24000, Gain: 1, Weight: 1},
+	))
+	t.Run("band pass", testOk(101, 0.0105,
+		fir.Band{Low: 0, High: 3000, Gain: 0, Weight: 1},
+		fir.Band{Low: 4000, High: 8000, Gain: 1, Weight: 1},
+		fir.Band{Low: 9000, High: 24000, Gain: 0, Weight: 1},
+	))
+
+	t.Run("hilbert", func(t *testing.T) {
+		for _, taps := range []int{63, 64} {
+			result, err := fir.EquirippleHilbert(sampleRate, taps, 1000, 23000)
+			assertNoError(t, err)
+			assertLinearPhase(t, result, false)
+			r := ripple(result, fir.Band{Low: 1000, High: 23000, Gain: 1})
+			if r > 0.01 {
+				t.Fatalf("hilbert %d taps ripple: %v", taps, r)
+			}
+			// phase is shifted by -90 degrees.
+			center := float64(taps-1) / 2
+			phase := result.Response(sampleRate, 5000) * complex(math.Cos(2*math.Pi*5000/float64(sampleRate)*center), math.Sin(2*math.Pi*5000/float64(sampleRate)*center))
+			if math.Abs(real(phase)) > 1e-9 || imag(phase) > 0 {
+				t.Fatalf("hilbert %d taps phase: %v", taps, phase)
+			}
+		}
+	})
+
+	testError := func(taps int, expected error, bands ...fir.Band) func(*testing.T) {
+		return func(t *testing.T) {
+			t.Helper()
+			_, err := fir.Equiripple(sampleRate, taps, bands...)
+			assertEqual(t, "error", err, expected)
+		}
+	}
+	lowPass := fir.Band{Low: 0, High: 4000, Gain: 1, Weight: 1}
+	t.Run("too few taps", testError(2, fir.ErrTaps, lowPass))
+	t.Run("no bands", testError(31, fir.ErrBands))
+	t.Run("above nyquist", testError(31, fir.ErrBands, fir.Band{Low: 0, High: 25000, Gain: 1, Weight: 1}))
+	t.Run("overlapping", testError(31, fir.ErrBands, lowPass, fir.Band{Low: 3000, High: 24000, Weight: 1}))
+	t.Run("zero weight", testError(31, fir.ErrBands, fir.Band{Low: 0, High: 4000, Gain: 1}))
+}
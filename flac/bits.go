@@ -0,0 +1,128 @@
+package flac
+
+import (
+	"io"
+	"math/bits"
+)
+
+// bitReader reads bits from the byte stream and computes CRC of the read
+// bytes. Bytes are read lazily, so the reader never consumes more bytes
+// than required.
+type bitReader struct {
+	r     io.ByteReader
+	cache uint64
+	n     uint
+	crc8  uint8
+	crc16 uint16
+}
+
+func (br *bitReader) fill() error {
+	b, err := br.r.ReadByte()
+	if err != nil {
+		return err
+	}
+	br.crc8 = crc8(br.crc8, b)
+	br.crc16 = crc16(br.crc16, b)
+	br.cache = br.cache<<8 | uint64(b)
+	br.n += 8
+	return nil
+}
+
+// read reads up to 56 bits.
+func (br *bitReader) read(n uint) (uint64, error) {
+	for br.n < n {
+		if err := br.fill(); err != nil {
+			return 0, unexpected(err)
+		}
+	}
+	br.n -= n
+	return br.cache >> br.n & (1<<n - 1), nil
+}
+
+// readSigned reads up to 56 bits two's complement value.
+func (br *bitReader) readSigned(n uint) (int64, error) {
+	v, err := br.read(n)
+	if err != nil || n == 0 {
+		return 0, err
+	}
+	return int64(v<<(64-n)) >> (64 - n), nil
+}
+
+// readUnary reads a number of zero bits before the set bit.
+func (br *bitReader) readUnary() (uint64, error) {
+	var q uint64
+	for {
+		if br.n == 0 {
+			if err := br.fill(); err != nil {
+				return 0, unexpected(err)
+			}
+		}
+		v := br.cache << (64 - br.n)
+		if v == 0 {
+			q += uint64(br.n)
+			br.n = 0
+			continue
+		}
+		z := uint(bits.LeadingZeros64(v))
+		br.n -= z + 1
+		return q + uint64(z), nil
+	}
+}
+
+// align discards bits until the byte boundary.
+func (br *bitReader) align() {
+	br.n -= br.n % 8
+}
+
+// unexpected converts EOF in the middle of the frame to unexpected EOF.
+func unexpected(err error) error {
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
+
+// bitWriter writes bits into the byte slice.
+type bitWriter struct {
+	buf   []byte
+	cache uint64
+	n     uint
+}
+
+// write writes up to 56 low bits of the value.
+func (bw *bitWriter) write(v uint64, n uint) {
+	bw.cache = bw.cache<<n | v&(1<<n-1)
+	bw.n += n
+	for bw.n >= 8 {
+		bw.n -= 8
+		bw.buf = append(bw.buf, byte(bw.cache>>bw.n))
+	}
+}
+
+// writeUnary writes a number of zero bits followed by the set bit.
+func (bw *bitWriter) writeUnary(q uint64) {
+	for ; q >= 32; q -= 32 {
+		bw.write(0, 32)
+	}
+	bw.write(1, uint(q)+1)
+}
+
+// align writes zero bits until the byte boundary.
+func (bw *bitWriter) align() {
+	if bw.n > 0 {
+		bw.write(0, 8-bw.n)
+	}
+}
+
+func (bw *bitWriter) reset() {
+	bw.buf, bw.cache, bw.n = bw.buf[:0], 0, 0
+}
+
+// zigzag maps signed values to unsigned for rice coding.
+func zigzag(v int64) uint64 {
+	return uint64(v<<1 ^ v>>63)
+}
+
+func unzigzag(u uint64) int64 {
+	return int64(u>>1) ^ -int64(u&1)
+}
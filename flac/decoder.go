@@ -0,0 +1,463 @@
+package flac
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/md5"
+	"encoding/binary"
+	"hash"
+	"io"
+
+	"pipelined.dev/signal"
+)
+
+// Decoder decodes samples from the FLAC stream.
+type Decoder struct {
+	StreamInfo
+	br bitReader
+	// samples of the current frame per channel.
+	samples  [][]int64
+	length   int
+	position int
+	md5      hash.Hash
+	md5Buf   []byte
+}
+
+// NewDecoder reads the metadata of the FLAC stream and returns a Decoder
+// positioned at the first frame. All metadata blocks except STREAMINFO
+// are skipped.
+func NewDecoder(r io.Reader) (*Decoder, error) {
+	br, ok := r.(io.ByteReader)
+	if !ok {
+		br = bufio.NewReader(r)
+	}
+	var marker [4]byte
+	if err := readFull(br, marker[:]); err != nil || string(marker[:]) != "fLaC" {
+		return nil, ErrHeader
+	}
+	d := Decoder{
+		br:  bitReader{r: br},
+		md5: md5.New(),
+	}
+	var hasInfo bool
+	for last := false; !last; {
+		var header [4]byte
+		if err := readFull(br, header[:]); err != nil {
+			return nil, ErrHeader
+		}
+		last = header[0]&0x80 != 0
+		size := int(binary.BigEndian.Uint32(header[:]) & 0xffffff)
+		block := make([]byte, size)
+		if err := readFull(br, block); err != nil {
+			return nil, ErrHeader
+		}
+		if header[0]&0x7f == streamInfoType {
+			if size != streamInfoSize {
+				return nil, ErrHeader
+			}
+			d.StreamInfo, hasInfo = parseStreamInfo(block), true
+		}
+	}
+	if !hasInfo {
+		return nil, ErrHeader
+	}
+	if err := d.StreamInfo.validate(); err != nil {
+		return nil, err
+	}
+	d.samples = make([][]int64, d.Channels)
+	return &d, nil
+}
+
+func readFull(r io.ByteReader, b []byte) (err error) {
+	for i := range b {
+		if b[i], err = r.ReadByte(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Read decodes samples into the dst Buffer. The Buffer must have the same
+// number of channels and bit depth as the stream. Up to dst.Length()
+// samples per channel are read. Returns a number of samples read per
+// channel and io.EOF when there are no frames left. If MD5 signature of
+// decoded samples doesn't match, ErrMD5 is returned instead of io.EOF.
+func (d *Decoder) Read(dst *signal.Buffer[int32]) (int, error) {
+	if dst.Channels() != d.Channels || dst.BitDepth() != d.BitDepth {
+		return 0, ErrBuffer
+	}
+	var read int
+	for read < dst.Length() {
+		if d.position == d.length {
+			err := d.decodeFrame()
+			if err == io.EOF && read == 0 {
+				return 0, d.verify()
+			}
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				return read, err
+			}
+		}
+		n := min(dst.Length()-read, d.length-d.position)
+		for c := range d.samples {
+			for i := 0; i < n; i++ {
+				dst.SetSample(dst.BufferIndex(c, read+i), int32(d.samples[c][d.position+i]))
+			}
+		}
+		read += n
+		d.position += n
+	}
+	return read, nil
+}
+
+// verify checks MD5 signature of decoded samples.
+func (d *Decoder) verify() error {
+	var zero [16]byte
+	if d.MD5 == zero {
+		return io.EOF
+	}
+	if !bytes.Equal(d.md5.Sum(nil), d.MD5[:]) {
+		return ErrMD5
+	}
+	return io.EOF
+}
+
+func (d *Decoder) decodeFrame() error {
+	br := &d.br
+	br.crc8, br.crc16 = 0, 0
+	// first byte of sync code determines the end of the stream.
+	if err := br.fill(); err != nil {
+		return err
+	}
+	br.n -= 8
+	if byte(br.cache) != 0xff {
+		return ErrFrame
+	}
+	h, err := br.read(8)
+	if err != nil {
+		return err
+	}
+	if h&0xfe != 0xf8 {
+		return ErrFrame
+	}
+	h, err = br.read(16)
+	if err != nil {
+		return err
+	}
+	blockSizeCode, rateCode := h>>12, h>>8&0xf
+	assignment, sizeCode := h>>4&0xf, h>>1&0x7
+	if h&1 != 0 || rateCode == 0xf || sizeCode == 0x3 || assignment > midSide {
+		return ErrFrame
+	}
+	// frame or sample number is not used.
+	if err := d.skipNumber(); err != nil {
+		return err
+	}
+
+	var blockSize int
+	switch {
+	case blockSizeCode == 0x1:
+		blockSize = 192
+	case blockSizeCode >= 0x2 && blockSizeCode <= 0x5:
+		blockSize = 576 << (blockSizeCode - 2)
+	case blockSizeCode == 0x6:
+		v, err := br.read(8)
+		if err != nil {
+			return err
+		}
+		blockSize = int(v) + 1
+	case blockSizeCode == 0x7:
+		v, err := br.read(16)
+		if err != nil {
+			return err
+		}
+		blockSize = int(v) + 1
+	case blockSizeCode >= 0x8:
+		blockSize = 256 << (blockSizeCode - 8)
+	default:
+		return ErrFrame
+	}
+	switch rateCode {
+	case 0xc:
+		_, err = br.read(8)
+	case 0xd, 0xe:
+		_, err = br.read(16)
+	}
+	if err != nil {
+		return err
+	}
+	bd := d.BitDepth
+	if sizeCode != 0 {
+		bd = bitDepths[sizeCode]
+	}
+	crc := br.crc8
+	if v, err := br.read(8); err != nil {
+		return err
+	} else if uint8(v) != crc {
+		return ErrChecksum
+	}
+	// samples must fit the bit depth of the buffer.
+	if bd != d.BitDepth {
+		return ErrFrame
+	}
+
+	channels := int(assignment) + 1
+	if assignment >= leftSide {
+		channels = 2
+	}
+	if channels != d.Channels {
+		return ErrFrame
+	}
+	for c := range d.samples {
+		if cap(d.samples[c]) < blockSize {
+			d.samples[c] = make([]int64, blockSize)
+		}
+		d.samples[c] = d.samples[c][:blockSize]
+		subframeBits := uint(bd)
+		switch {
+		case assignment == leftSide && c == 1,
+			assignment == sideRight && c == 0,
+			assignment == midSide && c == 1:
+			subframeBits++
+		}
+		if err := d.decodeSubframe(d.samples[c], subframeBits); err != nil {
+			return err
+		}
+	}
+	br.align()
+	crc16 := br.crc16
+	if v, err := br.read(16); err != nil {
+		return err
+	} else if uint16(v) != crc16 {
+		return ErrChecksum
+	}
+
+	decorrelate(d.samples, assignment)
+	d.md5Buf = appendSamples(d.md5Buf[:0], d.samples, blockSize, bd)
+	d.md5.Write(d.md5Buf)
+	d.length, d.position = blockSize, 0
+	return nil
+}
+
+// skipNumber skips UTF-8 coded frame or sample number.
+func (d *Decoder) skipNumber() error {
+	v, err := d.br.read(8)
+	if err != nil {
+		return err
+	}
+	var n int
+	for b := byte(v); b&0x80 != 0; b <<= 1 {
+		n++
+	}
+	if n == 1 || n > 7 {
+		return ErrFrame
+	}
+	for i := 1; i < n; i++ {
+		if v, err = d.br.read(8); err != nil {
+			return err
+		}
+		if v&0xc0 != 0x80 {
+			return ErrFrame
+		}
+	}
+	return nil
+}
+
+func (d *Decoder) decodeSubframe(samples []int64, bd uint) error {
+	br := &d.br
+	h, err := br.read(8)
+	if err != nil {
+		return err
+	}
+	if h&0x80 != 0 {
+		return ErrFrame
+	}
+	var wasted uint
+	if h&1 != 0 {
+		w, err := br.readUnary()
+		if err != nil {
+			return err
+		}
+		wasted = uint(w) + 1
+		if wasted >= bd {
+			return ErrFrame
+		}
+		bd -= wasted
+	}
+
+	switch t := h >> 1 & 0x3f; {
+	case t == 0x0:
+		v, err := br.readSigned(bd)
+		if err != nil {
+			return err
+		}
+		for i := range samples {
+			samples[i] = v
+		}
+	case t == 0x1:
+		for i := range samples {
+			if samples[i], err = br.readSigned(bd); err != nil {
+				return err
+			}
+		}
+	case t >= 0x8 && t <= 0xc:
+		order := int(t & 0x7)
+		if err := d.decodePredicted(samples, bd, fixedCoefficients[order], 0); err != nil {
+			return err
+		}
+	case t >= 0x20:
+		order := int(t&0x1f) + 1
+		if len(samples) < order {
+			return ErrFrame
+		}
+		// warm-up samples precede coefficients.
+		for i := 0; i < order; i++ {
+			if samples[i], err = br.readSigned(bd); err != nil {
+				return err
+			}
+		}
+		precision, err := br.read(4)
+		if err != nil {
+			return err
+		}
+		if precision == 0xf {
+			return ErrFrame
+		}
+		shift, err := br.readSigned(5)
+		if err != nil {
+			return err
+		}
+		if shift < 0 {
+			return ErrFrame
+		}
+		var buf [32]int64
+		coefficients := buf[:order]
+		for i := range coefficients {
+			if coefficients[i], err = br.readSigned(uint(precision) + 1); err != nil {
+				return err
+			}
+		}
+		if err := d.decodeResidual(samples, order); err != nil {
+			return err
+		}
+		restore(samples, coefficients, uint(shift))
+	default:
+		return ErrFrame
+	}
+	if wasted > 0 {
+		for i := range samples {
+			samples[i] <<= wasted
+		}
+	}
+	return nil
+}
+
+// decodePredicted decodes warm-up samples and residual and restores the
+// signal with provided coefficients.
+func (d *Decoder) decodePredicted(samples []int64, bd uint, coefficients []int64, shift uint) (err error) {
+	order := len(coefficients)
+	if len(samples) < order {
+		return ErrFrame
+	}
+	for i := 0; i < order; i++ {
+		if samples[i], err = d.br.readSigned(bd); err != nil {
+			return err
+		}
+	}
+	if err := d.decodeResidual(samples, order); err != nil {
+		return err
+	}
+	restore(samples, coefficients, shift)
+	return nil
+}
+
+// decodeResidual decodes rice-coded residual into samples after warm-up.
+func (d *Decoder) decodeResidual(samples []int64, order int) error {
+	br := &d.br
+	method, err := br.read(2)
+	if err != nil {
+		return err
+	}
+	if method > 1 {
+		return ErrFrame
+	}
+	paramBits, escape := uint(4), uint64(0xf)
+	if method == 1 {
+		paramBits, escape = 5, 0x1f
+	}
+	partitionOrder, err := br.read(4)
+	if err != nil {
+		return err
+	}
+	partitions := 1 << partitionOrder
+	if len(samples)%partitions != 0 || len(samples)>>partitionOrder < order {
+		return ErrFrame
+	}
+	i := order
+	for p := 0; p < partitions; p++ {
+		end := (p + 1) * len(samples) >> partitionOrder
+		param, err := br.read(paramBits)
+		if err != nil {
+			return err
+		}
+		if param == escape {
+			n, err := br.read(5)
+			if err != nil {
+				return err
+			}
+			for ; i < end; i++ {
+				if samples[i], err = br.readSigned(uint(n)); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+		k := uint(param)
+		for ; i < end; i++ {
+			q, err := br.readUnary()
+			if err != nil {
+				return err
+			}
+			low, err := br.read(k)
+			if err != nil {
+				return err
+			}
+			samples[i] = unzigzag(q<<k | low)
+		}
+	}
+	return nil
+}
+
+// restore replaces residual with samples predicted by coefficients.
+func restore(samples, coefficients []int64, shift uint) {
+	order := len(coefficients)
+	for i := order; i < len(samples); i++ {
+		var prediction int64
+		for j, c := range coefficients {
+			prediction += c * samples[i-1-j]
+		}
+		samples[i] += prediction >> shift
+	}
+}
+
+// decorrelate restores left and right channels of stereo frame.
+func decorrelate(samples [][]int64, assignment uint64) {
+	switch assignment {
+	case leftSide:
+		for i, side := range samples[1] {
+			samples[1][i] = samples[0][i] - side
+		}
+	case sideRight:
+		for i, side := range samples[0] {
+			samples[0][i] = side + samples[1][i]
+		}
+	case midSide:
+		for i, side := range samples[1] {
+			mid := samples[0][i]<<1 | side&1
+			samples[0][i] = (mid + side) >> 1
+			samples[1][i] = (mid - side) >> 1
+		}
+	}
+}
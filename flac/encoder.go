@@ -0,0 +1,705 @@
+package flac
+
+import (
+	"crypto/md5"
+	"hash"
+	"io"
+	"math"
+	"math/bits"
+
+	"pipelined.dev/signal"
+)
+
+// Level is a compression level of the Encoder. Higher levels produce
+// smaller streams at the cost of encoding time.
+type Level int
+
+const (
+	// LevelFastest uses fixed predictors only.
+	LevelFastest Level = 0
+	// LevelDefault is a balance between speed and compression.
+	LevelDefault Level = 5
+	// LevelBest searches for the best linear predictor.
+	LevelBest Level = 8
+)
+
+// levelParams are the encoding parameters for compression level.
+type levelParams struct {
+	blockSize    int
+	stereo       bool
+	maxLPC       int
+	maxPartition uint
+	exhaustive   bool
+}
+
+var levels = [...]levelParams{
+	{blockSize: 1152, stereo: false, maxLPC: 0, maxPartition: 3},
+	{blockSize: 1152, stereo: true, maxLPC: 0, maxPartition: 3},
+	{blockSize: 1152, stereo: true, maxLPC: 0, maxPartition: 3, exhaustive: true},
+	{blockSize: 4096, stereo: false, maxLPC: 6, maxPartition: 4},
+	{blockSize: 4096, stereo: true, maxLPC: 8, maxPartition: 4},
+	{blockSize: 4096, stereo: true, maxLPC: 8, maxPartition: 5},
+	{blockSize: 4096, stereo: true, maxLPC: 8, maxPartition: 6},
+	{blockSize: 4096, stereo: true, maxLPC: 12, maxPartition: 6, exhaustive: true},
+	{blockSize: 4096, stereo: true, maxLPC: 12, maxPartition: 8, exhaustive: true},
+}
+
+// Encoder encodes samples into the FLAC stream. Samples are buffered
+// until the block is complete, Close must be called to encode the last
+// block.
+type Encoder struct {
+	StreamInfo
+	w      io.Writer
+	params levelParams
+	// infoOffset is the position of STREAMINFO in the stream, negative
+	// if the writer doesn't implement io.Seeker.
+	infoOffset int64
+	// samples of the current block per channel.
+	samples [][]int64
+	// samples of the current block trimmed to its length.
+	block       [][]int64
+	length      int
+	frameNumber uint64
+	bw          bitWriter
+	md5         hash.Hash
+	md5Buf      []byte
+	// scratch buffers for subframes analysis.
+	subframes []subframe
+	stereo    [2][]int64
+	residual  []int64
+	window    []float64
+	signal    []float64
+}
+
+// NewEncoder writes the metadata of the FLAC stream and returns an
+// Encoder. If the writer implements io.Seeker, STREAMINFO is updated on
+// Close with the number of samples, frame sizes and MD5 signature.
+func NewEncoder(w io.Writer, channels int, sampleRate signal.Frequency, bd signal.BitDepth, level Level) (*Encoder, error) {
+	if level < LevelFastest || level > LevelBest {
+		return nil, ErrFormat
+	}
+	params := levels[level]
+	info := StreamInfo{
+		MinBlockSize: params.blockSize,
+		MaxBlockSize: params.blockSize,
+		SampleRate:   sampleRate,
+		Channels:     channels,
+		BitDepth:     bd,
+	}
+	if err := info.validate(); err != nil {
+		return nil, err
+	}
+	infoOffset := int64(-1)
+	if s, ok := w.(io.Seeker); ok {
+		offset, err := s.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return nil, err
+		}
+		infoOffset = offset + 8
+	}
+	header := append([]byte("fLaC"), 0x80|streamInfoType, 0, 0, streamInfoSize)
+	if _, err := w.Write(append(header, info.bytes()...)); err != nil {
+		return nil, err
+	}
+
+	e := Encoder{
+		StreamInfo: info,
+		w:          w,
+		params:     params,
+		infoOffset: infoOffset,
+		samples:    make([][]int64, channels),
+		block:      make([][]int64, channels),
+		md5:        md5.New(),
+		subframes:  make([]subframe, max(channels, 4)),
+		residual:   make([]int64, params.blockSize),
+	}
+	for c := range e.samples {
+		e.samples[c] = make([]int64, params.blockSize)
+	}
+	if channels == 2 && params.stereo {
+		e.stereo = [2][]int64{
+			make([]int64, params.blockSize),
+			make([]int64, params.blockSize),
+		}
+	}
+	return &e, nil
+}
+
+// Write encodes samples of the src Buffer. The Buffer must have the same
+// number of channels and bit depth as the stream.
+func (e *Encoder) Write(src *signal.Buffer[int32]) error {
+	if src.Channels() != e.Channels || src.BitDepth() != e.BitDepth {
+		return ErrBuffer
+	}
+	for written := 0; written < src.Length(); {
+		n := min(src.Length()-written, e.params.blockSize-e.length)
+		for c := range e.samples {
+			for i := 0; i < n; i++ {
+				e.samples[c][e.length+i] = int64(src.Sample(src.BufferIndex(c, written+i)))
+			}
+		}
+		written += n
+		e.length += n
+		if e.length == e.params.blockSize {
+			if err := e.encodeFrame(); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Close encodes the last block and updates STREAMINFO if possible. It
+// doesn't close the underlying writer.
+func (e *Encoder) Close() error {
+	if e.length > 0 {
+		if err := e.encodeFrame(); err != nil {
+			return err
+		}
+	}
+	copy(e.MD5[:], e.md5.Sum(nil))
+	if e.infoOffset < 0 {
+		return nil
+	}
+	s := e.w.(io.WriteSeeker)
+	end, err := s.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return err
+	}
+	if _, err := s.Seek(e.infoOffset, io.SeekStart); err != nil {
+		return err
+	}
+	if _, err := s.Write(e.StreamInfo.bytes()); err != nil {
+		return err
+	}
+	_, err = s.Seek(end, io.SeekStart)
+	return err
+}
+
+func (e *Encoder) encodeFrame() error {
+	n := e.length
+	samples := e.block
+	for c := range samples {
+		samples[c] = e.samples[c][:n]
+	}
+	bd := uint(e.BitDepth)
+
+	// choose channel assignment.
+	assignment := uint64(e.Channels - 1)
+	// indices of subframes to write, channels are independent by
+	// default. subframes is a view of order, so assigning order selects
+	// subframes of stereo decorrelation.
+	var order [8]int
+	subframes := order[:e.Channels]
+	for c := range subframes {
+		subframes[c] = c
+	}
+	for c := range samples {
+		e.analyze(&e.subframes[c], samples[c], bd)
+	}
+	if e.stereo[0] != nil {
+		mid, side := e.stereo[0][:n], e.stereo[1][:n]
+		for i := range mid {
+			mid[i] = (samples[0][i] + samples[1][i]) >> 1
+			side[i] = samples[0][i] - samples[1][i]
+		}
+		e.analyze(&e.subframes[2], mid, bd)
+		e.analyze(&e.subframes[3], side, bd+1)
+		left, right, m, s := e.subframes[0].bits, e.subframes[1].bits, e.subframes[2].bits, e.subframes[3].bits
+		best := left + right
+		if v := left + s; v < best {
+			best, assignment, order = v, leftSide, [8]int{0, 3}
+		}
+		if v := s + right; v < best {
+			best, assignment, order = v, sideRight, [8]int{3, 1}
+		}
+		if v := m + s; v < best {
+			assignment, order = midSide, [8]int{2, 3}
+		}
+	}
+
+	bw := &e.bw
+	bw.reset()
+	bw.write(0xfff8, 16)
+	blockSizeCode, blockSizeBits := blockSizeCode(n)
+	rateCode, rateBits, rate := sampleRateCode(e.SampleRate)
+	bw.write(blockSizeCode, 4)
+	bw.write(rateCode, 4)
+	bw.write(assignment, 4)
+	bw.write(bitDepthCode(e.BitDepth), 3)
+	bw.write(0, 1)
+	writeNumber(bw, e.frameNumber)
+	bw.write(uint64(n-1), blockSizeBits)
+	bw.write(rate, rateBits)
+	var crc uint8
+	for _, b := range bw.buf {
+		crc = crc8(crc, b)
+	}
+	bw.write(uint64(crc), 8)
+
+	for _, i := range subframes {
+		writeSubframe(bw, &e.subframes[i])
+	}
+	bw.align()
+	var crc16v uint16
+	for _, b := range bw.buf {
+		crc16v = crc16(crc16v, b)
+	}
+	bw.write(uint64(crc16v), 16)
+	if _, err := e.w.Write(bw.buf); err != nil {
+		return err
+	}
+
+	if size := len(bw.buf); e.MinFrameSize == 0 || size < e.MinFrameSize {
+		e.MinFrameSize = size
+	}
+	e.MaxFrameSize = max(e.MaxFrameSize, len(bw.buf))
+	e.md5Buf = appendSamples(e.md5Buf[:0], samples, n, e.BitDepth)
+	e.md5.Write(e.md5Buf)
+	e.TotalSamples += uint64(n)
+	e.frameNumber++
+	e.length = 0
+	return nil
+}
+
+func blockSizeCode(n int) (code uint64, extraBits uint) {
+	switch {
+	case n == 192:
+		return 0x1, 0
+	case n == 576, n == 1152, n == 2304, n == 4608:
+		return 0x2 + uint64(bits.TrailingZeros(uint(n/576))), 0
+	case n >= 256 && n <= 32768 && n&(n-1) == 0:
+		return 0x8 + uint64(bits.TrailingZeros(uint(n/256))), 0
+	case n <= 256:
+		return 0x6, 8
+	default:
+		return 0x7, 16
+	}
+}
+
+func sampleRateCode(rate signal.Frequency) (code uint64, extraBits uint, extra uint64) {
+	for i, r := range sampleRates {
+		if r != 0 && r == rate {
+			return uint64(i), 0, 0
+		}
+	}
+	v := uint64(rate)
+	switch {
+	case signal.Frequency(v) != rate:
+		return 0x0, 0, 0
+	case v%1000 == 0 && v/1000 <= 0xff:
+		return 0xc, 8, v / 1000
+	case v <= 0xffff:
+		return 0xd, 16, v
+	case v%10 == 0 && v/10 <= 0xffff:
+		return 0xe, 16, v / 10
+	}
+	return 0x0, 0, 0
+}
+
+func bitDepthCode(bd signal.BitDepth) uint64 {
+	for i, v := range bitDepths {
+		if v != 0 && v == bd {
+			return uint64(i)
+		}
+	}
+	return 0
+}
+
+// writeNumber writes UTF-8 coded frame number.
+func writeNumber(bw *bitWriter, v uint64) {
+	if v < 0x80 {
+		bw.write(v, 8)
+		return
+	}
+	n := 2
+	for v >= 1<<(5*n+1) {
+		n++
+	}
+	bw.write(0xff<<(8-n)&0xff|v>>(6*(n-1)), 8)
+	for i := n - 2; i >= 0; i-- {
+		bw.write(0x80|v>>(6*i)&0x3f, 8)
+	}
+}
+
+// subframe is the result of subframe analysis.
+type subframe struct {
+	kind   int
+	order  int
+	wasted uint
+	// bd is a bit depth of samples without wasted bits.
+	bd           uint
+	precision    uint
+	shift        uint
+	coefficients [32]int64
+	samples      []int64
+	residual     []int64
+	rice
+	bits int
+}
+
+// rice defines the partitioning of rice coded residual.
+type rice struct {
+	method         uint64
+	partitionOrder uint
+	params         [256]uint8
+}
+
+// analyze finds the smallest encoding of the samples.
+func (e *Encoder) analyze(sf *subframe, samples []int64, bd uint) {
+	n := len(samples)
+	if cap(sf.samples) < n {
+		sf.samples = make([]int64, n)
+		sf.residual = make([]int64, n)
+	}
+	sf.samples, sf.residual = sf.samples[:n], sf.residual[:n]
+	var or int64
+	for _, s := range samples {
+		or |= s
+	}
+	sf.wasted = 0
+	if or != 0 {
+		sf.wasted = min(uint(bits.TrailingZeros64(uint64(or))), bd-1)
+	}
+	sf.bd = bd - sf.wasted
+	constant := true
+	for i, s := range samples {
+		sf.samples[i] = s >> sf.wasted
+		constant = constant && s == samples[0]
+	}
+	header := 8 + int(sf.wasted)
+	if constant {
+		sf.kind, sf.wasted, sf.bd = subframeConstant, 0, bd
+		sf.samples[0] = samples[0]
+		sf.bits = 8 + int(bd)
+		return
+	}
+	sf.kind, sf.bits = subframeVerbatim, header+n*int(sf.bd)
+
+	var r rice
+	residual := e.residual[:n]
+	for order := 0; order <= 4 && order < n; order++ {
+		if !predict(residual, sf.samples, fixedCoefficients[order], 0) {
+			continue
+		}
+		v := header + order*int(sf.bd) + e.riceBits(&r, residual, order)
+		if v < sf.bits {
+			sf.kind, sf.order, sf.bits, sf.rice = subframeFixed, order, v, r
+			residual, sf.residual = sf.residual, residual
+		}
+	}
+
+	maxOrder := min(e.params.maxLPC, n-1)
+	if maxOrder == 0 {
+		e.residual = residual
+		return
+	}
+	lp := e.lpc(sf.samples, maxOrder)
+	if lp == nil {
+		e.residual = residual
+		return
+	}
+	precision := lpcPrecision(sf.bd, n)
+	minOrder := maxOrder
+	if e.params.exhaustive {
+		minOrder = 1
+	}
+	var coefficients [32]int64
+	for order := minOrder; order <= len(lp); order++ {
+		shift, ok := quantize(coefficients[:order], lp[order-1][:order], precision)
+		if !ok || !predict(residual, sf.samples, coefficients[:order], shift) {
+			continue
+		}
+		v := header + order*int(sf.bd) + 9 + order*int(precision) + e.riceBits(&r, residual, order)
+		if v < sf.bits {
+			sf.kind, sf.order, sf.bits, sf.rice = subframeLPC, order, v, r
+			sf.precision, sf.shift, sf.coefficients = precision, shift, coefficients
+			residual, sf.residual = sf.residual, residual
+		}
+	}
+	e.residual = residual
+}
+
+// predict computes the residual of prediction. Returns false if the
+// residual doesn't fit into 32 bits.
+func predict(residual, samples, coefficients []int64, shift uint) bool {
+	order := len(coefficients)
+	copy(residual[:order], samples[:order])
+	for i := order; i < len(samples); i++ {
+		var prediction int64
+		for j, c := range coefficients {
+			prediction += c * samples[i-1-j]
+		}
+		r := samples[i] - prediction>>shift
+		if r > math.MaxInt32 || r < math.MinInt32 {
+			return false
+		}
+		residual[i] = r
+	}
+	return true
+}
+
+// riceBits finds the partitioning of the residual and returns estimated
+// number of bits to encode it.
+func (e *Encoder) riceBits(r *rice, residual []int64, order int) int {
+	n := len(residual)
+	maxPartition := e.params.maxPartition
+	for maxPartition > 0 && (n%(1<<maxPartition) != 0 || n>>maxPartition <= order) {
+		maxPartition--
+	}
+	var sums [256]uint64
+	partitions := 1 << maxPartition
+	for p := 0; p < partitions; p++ {
+		start, end := max(p*n/partitions, order), (p+1)*n/partitions
+		for _, v := range residual[start:end] {
+			sums[p] += zigzag(v)
+		}
+	}
+
+	bestBits := math.MaxInt
+	for po := maxPartition; ; po-- {
+		partitions := 1 << po
+		var (
+			total int
+			c     rice
+		)
+		for p := 0; p < partitions; p++ {
+			count := n >> po
+			if p == 0 {
+				count -= order
+			}
+			k, bits := riceParam(sums[p], count)
+			c.params[p] = uint8(k)
+			total += bits
+			if k > 14 {
+				c.method = 1
+			}
+		}
+		c.partitionOrder = po
+		total += 6 + partitions*int(4+c.method)
+		if total < bestBits {
+			bestBits, *r = total, c
+		}
+		if po == 0 {
+			break
+		}
+		// merge sums for the lower partition order.
+		for p := 0; p < partitions/2; p++ {
+			sums[p] = sums[2*p] + sums[2*p+1]
+		}
+	}
+	return bestBits
+}
+
+// riceParam returns the rice parameter for the partition and estimated
+// number of bits.
+func riceParam(sum uint64, count int) (uint, int) {
+	if count == 0 || sum == 0 {
+		return 0, count
+	}
+	k := uint(0)
+	if mean := sum / uint64(count); mean > 0 {
+		k = min(uint(bits.Len64(mean))-1, 30)
+	}
+	best, bestBits := k, math.MaxInt
+	for _, v := range [...]uint{k, k + 1} {
+		if v > 30 {
+			continue
+		}
+		if b := count*int(v+1) + int(sum>>v); b < bestBits {
+			best, bestBits = v, b
+		}
+	}
+	return best, bestBits
+}
+
+// lpc computes linear predictor coefficients for orders up to maxOrder.
+// Returns nil if the signal is silent.
+func (e *Encoder) lpc(samples []int64, maxOrder int) [][]float64 {
+	n := len(samples)
+	if len(e.window) != n {
+		e.window = tukey(n, 0.5)
+		e.signal = make([]float64, n)
+	}
+	for i, s := range samples {
+		e.signal[i] = float64(s) * e.window[i]
+	}
+	autoc := make([]float64, maxOrder+1)
+	for lag := range autoc {
+		for i := lag; i < n; i++ {
+			autoc[lag] += e.signal[i] * e.signal[i-lag]
+		}
+	}
+	if autoc[0] == 0 {
+		return nil
+	}
+	return levinson(autoc, maxOrder)
+}
+
+// levinson computes predictor coefficients with Levinson-Durbin
+// recursion. Coefficients of order i are stored at index i-1.
+func levinson(autoc []float64, maxOrder int) [][]float64 {
+	lp := make([][]float64, maxOrder)
+	lpc := make([]float64, maxOrder)
+	err := autoc[0]
+	for i := 0; i < maxOrder; i++ {
+		r := -autoc[i+1]
+		for j := 0; j < i; j++ {
+			r -= lpc[j] * autoc[i-j]
+		}
+		r /= err
+		lpc[i] = r
+		var j int
+		for j = 0; j < i>>1; j++ {
+			tmp := lpc[j]
+			lpc[j] += r * lpc[i-1-j]
+			lpc[i-1-j] += r * tmp
+		}
+		if i&1 != 0 {
+			lpc[j] += lpc[j] * r
+		}
+		err *= 1 - r*r
+		lp[i] = make([]float64, i+1)
+		for j := 0; j <= i; j++ {
+			lp[i][j] = -lpc[j]
+		}
+		if err <= 0 {
+			return lp[:i+1]
+		}
+	}
+	return lp
+}
+
+// quantize converts predictor coefficients into integers of provided
+// precision. Returns the shift of quantized coefficients.
+func quantize(dst []int64, lp []float64, precision uint) (uint, bool) {
+	if len(dst) > len(lp) {
+		return 0, false
+	}
+	var cmax float64
+	for _, c := range lp {
+		cmax = math.Max(cmax, math.Abs(c))
+	}
+	if cmax <= 0 || math.IsInf(cmax, 0) || math.IsNaN(cmax) {
+		return 0, false
+	}
+	_, log2cmax := math.Frexp(cmax)
+	p := int(precision) - 1
+	qmax := int64(1)<<p - 1
+	shift := p - log2cmax
+	if shift > 15 {
+		shift = 15
+	}
+	if shift < 0 {
+		return 0, false
+	}
+	var err float64
+	for i, c := range lp {
+		err += c * float64(int64(1)<<shift)
+		q := int64(math.Round(err))
+		q = max(min(q, qmax), -qmax-1)
+		err -= float64(q)
+		dst[i] = q
+	}
+	return uint(shift), true
+}
+
+func lpcPrecision(bd uint, n int) uint {
+	var p uint
+	switch {
+	case n <= 192:
+		p = 7
+	case n <= 384:
+		p = 8
+	case n <= 576:
+		p = 9
+	case n <= 1152:
+		p = 10
+	case n <= 2304:
+		p = 11
+	case n <= 4608:
+		p = 12
+	default:
+		p = 13
+	}
+	if bd > 16 {
+		p += 2
+	}
+	return min(p, 15)
+}
+
+// tukey returns Tukey window of length n.
+func tukey(n int, alpha float64) []float64 {
+	w := make([]float64, n)
+	taper := int(alpha * float64(n-1) / 2)
+	for i := range w {
+		switch {
+		case i < taper:
+			w[i] = 0.5 * (1 - math.Cos(math.Pi*float64(i)/float64(taper)))
+		case i >= n-taper:
+			w[i] = 0.5 * (1 - math.Cos(math.Pi*float64(n-1-i)/float64(taper)))
+		default:
+			w[i] = 1
+		}
+	}
+	return w
+}
+
+func writeSubframe(bw *bitWriter, sf *subframe) {
+	bw.write(0, 1)
+	switch sf.kind {
+	case subframeConstant:
+		bw.write(0x0, 6)
+	case subframeVerbatim:
+		bw.write(0x1, 6)
+	case subframeFixed:
+		bw.write(0x8|uint64(sf.order), 6)
+	case subframeLPC:
+		bw.write(0x20|uint64(sf.order-1), 6)
+	}
+	if sf.wasted > 0 {
+		bw.write(1, 1)
+		bw.writeUnary(uint64(sf.wasted - 1))
+	} else {
+		bw.write(0, 1)
+	}
+	switch sf.kind {
+	case subframeConstant:
+		bw.write(uint64(sf.samples[0]), sf.bd)
+		return
+	case subframeVerbatim:
+		for _, s := range sf.samples {
+			bw.write(uint64(s), sf.bd)
+		}
+		return
+	}
+	for _, s := range sf.samples[:sf.order] {
+		bw.write(uint64(s), sf.bd)
+	}
+	if sf.kind == subframeLPC {
+		bw.write(uint64(sf.precision-1), 4)
+		bw.write(uint64(sf.shift), 5)
+		for _, c := range sf.coefficients[:sf.order] {
+			bw.write(uint64(c), sf.precision)
+		}
+	}
+	writeResidual(bw, sf.residual, sf.order, &sf.rice)
+}
+
+func writeResidual(bw *bitWriter, residual []int64, order int, r *rice) {
+	bw.write(r.method, 2)
+	bw.write(uint64(r.partitionOrder), 4)
+	paramBits := uint(4 + r.method)
+	partitions := 1 << r.partitionOrder
+	n := len(residual)
+	for p := 0; p < partitions; p++ {
+		k := uint(r.params[p])
+		bw.write(uint64(k), paramBits)
+		start, end := max(p*n/partitions, order), (p+1)*n/partitions
+		for _, v := range residual[start:end] {
+			u := zigzag(v)
+			bw.writeUnary(u >> k)
+			bw.write(u, k)
+		}
+	}
+}
@@ -0,0 +1,220 @@
+// Package flac provides native FLAC decoder and encoder built on top of
+// signal buffers.
+//
+// Samples are decoded into and encoded from int32 buffers, which bit
+// depth matches the bit depth of the stream:
+//
+//	d, err := flac.NewDecoder(file)
+//	if err != nil {
+//		return err
+//	}
+//	buf := signal.AllocBitDepth[int32](d.Allocator(4096), d.BitDepth)
+//	for {
+//		n, err := d.Read(buf)
+//		if err == io.EOF {
+//			break
+//		}
+//		// process buf.Slice(0, n)
+//	}
+//
+// MD5 signature of decoded samples is verified when the end of the stream
+// is reached.
+package flac
+
+import (
+	"encoding/binary"
+	"errors"
+
+	"pipelined.dev/signal"
+)
+
+var (
+	// ErrHeader is returned when the stream is not a valid FLAC stream.
+	ErrHeader = errors.New("flac: invalid header")
+	// ErrFrame is returned when the frame cannot be decoded.
+	ErrFrame = errors.New("flac: invalid frame")
+	// ErrChecksum is returned when CRC of the frame doesn't match.
+	ErrChecksum = errors.New("flac: frame checksum mismatch")
+	// ErrMD5 is returned when MD5 signature of decoded samples doesn't
+	// match the signature from STREAMINFO.
+	ErrMD5 = errors.New("flac: md5 signature mismatch")
+	// ErrFormat is returned when the stream parameters are not
+	// supported.
+	ErrFormat = errors.New("flac: unsupported format")
+	// ErrBuffer is returned when the number of channels or bit depth of
+	// the Buffer doesn't match the stream.
+	ErrBuffer = errors.New("flac: buffer doesn't match the stream")
+)
+
+const (
+	streamInfoType = 0
+	streamInfoSize = 34
+)
+
+// StreamInfo is a STREAMINFO metadata block that describes the stream.
+type StreamInfo struct {
+	MinBlockSize int
+	MaxBlockSize int
+	MinFrameSize int
+	MaxFrameSize int
+	SampleRate   signal.Frequency
+	Channels     int
+	BitDepth     signal.BitDepth
+	// TotalSamples is a number of samples per channel, zero if unknown.
+	TotalSamples uint64
+	// MD5 is a signature of unencoded samples, zero if unknown.
+	MD5 [16]byte
+}
+
+// Allocator returns an allocator for buffers that hold provided number of
+// samples per channel.
+func (s StreamInfo) Allocator(length int) signal.Allocator {
+	return signal.Allocator{
+		Channels:   s.Channels,
+		Length:     length,
+		Capacity:   length,
+		SampleRate: s.SampleRate,
+	}
+}
+
+func (s StreamInfo) validate() error {
+	if s.Channels < 1 || s.Channels > 8 || s.BitDepth < 4 || s.BitDepth > 32 || s.SampleRate <= 0 || s.SampleRate >= 1<<20 {
+		return ErrFormat
+	}
+	return nil
+}
+
+func parseStreamInfo(b []byte) StreamInfo {
+	s := StreamInfo{
+		MinBlockSize: int(binary.BigEndian.Uint16(b[0:])),
+		MaxBlockSize: int(binary.BigEndian.Uint16(b[2:])),
+		MinFrameSize: int(uint32(b[4])<<16 | uint32(b[5])<<8 | uint32(b[6])),
+		MaxFrameSize: int(uint32(b[7])<<16 | uint32(b[8])<<8 | uint32(b[9])),
+	}
+	v := binary.BigEndian.Uint64(b[10:])
+	s.SampleRate = signal.Frequency(v >> 44)
+	s.Channels = int(v>>41&0x7) + 1
+	s.BitDepth = signal.BitDepth(v>>36&0x1f) + 1
+	s.TotalSamples = v & (1<<36 - 1)
+	copy(s.MD5[:], b[18:34])
+	return s
+}
+
+func (s StreamInfo) bytes() []byte {
+	b := make([]byte, 0, streamInfoSize)
+	b = binary.BigEndian.AppendUint16(b, uint16(s.MinBlockSize))
+	b = binary.BigEndian.AppendUint16(b, uint16(s.MaxBlockSize))
+	b = append(b, byte(s.MinFrameSize>>16), byte(s.MinFrameSize>>8), byte(s.MinFrameSize))
+	b = append(b, byte(s.MaxFrameSize>>16), byte(s.MaxFrameSize>>8), byte(s.MaxFrameSize))
+	v := uint64(s.SampleRate)<<44 |
+		uint64(s.Channels-1)<<41 |
+		uint64(s.BitDepth-1)<<36 |
+		s.TotalSamples&(1<<36-1)
+	b = binary.BigEndian.AppendUint64(b, v)
+	return append(b, s.MD5[:]...)
+}
+
+// channel assignments of the frame.
+const (
+	leftSide  = 0x8
+	sideRight = 0x9
+	midSide   = 0xa
+)
+
+// subframe types.
+const (
+	subframeConstant = iota
+	subframeVerbatim
+	subframeFixed
+	subframeLPC
+)
+
+// sampleRates are the sample rates that have frame header codes.
+var sampleRates = [...]signal.Frequency{
+	0x1: 88200,
+	0x2: 176400,
+	0x3: 192000,
+	0x4: 8000,
+	0x5: 16000,
+	0x6: 22050,
+	0x7: 24000,
+	0x8: 32000,
+	0x9: 44100,
+	0xa: 48000,
+	0xb: 96000,
+}
+
+// bitDepths are the bit depths that have frame header codes.
+var bitDepths = [...]signal.BitDepth{
+	0x1: 8,
+	0x2: 12,
+	0x4: 16,
+	0x5: 20,
+	0x6: 24,
+	0x7: 32,
+}
+
+// fixedCoefficients are the coefficients of fixed predictors.
+var fixedCoefficients = [...][]int64{
+	{},
+	{1},
+	{2, -1},
+	{3, -3, 1},
+	{4, -6, 4, -1},
+}
+
+// crc8Table is a CRC-8 table with polynomial x^8 + x^2 + x^1 + x^0.
+var crc8Table = func() (t [256]uint8) {
+	for i := range t {
+		c := uint8(i)
+		for j := 0; j < 8; j++ {
+			if c&0x80 != 0 {
+				c = c<<1 ^ 0x07
+			} else {
+				c <<= 1
+			}
+		}
+		t[i] = c
+	}
+	return
+}()
+
+// crc16Table is a CRC-16 table with polynomial x^16 + x^15 + x^2 + x^0.
+var crc16Table = func() (t [256]uint16) {
+	for i := range t {
+		c := uint16(i) << 8
+		for j := 0; j < 8; j++ {
+			if c&0x8000 != 0 {
+				c = c<<1 ^ 0x8005
+			} else {
+				c <<= 1
+			}
+		}
+		t[i] = c
+	}
+	return
+}()
+
+func crc8(c uint8, b byte) uint8 {
+	return crc8Table[c^b]
+}
+
+func crc16(c uint16, b byte) uint16 {
+	return c<<8 ^ crc16Table[byte(c>>8)^b]
+}
+
+// appendSamples appends samples to the byte slice as interleaved
+// little-endian values. It's used to compute MD5 signature.
+func appendSamples(b []byte, samples [][]int64, length int, bd signal.BitDepth) []byte {
+	size := int(bd+7) / 8
+	for i := 0; i < length; i++ {
+		for c := range samples {
+			v := samples[c][i]
+			for j := 0; j < size; j++ {
+				b = append(b, byte(v))
+				v >>= 8
+			}
+		}
+	}
+	return b
+}
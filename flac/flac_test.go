@@ -0,0 +1,288 @@
+package flac_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"math"
+	"math/rand"
+	"os"
+	"reflect"
+	"testing"
+
+	"pipelined.dev/signal"
+	"pipelined.dev/signal/flac"
+)
+
+func TestRoundTrip(t *testing.T) {
+	testOk := func(channels int, bd signal.BitDepth, rate signal.Frequency, level flac.Level, samples []int32) func(*testing.T) {
+		return func(t *testing.T) {
+			t.Helper()
+			var ws writeSeeker
+			e, err := flac.NewEncoder(&ws, channels, rate, bd, level)
+			assertNoError(t, err)
+			src := signal.AllocBitDepth[int32](e.Allocator(len(samples)/channels), bd)
+			signal.Write(samples, src)
+			// write samples in uneven chunks
+			half := src.Length() / 3
+			assertNoError(t, e.Write(src.Slice(0, half)))
+			assertNoError(t, e.Write(src.Slice(half, src.Length())))
+			assertNoError(t, e.Close())
+
+			d, err := flac.NewDecoder(bytes.NewReader(ws.data))
+			assertNoError(t, err)
+			assertEqual(t, "stream info", d.StreamInfo, e.StreamInfo)
+			assertEqual(t, "total samples", d.TotalSamples, uint64(src.Length()))
+			dst := signal.AllocBitDepth[int32](d.Allocator(1000), bd)
+			var result []int32
+			for {
+				n, err := d.Read(dst)
+				if err == io.EOF {
+					break
+				}
+				assertNoError(t, err)
+				read := make([]int32, n*channels)
+				signal.Read(dst, read)
+				result = append(result, read...)
+			}
+			assertEqual(t, "samples", result, samples)
+		}
+	}
+	for _, level := range []flac.Level{flac.LevelFastest, 2, flac.LevelDefault, flac.LevelBest} {
+		t.Run("stereo sine 16 bits", testOk(2, signal.BitDepth16, 44100, level, sine(2, 10000, signal.BitDepth16)))
+		t.Run("mono noise 24 bits", testOk(1, signal.BitDepth24, 96000, level, noise(1, 5000, signal.BitDepth24)))
+		t.Run("stereo noise 32 bits", testOk(2, signal.BitDepth32, 48000, level, noise(2, 5000, signal.BitDepth32)))
+		t.Run("multichannel 12 bits", testOk(6, 12, 22050, level, sine(6, 3000, 12)))
+		t.Run("8 bits odd rate", testOk(1, signal.BitDepth8, 12345, level, sine(1, 4500, signal.BitDepth8)))
+		t.Run("wasted bits", testOk(2, signal.BitDepth16, 44100, level, shift(sine(2, 5000, 12), 4)))
+		t.Run("silence", testOk(2, signal.BitDepth16, 44100, level, make([]int32, 2*5000)))
+		t.Run("short", testOk(2, signal.BitDepth16, 44100, level, sine(2, 10, signal.BitDepth16)))
+	}
+}
+
+func TestDecoder(t *testing.T) {
+	encode := func(samples []int32) []byte {
+		var ws writeSeeker
+		e, _ := flac.NewEncoder(&ws, 1, 44100, signal.BitDepth16, flac.LevelDefault)
+		buf := signal.AllocBitDepth[int32](e.Allocator(len(samples)), signal.BitDepth16)
+		signal.Write(samples, buf)
+		e.Write(buf)
+		e.Close()
+		return ws.data
+	}
+	readAll := func(d *flac.Decoder) error {
+		buf := signal.AllocBitDepth[int32](d.Allocator(4096), d.BitDepth)
+		for {
+			if _, err := d.Read(buf); err != nil {
+				return err
+			}
+		}
+	}
+	data := encode(sine(1, 10000, signal.BitDepth16))
+
+	t.Run("md5 mismatch", func(t *testing.T) {
+		corrupted := append([]byte{}, data...)
+		// last byte of MD5 signature
+		corrupted[8+33]++
+		d, err := flac.NewDecoder(bytes.NewReader(corrupted))
+		assertNoError(t, err)
+		assertError(t, readAll(d), flac.ErrMD5)
+	})
+	t.Run("checksum mismatch", func(t *testing.T) {
+		corrupted := append([]byte{}, data...)
+		corrupted[len(corrupted)-10]++
+		d, err := flac.NewDecoder(bytes.NewReader(corrupted))
+		assertNoError(t, err)
+		if err := readAll(d); err == io.EOF {
+			t.Fatalf("expected error")
+		}
+	})
+	t.Run("truncated", func(t *testing.T) {
+		d, err := flac.NewDecoder(bytes.NewReader(data[:len(data)-10]))
+		assertNoError(t, err)
+		assertError(t, readAll(d), io.ErrUnexpectedEOF)
+	})
+	t.Run("frame bit depth", func(t *testing.T) {
+		corrupted := append([]byte{}, data...)
+		// bit depth of STREAMINFO differs from the frame headers.
+		v := binary.BigEndian.Uint64(corrupted[8+10:])
+		v = v&^(0x1f<<36) | uint64(signal.BitDepth24-1)<<36
+		binary.BigEndian.PutUint64(corrupted[8+10:], v)
+		d, err := flac.NewDecoder(bytes.NewReader(corrupted))
+		assertNoError(t, err)
+		assertEqual(t, "bit depth", d.BitDepth, signal.BitDepth24)
+		assertError(t, readAll(d), flac.ErrFrame)
+	})
+	t.Run("not flac", func(t *testing.T) {
+		_, err := flac.NewDecoder(bytes.NewReader([]byte("RIFF")))
+		assertError(t, err, flac.ErrHeader)
+	})
+	t.Run("different bit depth", func(t *testing.T) {
+		d, err := flac.NewDecoder(bytes.NewReader(data))
+		assertNoError(t, err)
+		_, err = d.Read(signal.Alloc[int32](d.Allocator(10)))
+		assertError(t, err, flac.ErrBuffer)
+	})
+	t.Run("not seeker", func(t *testing.T) {
+		var buf bytes.Buffer
+		e, err := flac.NewEncoder(&buf, 1, 44100, signal.BitDepth16, flac.LevelFastest)
+		assertNoError(t, err)
+		src := signal.AllocBitDepth[int32](e.Allocator(100), signal.BitDepth16)
+		signal.Write(sine(1, 100, signal.BitDepth16), src)
+		assertNoError(t, e.Write(src))
+		assertNoError(t, e.Close())
+		d, err := flac.NewDecoder(&buf)
+		assertNoError(t, err)
+		assertEqual(t, "total samples", d.TotalSamples, uint64(0))
+		assertEqual(t, "error", readAll(d), io.EOF)
+	})
+}
+
+// TestReference decodes a stream that wasn't produced by this package.
+// The stream in testdata/reference.flac was assembled bit by bit from the
+// format specification and uses features the encoder never emits:
+// VORBIS_COMMENT and PADDING blocks, uncommon block size, sample rate and
+// bit depth taken from STREAMINFO, escaped and 5-bit rice partitions,
+// wasted bits and LPC with a shift. Its STREAMINFO carries MD5 of the
+// samples below, so the decoder also verifies them.
+func TestReference(t *testing.T) {
+	f, err := os.Open("testdata/reference.flac")
+	assertNoError(t, err)
+	defer f.Close()
+	d, err := flac.NewDecoder(f)
+	assertNoError(t, err)
+	assertEqual(t, "channels", d.Channels, 2)
+	assertEqual(t, "sample rate", d.SampleRate, signal.Frequency(44100))
+	assertEqual(t, "bit depth", d.BitDepth, signal.BitDepth16)
+	assertEqual(t, "total samples", d.TotalSamples, uint64(229))
+
+	var expected []int32
+	for i := 0; i < 229; i++ {
+		var l, r int
+		switch {
+		case i < 64:
+			l, r = i*97%512-256, 0
+		case i < 128:
+			l = (i-96)*(i-96)*3 - 1000
+			r = l - (i*31%17 - 8)
+		case i < 192:
+			l, r = 8*(i*13%64-32), 8*(i*5%40-20)
+		default:
+			l, r = i-205, i-210
+		}
+		expected = append(expected, int32(l), int32(r))
+	}
+	buf := signal.AllocBitDepth[int32](d.Allocator(100), d.BitDepth)
+	var result []int32
+	for {
+		n, err := d.Read(buf)
+		if err == io.EOF {
+			break
+		}
+		assertNoError(t, err)
+		read := make([]int32, n*2)
+		signal.Read(buf, read)
+		result = append(result, read...)
+	}
+	assertEqual(t, "samples", result, expected)
+}
+
+func TestCompression(t *testing.T) {
+	samples := sine(2, 44100, signal.BitDepth16)
+	var sizes []int
+	for _, level := range []flac.Level{flac.LevelFastest, flac.LevelBest} {
+		var ws writeSeeker
+		e, err := flac.NewEncoder(&ws, 2, 44100, signal.BitDepth16, level)
+		assertNoError(t, err)
+		src := signal.AllocBitDepth[int32](e.Allocator(len(samples)/2), signal.BitDepth16)
+		signal.Write(samples, src)
+		assertNoError(t, e.Write(src))
+		assertNoError(t, e.Close())
+		sizes = append(sizes, len(ws.data))
+	}
+	if raw := len(samples) * 2; sizes[0] >= raw/2 {
+		t.Fatalf("poor compression: %d of %d bytes", sizes[0], raw)
+	}
+	if sizes[1] > sizes[0] {
+		t.Fatalf("best level is larger than fastest: %v", sizes)
+	}
+}
+
+// sine returns interleaved samples of sine waves with different frequency
+// per channel.
+func sine(channels, length int, bd signal.BitDepth) []int32 {
+	samples := make([]int32, channels*length)
+	amplitude := float64(bd.MaxSignedValue()) * 0.9
+	for i := 0; i < length; i++ {
+		for c := 0; c < channels; c++ {
+			v := math.Sin(2 * math.Pi * float64(i) * float64(c+1) * 440 / 44100)
+			samples[i*channels+c] = int32(math.Round(v * amplitude))
+		}
+	}
+	return samples
+}
+
+// noise returns interleaved samples of full-scale white noise.
+func noise(channels, length int, bd signal.BitDepth) []int32 {
+	r := rand.New(rand.NewSource(1))
+	samples := make([]int32, channels*length)
+	for i := range samples {
+		samples[i] = int32(bd.SignedValue(r.Int63n(int64(bd.MaxUnsignedValue())+1) + bd.MinSignedValue()))
+	}
+	return samples
+}
+
+func shift(samples []int32, n uint) []int32 {
+	for i := range samples {
+		samples[i] <<= n
+	}
+	return samples
+}
+
+// writeSeeker is an in-memory io.WriteSeeker.
+type writeSeeker struct {
+	data []byte
+	pos  int
+}
+
+func (w *writeSeeker) Write(p []byte) (int, error) {
+	if end := w.pos + len(p); end > len(w.data) {
+		w.data = append(w.data, make([]byte, end-len(w.data))...)
+	}
+	w.pos += copy(w.data[w.pos:], p)
+	return len(p), nil
+}
+
+func (w *writeSeeker) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekStart:
+		w.pos = int(offset)
+	case io.SeekCurrent:
+		w.pos += int(offset)
+	case io.SeekEnd:
+		w.pos = len(w.data) + int(offset)
+	}
+	return int64(w.pos), nil
+}
+
+func assertNoError(t *testing.T, err error) {
+	t.Helper()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func assertError(t *testing.T, err, expected error) {
+	t.Helper()
+	if !errors.Is(err, expected) {
+		t.Fatalf("expected error: %v got: %v", expected, err)
+	}
+}
+
+func assertEqual(t *testing.T, name string, result, expected interface{}) {
+	t.Helper()
+	if !reflect.DeepEqual(expected, result) {
+		t.Fatalf("%v\nresult: \t%T\t%+v \nexpected: \t%T\t%+v", name, result, result, expected, expected)
+	}
+}
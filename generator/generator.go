@@ -0,0 +1,201 @@
+// Package generator provides test signal generators for signal buffers.
+//
+// Generator produces floating-point samples in range [-1, 1]. Fill writes
+// the same signal into every channel of the Buffer and converts samples
+// into the type and bit depth of the Buffer. Generators keep the phase
+// between calls, so the signal is continuous:
+//
+//	g := generator.NewSine(44100, 440, 0.5)
+//	generator.Fill(g, buf1)
+//	generator.Fill(g, buf2)
+//
+// Square and saw waveforms are band-limited with PolyBLEP method. Noise
+// generators use seeded source, so the output is reproducible.
+package generator
+
+import (
+	"math"
+
+	"pipelined.dev/signal"
+)
+
+// Generator produces a signal sample by sample.
+type Generator interface {
+	// Next returns the next sample of the signal.
+	Next() float64
+}
+
+// Fill writes [0:Length] samples of the generated signal into every
+// channel of the Buffer. Fixed-point samples are scaled to the bit depth
+// of the Buffer. Generators of this package keep the buffer of generated
+// samples between calls, so Fill doesn't allocate when it's called with
+// buffers of the same shape. Returns a number of samples written per
+// channel.
+func Fill[T signal.SignalTypes](g Generator, dst *signal.Buffer[T]) int {
+	length := dst.Length()
+	var src *signal.Buffer[float64]
+	if s, ok := g.(scratcher); ok {
+		src = s.scratch(dst.Channels(), length)
+	} else {
+		src = allocScratch(dst.Channels(), length)
+	}
+	for i := 0; i < length; i++ {
+		v := g.Next()
+		for c := 0; c < src.Channels(); c++ {
+			src.SetSample(src.BufferIndex(c, i), v)
+		}
+	}
+	return signal.Convert(src, dst)
+}
+
+// scratcher is implemented by generators that keep the buffer of
+// generated samples.
+type scratcher interface {
+	scratch(channels, length int) *signal.Buffer[float64]
+}
+
+// scratchBuffer holds the buffer of generated samples. It's reallocated
+// only when the shape of requested buffer changes.
+type scratchBuffer struct {
+	buf *signal.Buffer[float64]
+}
+
+func (s *scratchBuffer) scratch(channels, length int) *signal.Buffer[float64] {
+	if s.buf == nil || s.buf.Channels() != channels || s.buf.Length() != length {
+		s.buf = allocScratch(channels, length)
+	}
+	return s.buf
+}
+
+func allocScratch(channels, length int) *signal.Buffer[float64] {
+	return signal.Alloc[float64](signal.Allocator{
+		Channels: channels,
+		Length:   length,
+		Capacity: length,
+	})
+}
+
+// oscillator is a phase accumulator of periodic waveforms. Phase is
+// normalized to range [0, 1).
+type oscillator struct {
+	sampleRate signal.Frequency
+	amplitude  float64
+	phase      float64
+	increment  float64
+}
+
+func newOscillator(sampleRate, freq signal.Frequency, amplitude float64) oscillator {
+	return oscillator{
+		sampleRate: sampleRate,
+		amplitude:  amplitude,
+		increment:  float64(freq) / float64(sampleRate),
+	}
+}
+
+// SetFrequency changes the frequency of the waveform. The phase is
+// continuous.
+func (o *oscillator) SetFrequency(freq signal.Frequency) {
+	o.increment = float64(freq) / float64(o.sampleRate)
+}
+
+// advance returns the current phase and moves to the next one.
+func (o *oscillator) advance() float64 {
+	p := o.phase
+	o.phase += o.increment
+	o.phase -= math.Floor(o.phase)
+	return p
+}
+
+// Sine generates a sine wave.
+type Sine struct {
+	oscillator
+	scratchBuffer
+}
+
+// NewSine returns a sine generator with provided frequency and amplitude.
+func NewSine(sampleRate, freq signal.Frequency, amplitude float64) *Sine {
+	return &Sine{oscillator: newOscillator(sampleRate, freq, amplitude)}
+}
+
+// Next returns the next sample of the sine wave.
+func (g *Sine) Next() float64 {
+	return g.amplitude * math.Sin(2*math.Pi*g.advance())
+}
+
+// Square generates a band-limited square wave.
+type Square struct {
+	oscillator
+	scratchBuffer
+}
+
+// NewSquare returns a square generator with provided frequency and
+// amplitude.
+func NewSquare(sampleRate, freq signal.Frequency, amplitude float64) *Square {
+	return &Square{oscillator: newOscillator(sampleRate, freq, amplitude)}
+}
+
+// Next returns the next sample of the square wave.
+func (g *Square) Next() float64 {
+	p := g.advance()
+	v := -1.0
+	if p < 0.5 {
+		v = 1
+	}
+	half := p + 0.5
+	half -= math.Floor(half)
+	v += polyBLEP(p, g.increment) - polyBLEP(half, g.increment)
+	return g.amplitude * v
+}
+
+// Saw generates a band-limited rising sawtooth wave.
+type Saw struct {
+	oscillator
+	scratchBuffer
+}
+
+// NewSaw returns a sawtooth generator with provided frequency and
+// amplitude.
+func NewSaw(sampleRate, freq signal.Frequency, amplitude float64) *Saw {
+	return &Saw{oscillator: newOscillator(sampleRate, freq, amplitude)}
+}
+
+// Next returns the next sample of the sawtooth wave.
+func (g *Saw) Next() float64 {
+	p := g.advance()
+	return g.amplitude * (2*p - 1 - polyBLEP(p, g.increment))
+}
+
+// Triangle generates a triangle wave. It starts from zero and rises like
+// the sine wave.
+type Triangle struct {
+	oscillator
+	scratchBuffer
+}
+
+// NewTriangle returns a triangle generator with provided frequency and
+// amplitude.
+func NewTriangle(sampleRate, freq signal.Frequency, amplitude float64) *Triangle {
+	return &Triangle{oscillator: newOscillator(sampleRate, freq, amplitude)}
+}
+
+// Next returns the next sample of the triangle wave.
+func (g *Triangle) Next() float64 {
+	p := g.advance() + 0.25
+	p -= math.Floor(p)
+	return g.amplitude * (1 - 4*math.Abs(p-0.5))
+}
+
+// polyBLEP returns the correction of discontinuity at zero phase with the
+// phase increment dt.
+func polyBLEP(p, dt float64) float64 {
+	switch {
+	case p < dt:
+		p /= dt
+		return 2*p - p*p - 1
+	case p > 1-dt:
+		p = (p - 1) / dt
+		return p*p + 2*p + 1
+	default:
+		return 0
+	}
+}
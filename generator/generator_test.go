@@ -0,0 +1,240 @@
+package generator_test
+
+import (
+	"math"
+	"math/cmplx"
+	"reflect"
+	"testing"
+	"time"
+
+	"pipelined.dev/signal"
+	"pipelined.dev/signal/fft"
+	"pipelined.dev/signal/generator"
+)
+
+const sampleRate signal.Frequency = 48000
+
+func TestOscillators(t *testing.T) {
+	const length = 4800
+	testOk := func(g generator.Generator, expected func(i int) float64, tolerance float64) func(*testing.T) {
+		return func(t *testing.T) {
+			t.Helper()
+			buf := signal.Alloc[float64](signal.Allocator{Channels: 2, Length: length, Capacity: length})
+			// phase is continuous between calls.
+			assertEqual(t, "filled", generator.Fill(g, buf.Slice(0, length/3)), length/3)
+			assertEqual(t, "filled", generator.Fill(g, buf.Slice(length/3, length)), length-length/3)
+			for i := 0; i < length; i++ {
+				e := expected(i)
+				for c := 0; c < 2; c++ {
+					if v := buf.Sample(buf.BufferIndex(c, i)); math.Abs(v-e) > tolerance {
+						t.Fatalf("channel %d sample %d: %v expected: %v", c, i, v, e)
+					}
+				}
+			}
+		}
+	}
+	phase := func(i int, freq float64) float64 {
+		p := float64(i) * freq / float64(sampleRate)
+		return p - math.Floor(p)
+	}
+	t.Run("sine", testOk(generator.NewSine(sampleRate, 440, 0.5), func(i int) float64 {
+		return 0.5 * math.Sin(2*math.Pi*440*float64(i)/float64(sampleRate))
+	}, 1e-9))
+	t.Run("triangle", testOk(generator.NewTriangle(sampleRate, 480, 1), func(i int) float64 {
+		// period is 100 samples.
+		switch x := float64(i % 100); {
+		case x < 25:
+			return x / 25
+		case x < 75:
+			return 2 - x/25
+		default:
+			return x/25 - 4
+		}
+	}, 1e-9))
+	// away from discontinuities band-limited waveforms match naive ones.
+	t.Run("square", testOk(generator.NewSquare(sampleRate, 100, 1), func(i int) float64 {
+		switch p := phase(i, 100); {
+		case p < 0.01 || (p > 0.49 && p < 0.51) || p > 0.99:
+			return 0
+		case p < 0.5:
+			return 1
+		default:
+			return -1
+		}
+	}, 1))
+	t.Run("saw", testOk(generator.NewSaw(sampleRate, 100, 1), func(i int) float64 {
+		p := phase(i, 100)
+		if p < 0.01 || p > 0.99 {
+			return 0
+		}
+		return 2*p - 1
+	}, 1.001))
+
+	t.Run("frequency change", func(t *testing.T) {
+		g := generator.NewSine(sampleRate, 1000, 1)
+		var prev float64
+		for i := 0; i < 1000; i++ {
+			if i == 500 {
+				g.SetFrequency(2000)
+			}
+			v := g.Next()
+			// maximum difference of subsequent samples.
+			if i > 0 && math.Abs(v-prev) > 2*math.Pi*2000/float64(sampleRate) {
+				t.Fatalf("discontinuity at %d: %v %v", i, prev, v)
+			}
+			prev = v
+		}
+	})
+
+	t.Run("band-limited", func(t *testing.T) {
+		// aliased harmonics are suppressed compared to naive waveforms.
+		const (
+			size = 48000
+			freq = 3100
+			// the first alias of 9th harmonic.
+			alias = size - 9*freq
+		)
+		plan, _ := fft.NewRealPlan(size)
+		level := func(samples func(i int) float64) float64 {
+			buf := signal.Alloc[float64](signal.Allocator{Channels: 1, Length: size, Capacity: size})
+			for i := 0; i < size; i++ {
+				buf.SetSample(i, samples(i))
+			}
+			spectrum := make([]complex128, plan.SpectrumLen())
+			fft.Forward(plan, buf, 0, spectrum)
+			return 20 * math.Log10(cmplx.Abs(spectrum[alias])/cmplx.Abs(spectrum[freq]))
+		}
+		testAlias := func(g generator.Generator, naive func(p float64) float64) {
+			t.Helper()
+			generated := level(func(int) float64 { return g.Next() })
+			expected := level(func(i int) float64 { return naive(phase(i, freq)) })
+			if generated > expected-10 {
+				t.Fatalf("%T alias level: %v dB naive: %v dB", g, generated, expected)
+			}
+		}
+		testAlias(generator.NewSquare(sampleRate, freq, 1), func(p float64) float64 {
+			if p < 0.5 {
+				return 1
+			}
+			return -1
+		})
+		testAlias(generator.NewSaw(sampleRate, freq, 1), func(p float64) float64 {
+			return 2*p - 1
+		})
+	})
+}
+
+func TestSweep(t *testing.T) {
+	t.Run("chirp", func(t *testing.T) {
+		g := generator.NewChirp(sampleRate, 100, 10000, time.Second, 1)
+		// count zero crossings of every 0.1 second.
+		const window = 4800
+		var (
+			prev      float64
+			crossings []int
+		)
+		for i := 0; i < 12*window; i++ {
+			if i%window == 0 {
+				crossings = append(crossings, 0)
+			}
+			v := g.Next()
+			if prev < 0 && v >= 0 {
+				crossings[len(crossings)-1]++
+			}
+			prev = v
+		}
+		// instant frequency is 100 * 100^t.
+		for k, c := range crossings[:10] {
+			start := 100 * math.Pow(100, float64(k)/10)
+			end := 100 * math.Pow(100, float64(k+1)/10)
+			// mean frequency over the window.
+			expected := (end - start) / math.Log(100)
+			if math.Abs(float64(c)-expected) > 2 {
+				t.Fatalf("window %d crossings: %d expected: %v", k, c, expected)
+			}
+		}
+		// end frequency is held.
+		assertEqual(t, "held", crossings[11], 1000)
+	})
+	t.Run("chirp frequency", func(t *testing.T) {
+		assertPanic(t, func() {
+			generator.NewChirp(sampleRate, 0, 1000, time.Second, 1)
+		})
+		assertPanic(t, func() {
+			generator.NewChirp(sampleRate, 100, -1, time.Second, 1)
+		})
+	})
+
+	t.Run("impulse", func(t *testing.T) {
+		buf := signal.Alloc[int16](signal.Allocator{Channels: 2, Length: 10, Capacity: 10})
+		generator.Fill(generator.NewImpulse(1, 4), buf)
+		assertEqual(t, "periodic", channel(buf, 1), []int16{
+			math.MaxInt16, 0, 0, 0, math.MaxInt16, 0, 0, 0, math.MaxInt16, 0,
+		})
+		buf = signal.Alloc[int16](signal.Allocator{Channels: 1, Length: 10, Capacity: 10})
+		g := generator.NewImpulse(-1, 0)
+		generator.Fill(g, buf.Slice(0, 3))
+		generator.Fill(g, buf.Slice(3, 10))
+		assertEqual(t, "single", channel(buf, 0), []int16{
+			math.MinInt16, 0, 0, 0, 0, 0, 0, 0, 0, 0,
+		})
+	})
+}
+
+func TestBitDepth(t *testing.T) {
+	buf := signal.AllocBitDepth[int32](signal.Allocator{Channels: 1, Length: 4, Capacity: 4}, signal.BitDepth8)
+	generator.Fill(generator.NewSquare(sampleRate, 12000, 1), buf)
+	// values are limited by the bit depth of the buffer.
+	for i := 0; i < buf.Len(); i++ {
+		if v := buf.Sample(i); v > 127 || v < -128 {
+			t.Fatalf("sample %d: %v out of 8-bit range", i, v)
+		}
+	}
+	ubuf := signal.AllocBitDepth[uint16](signal.Allocator{Channels: 1, Length: 1, Capacity: 1}, signal.BitDepth16)
+	generator.Fill(generator.NewImpulse(1, 0), ubuf)
+	assertEqual(t, "unsigned", ubuf.Sample(0), uint16(math.MaxUint16))
+}
+
+func TestFillAllocs(t *testing.T) {
+	buf := signal.Alloc[int16](signal.Allocator{Channels: 2, Length: 512, Capacity: 512})
+	generators := []generator.Generator{
+		generator.NewSine(sampleRate, 440, 1),
+		generator.NewSaw(sampleRate, 440, 1),
+		generator.NewPinkNoise(1, 1),
+		generator.NewChirp(sampleRate, 20, 20000, time.Second, 1),
+		generator.NewImpulse(1, 100),
+	}
+	for _, g := range generators {
+		generator.Fill(g, buf)
+		allocs := testing.AllocsPerRun(10, func() {
+			generator.Fill(g, buf)
+		})
+		assertEqual(t, "allocs", allocs, 0.0)
+	}
+}
+
+// channel returns [0:Length] samples of the channel.
+func channel[T signal.SignalTypes](b *signal.Buffer[T], c int) []T {
+	result := make([]T, b.Length())
+	for i := range result {
+		result[i] = b.Sample(b.BufferIndex(c, i))
+	}
+	return result
+}
+
+func assertPanic(t *testing.T, fn func()) {
+	t.Helper()
+	defer func() {
+		if r := recover(); r == nil {
+			t.Fatalf("expected panic")
+		}
+	}()
+	fn()
+}
+
+func assertEqual(t *testing.T, name string, result, expected interface{}) {
+	t.Helper()
+	if !reflect.DeepEqual(expected, result) {
+		t.Fatalf("%v\nresult: \t%T\t%+v \nexpected: \t%T\t%+v", name, result, result, expected, expected)
+	}
+}
@@ -0,0 +1,91 @@
+package generator
+
+import (
+	"math"
+	"math/rand"
+)
+
+// WhiteNoise generates uniformly distributed noise with flat spectrum.
+type WhiteNoise struct {
+	scratchBuffer
+	amplitude float64
+	rand      *rand.Rand
+}
+
+// NewWhiteNoise returns a white noise generator with provided amplitude.
+// The same seed results in the same noise.
+func NewWhiteNoise(amplitude float64, seed int64) *WhiteNoise {
+	return &WhiteNoise{
+		amplitude: amplitude,
+		rand:      rand.New(rand.NewSource(seed)),
+	}
+}
+
+// Next returns the next sample of the noise.
+func (g *WhiteNoise) Next() float64 {
+	return g.amplitude * g.white()
+}
+
+// white returns uniform noise in range [-1, 1).
+func (g *WhiteNoise) white() float64 {
+	return 2*g.rand.Float64() - 1
+}
+
+// PinkNoise generates noise with power spectral density decreasing by
+// 3 dB per octave. White noise is shaped with Paul Kellet's filter.
+type PinkNoise struct {
+	scratchBuffer
+	source WhiteNoise
+	state  [7]float64
+}
+
+// NewPinkNoise returns a pink noise generator with provided amplitude.
+// The same seed results in the same noise.
+func NewPinkNoise(amplitude float64, seed int64) *PinkNoise {
+	return &PinkNoise{
+		source: *NewWhiteNoise(amplitude, seed),
+	}
+}
+
+// Next returns the next sample of the noise.
+func (g *PinkNoise) Next() float64 {
+	w := g.source.white()
+	s := &g.state
+	s[0] = 0.99886*s[0] + w*0.0555179
+	s[1] = 0.99332*s[1] + w*0.0750759
+	s[2] = 0.96900*s[2] + w*0.1538520
+	s[3] = 0.86650*s[3] + w*0.3104856
+	s[4] = 0.55000*s[4] + w*0.5329522
+	s[5] = -0.7616*s[5] - w*0.0168980
+	v := s[0] + s[1] + s[2] + s[3] + s[4] + s[5] + s[6] + w*0.5362
+	s[6] = w * 0.115926
+	// normalize the peak level of the filter.
+	return g.source.amplitude * clip(v*0.11)
+}
+
+// BrownNoise generates noise with power spectral density decreasing by
+// 6 dB per octave. White noise is integrated with a leaky integrator.
+type BrownNoise struct {
+	scratchBuffer
+	source WhiteNoise
+	state  float64
+}
+
+// NewBrownNoise returns a brown noise generator with provided amplitude.
+// The same seed results in the same noise.
+func NewBrownNoise(amplitude float64, seed int64) *BrownNoise {
+	return &BrownNoise{
+		source: *NewWhiteNoise(amplitude, seed),
+	}
+}
+
+// Next returns the next sample of the noise.
+func (g *BrownNoise) Next() float64 {
+	g.state = (g.state + 0.02*g.source.white()) / 1.02
+	// normalize the peak level of the integrator.
+	return g.source.amplitude * clip(g.state*3.5)
+}
+
+func clip(v float64) float64 {
+	return math.Max(-1, math.Min(1, v))
+}
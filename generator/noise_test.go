@@ -0,0 +1,69 @@
+package generator_test
+
+import (
+	"math"
+	"math/cmplx"
+	"testing"
+
+	"pipelined.dev/signal"
+	"pipelined.dev/signal/fft"
+	"pipelined.dev/signal/generator"
+)
+
+func TestNoise(t *testing.T) {
+	const (
+		size   = 4096
+		frames = 64
+	)
+	plan, _ := fft.NewRealPlan(size)
+	// density returns the mean power spectral density around the
+	// frequency.
+	density := func(g generator.Generator, freq float64) float64 {
+		buf := signal.Alloc[float64](signal.Allocator{Channels: 1, Length: size, Capacity: size})
+		spectrum := make([]complex128, plan.SpectrumLen())
+		low := int(0.9 * freq * size / float64(sampleRate))
+		high := int(1.1 * freq * size / float64(sampleRate))
+		var sum float64
+		for f := 0; f < frames; f++ {
+			generator.Fill(g, buf)
+			fft.Forward(plan, buf, 0, spectrum)
+			for k := low; k <= high; k++ {
+				sum += math.Pow(cmplx.Abs(spectrum[k]), 2)
+			}
+		}
+		return sum / float64(frames*(high-low+1))
+	}
+	testOk := func(fn func(amplitude float64, seed int64) generator.Generator, slope float64) func(*testing.T) {
+		return func(t *testing.T) {
+			t.Helper()
+			// the same seed results in the same noise.
+			g1, g2, g3 := fn(0.5, 1), fn(0.5, 1), fn(0.5, 2)
+			var different bool
+			for i := 0; i < 1000; i++ {
+				v1, v2, v3 := g1.Next(), g2.Next(), g3.Next()
+				assertEqual(t, "seed", v1, v2)
+				different = different || v1 != v3
+				if math.Abs(v1) > 0.5 {
+					t.Fatalf("sample %d: %v out of range", i, v1)
+				}
+			}
+			assertEqual(t, "different seed", different, true)
+
+			// power spectral density over three octaves.
+			g := fn(1, 1)
+			db := 10 * math.Log10(density(g, 3200)/density(g, 400))
+			if math.Abs(db-slope) > 1.5 {
+				t.Fatalf("slope: %v dB expected: %v dB", db, slope)
+			}
+		}
+	}
+	t.Run("white", testOk(func(a float64, seed int64) generator.Generator {
+		return generator.NewWhiteNoise(a, seed)
+	}, 0))
+	t.Run("pink", testOk(func(a float64, seed int64) generator.Generator {
+		return generator.NewPinkNoise(a, seed)
+	}, -9))
+	t.Run("brown", testOk(func(a float64, seed int64) generator.Generator {
+		return generator.NewBrownNoise(a, seed)
+	}, -17.5))
+}
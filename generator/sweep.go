@@ -0,0 +1,81 @@
+package generator
+
+import (
+	"math"
+	"time"
+
+	"pipelined.dev/signal"
+)
+
+// panic messages.
+const (
+	nonPositiveFrequency = "generator: frequency must be positive"
+)
+
+// Chirp generates a sine wave with logarithmic frequency sweep. After the
+// sweep duration the end frequency is held.
+type Chirp struct {
+	scratchBuffer
+	osc      oscillator
+	start    float64
+	ratio    float64
+	length   int
+	position int
+}
+
+// NewChirp returns a logarithmic sweep generator from start to end
+// frequency over the duration. Both frequencies must be positive,
+// otherwise function will panic.
+func NewChirp(sampleRate, start, end signal.Frequency, duration time.Duration, amplitude float64) *Chirp {
+	if start <= 0 || end <= 0 {
+		panic(nonPositiveFrequency)
+	}
+	return &Chirp{
+		osc:    newOscillator(sampleRate, start, amplitude),
+		start:  float64(start),
+		ratio:  float64(end) / float64(start),
+		length: sampleRate.Events(duration),
+	}
+}
+
+// Next returns the next sample of the sweep.
+func (g *Chirp) Next() float64 {
+	if g.position <= g.length {
+		freq := g.start * math.Pow(g.ratio, float64(g.position)/float64(max(g.length, 1)))
+		g.osc.SetFrequency(signal.Frequency(freq))
+		g.position++
+	}
+	return g.osc.amplitude * math.Sin(2*math.Pi*g.osc.advance())
+}
+
+// Impulse generates Dirac impulses.
+type Impulse struct {
+	scratchBuffer
+	amplitude float64
+	period    int
+	position  int
+}
+
+// NewImpulse returns a generator of impulses with provided amplitude. The
+// first sample is an impulse and it's repeated every period samples. Zero
+// period results in a single impulse.
+func NewImpulse(amplitude float64, period int) *Impulse {
+	return &Impulse{
+		amplitude: amplitude,
+		period:    period,
+	}
+}
+
+// Next returns the next sample of impulses.
+func (g *Impulse) Next() float64 {
+	v := 0.0
+	if g.position == 0 {
+		v = g.amplitude
+	}
+	if g.period > 0 {
+		g.position = (g.position + 1) % g.period
+	} else {
+		g.position = 1
+	}
+	return v
+}
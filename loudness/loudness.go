@@ -0,0 +1,328 @@
+// Package loudness provides loudness metering of signal buffers according
+// to ITU-R BS.1770 and EBU R128.
+//
+// Meter consumes the stream of buffers and reports momentary, short-term
+// and integrated loudness in LUFS, loudness range in LU and true peak in
+// dBTP:
+//
+//	m, err := loudness.New[int16](48000, loudness.Stereo)
+//	if err != nil {
+//		return err
+//	}
+//	for buf := range buffers {
+//		m.Write(buf)
+//	}
+//	fmt.Println(m.Integrated(), m.Range(), m.TruePeak())
+//
+// Signal is K-weighted and mean square of channels is summed with weights
+// of the channel layout. Loudness values are measured over gated blocks,
+// when there is not enough signal to measure, negative infinity is
+// returned.
+package loudness
+
+import (
+	"errors"
+	"math"
+	"sort"
+
+	"golang.org/x/exp/constraints"
+	"pipelined.dev/signal"
+	"pipelined.dev/signal/filter"
+)
+
+var (
+	// ErrSampleRate is returned when sample rate is not positive.
+	ErrSampleRate = errors.New("loudness: invalid sample rate")
+	// ErrLayout is returned when channel layout is empty.
+	ErrLayout = errors.New("loudness: empty channel layout")
+)
+
+// panic messages.
+const (
+	diffChannels = "loudness: different number of channels"
+)
+
+// Channel is a position of the channel in the layout.
+type Channel int
+
+// Positions of channels.
+const (
+	Left Channel = iota
+	Right
+	Center
+	LFE
+	LeftSurround
+	RightSurround
+)
+
+// Layout defines positions of the channels of Buffer.
+type Layout []Channel
+
+// Common channel layouts.
+var (
+	Mono       = Layout{Center}
+	Stereo     = Layout{Left, Right}
+	Surround51 = Layout{Left, Right, Center, LFE, LeftSurround, RightSurround}
+)
+
+// Weight returns the weight of the channel mean square. Surround channels
+// are amplified by 1.5 dB and low-frequency effects channel is excluded.
+func (c Channel) Weight() float64 {
+	switch c {
+	case LFE:
+		return 0
+	case LeftSurround, RightSurround:
+		return 1.41
+	default:
+		return 1
+	}
+}
+
+const (
+	// step is a duration of sub-block in seconds. Gating blocks overlap by
+	// 75% of momentary block.
+	step = 0.1
+	// momentaryBlocks is a number of steps in momentary block of 400 ms.
+	momentaryBlocks = 4
+	// shortTermBlocks is a number of steps in short-term block of 3 s.
+	shortTermBlocks = 30
+	// absoluteGate in LUFS.
+	absoluteGate = -70
+	// relativeGate in LU below the mean loudness.
+	relativeGate = -10
+	// rangeGate in LU below the mean short-term loudness.
+	rangeGate = -20
+)
+
+// Meter measures loudness of the signal stream. It's not safe for
+// concurrent use.
+type Meter[T signal.SignalTypes] struct {
+	sampleRate signal.Frequency
+	weights    []float64
+	weighting  *filter.Cascade[float64]
+	peak       *truePeak
+	// scratch holds samples of the last written Buffer converted into
+	// floating-point. It's reallocated when the length changes.
+	scratch *signal.Buffer[float64]
+	// stepLength is a number of samples in step.
+	stepLength int
+	position   int
+	// sums of squares of the current step.
+	sums []float64
+	// powers of the last steps, the last value is the newest.
+	steps []float64
+	// powers of all momentary and short-term blocks.
+	momentary []float64
+	shortTerm []float64
+}
+
+// New returns Meter for the sample rate and channel layout. Number of
+// channels of buffers must match the layout.
+func New[T signal.SignalTypes](sampleRate signal.Frequency, layout Layout) (*Meter[T], error) {
+	if sampleRate <= 0 {
+		return nil, ErrSampleRate
+	}
+	if len(layout) == 0 {
+		return nil, ErrLayout
+	}
+	m := Meter[T]{
+		sampleRate: sampleRate,
+		weights:    make([]float64, len(layout)),
+		weighting:  filter.NewCascade[float64](len(layout), KWeighting(sampleRate)),
+		peak:       newTruePeak(sampleRate, len(layout)),
+		stepLength: int(math.Round(step * float64(sampleRate))),
+		sums:       make([]float64, len(layout)),
+	}
+	for i, c := range layout {
+		m.weights[i] = c.Weight()
+	}
+	return &m, nil
+}
+
+// KWeighting returns sections of K-weighting filter for the sample rate.
+// It consists of high-shelf filter, which models the acoustic effect of
+// the head, and high-pass filter.
+func KWeighting(sampleRate signal.Frequency) filter.Sections {
+	// high shelf.
+	const (
+		shelfFreq = 1681.974450955533
+		shelfGain = 3.999843853973347
+		shelfQ    = 0.7071752369554196
+	)
+	k := math.Tan(math.Pi * shelfFreq / float64(sampleRate))
+	vh := math.Pow(10, shelfGain/20)
+	vb := math.Pow(vh, 0.4996667741545416)
+	a0 := 1 + k/shelfQ + k*k
+	shelf := filter.Coefficients{
+		B0: (vh + vb*k/shelfQ + k*k) / a0,
+		B1: 2 * (k*k - vh) / a0,
+		B2: (vh - vb*k/shelfQ + k*k) / a0,
+		A1: 2 * (k*k - 1) / a0,
+		A2: (1 - k/shelfQ + k*k) / a0,
+	}
+	// high pass.
+	const (
+		passFreq = 38.13547087602444
+		passQ    = 0.5003270373238773
+	)
+	k = math.Tan(math.Pi * passFreq / float64(sampleRate))
+	a0 = 1 + k/passQ + k*k
+	pass := filter.Coefficients{
+		B0: 1,
+		B1: -2,
+		B2: 1,
+		A1: 2 * (k*k - 1) / a0,
+		A2: (1 - k/passQ + k*k) / a0,
+	}
+	return filter.Sections{shelf, pass}
+}
+
+// Write measures [0:Length] samples of the Buffer. Fixed-point samples
+// are normalized by the bit depth of the Buffer. Buffer must have the
+// same number of channels as the layout, otherwise function will panic.
+func (m *Meter[T]) Write(b *signal.Buffer[T]) {
+	mustSame(len(m.weights), b.Channels(), diffChannels)
+	length := b.Length()
+	if m.scratch == nil || m.scratch.Length() != length {
+		m.scratch = signal.Alloc[float64](signal.Allocator{
+			Channels: b.Channels(),
+			Length:   length,
+			Capacity: length,
+		})
+	}
+	src := m.scratch
+	signal.Convert(b, src)
+	m.peak.write(src)
+	m.weighting.Process(src, src)
+	for i := 0; i < length; i++ {
+		for c := range m.sums {
+			v := src.Sample(src.BufferIndex(c, i))
+			m.sums[c] += v * v
+		}
+		m.position++
+		if m.position == m.stepLength {
+			m.completeStep()
+		}
+	}
+}
+
+// Momentary returns loudness of the last 400 ms in LUFS.
+func (m *Meter[T]) Momentary() float64 {
+	return m.blockLoudness(momentaryBlocks)
+}
+
+// ShortTerm returns loudness of the last 3 seconds in LUFS.
+func (m *Meter[T]) ShortTerm() float64 {
+	return m.blockLoudness(shortTermBlocks)
+}
+
+// Integrated returns gated loudness of the whole stream in LUFS.
+func (m *Meter[T]) Integrated() float64 {
+	gated := gate(m.momentary, absoluteGate)
+	if len(gated) == 0 {
+		return math.Inf(-1)
+	}
+	return loudness(mean(gate(gated, loudness(mean(gated))+relativeGate)))
+}
+
+// Range returns loudness range of the stream in LU. It's a difference
+// between 95th and 10th percentiles of gated short-term loudness.
+func (m *Meter[T]) Range() float64 {
+	gated := gate(m.shortTerm, absoluteGate)
+	if len(gated) == 0 {
+		return 0
+	}
+	gated = gate(gated, loudness(mean(gated))+rangeGate)
+	values := make([]float64, len(gated))
+	for i, p := range gated {
+		values[i] = loudness(p)
+	}
+	sort.Float64s(values)
+	return percentile(values, 0.95) - percentile(values, 0.10)
+}
+
+// TruePeak returns the maximum true peak of all channels in dBTP.
+func (m *Meter[T]) TruePeak() float64 {
+	return 20 * math.Log10(m.peak.max())
+}
+
+// Reset discards all measurements, so meter can be used for a new
+// stream.
+func (m *Meter[T]) Reset() {
+	m.weighting.Reset()
+	m.peak.reset()
+	clear(m.sums)
+	m.position = 0
+	m.steps = m.steps[:0]
+	m.momentary = m.momentary[:0]
+	m.shortTerm = m.shortTerm[:0]
+}
+
+// completeStep stores weighted power of the step and the blocks that end
+// with it.
+func (m *Meter[T]) completeStep() {
+	var power float64
+	for c, sum := range m.sums {
+		power += m.weights[c] * sum / float64(m.stepLength)
+	}
+	clear(m.sums)
+	m.position = 0
+	if len(m.steps) == shortTermBlocks {
+		copy(m.steps, m.steps[1:])
+		m.steps = m.steps[:shortTermBlocks-1]
+	}
+	m.steps = append(m.steps, power)
+	if len(m.steps) >= momentaryBlocks {
+		m.momentary = append(m.momentary, mean(m.steps[len(m.steps)-momentaryBlocks:]))
+	}
+	if len(m.steps) == shortTermBlocks {
+		m.shortTerm = append(m.shortTerm, mean(m.steps))
+	}
+}
+
+// blockLoudness returns loudness of the last block of n steps.
+func (m *Meter[T]) blockLoudness(n int) float64 {
+	if len(m.steps) < n {
+		return math.Inf(-1)
+	}
+	return loudness(mean(m.steps[len(m.steps)-n:]))
+}
+
+// gate returns powers with loudness above the threshold.
+func gate(powers []float64, threshold float64) []float64 {
+	var result []float64
+	for _, p := range powers {
+		if loudness(p) > threshold {
+			result = append(result, p)
+		}
+	}
+	return result
+}
+
+// loudness returns loudness of the weighted power in LUFS.
+func loudness(power float64) float64 {
+	return -0.691 + 10*math.Log10(power)
+}
+
+// percentile returns the value of sorted values at the fraction of the
+// distribution.
+func percentile(values []float64, p float64) float64 {
+	return values[int(math.Round(float64(len(values)-1)*p))]
+}
+
+func mean[T constraints.Float](values []T) T {
+	if len(values) == 0 {
+		return 0
+	}
+	var sum T
+	for _, v := range values {
+		sum += v
+	}
+	return sum / T(len(values))
+}
+
+func mustSame[T comparable](a, b T, panicStr string) {
+	if a != b {
+		panic(panicStr)
+	}
+}
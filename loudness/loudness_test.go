@@ -0,0 +1,192 @@
+package loudness_test
+
+import (
+	"math"
+	"reflect"
+	"testing"
+
+	"pipelined.dev/signal"
+	"pipelined.dev/signal/loudness"
+)
+
+const sampleRate signal.Frequency = 48000
+
+// segment is a part of test signal with a sine wave of the level in dBFS
+// and duration in seconds.
+type segment struct {
+	level    float64
+	duration float64
+}
+
+// sine writes stereo sine wave of 1 kHz into the meter. Zero bit depth
+// means the default bit depth of the type.
+func sine[T signal.SignalTypes](m *loudness.Meter[T], bitDepth signal.BitDepth, segments ...segment) {
+	const chunk = 1000
+	var n int
+	for _, s := range segments {
+		amplitude := math.Pow(10, s.level/20)
+		length := int(s.duration * float64(sampleRate))
+		for offset := 0; offset < length; offset += chunk {
+			size := min(chunk, length-offset)
+			src := signal.Alloc[float64](signal.Allocator{Channels: 2, Length: size, Capacity: size})
+			for i := 0; i < size; i++ {
+				v := amplitude * math.Sin(2*math.Pi*1000*float64(n)/float64(sampleRate))
+				src.SetSample(src.BufferIndex(0, i), v)
+				src.SetSample(src.BufferIndex(1, i), v)
+				n++
+			}
+			a := signal.Allocator{Channels: 2, Length: size, Capacity: size}
+			dst := signal.Alloc[T](a)
+			if bitDepth != 0 {
+				dst = signal.AllocBitDepth[T](a, bitDepth)
+			}
+			signal.Convert(src, dst)
+			m.Write(dst)
+		}
+	}
+}
+
+func TestMeter(t *testing.T) {
+	testOk := func(integrated, lra float64, segments ...segment) func(*testing.T) {
+		return func(t *testing.T) {
+			t.Helper()
+			m, err := loudness.New[float64](sampleRate, loudness.Stereo)
+			assertNoError(t, err)
+			sine(m, 0, segments...)
+			assertClose(t, "integrated", m.Integrated(), integrated, 0.1)
+			assertClose(t, "range", m.Range(), lra, 1)
+			// the last segment is long enough for momentary and short-term.
+			last := segments[len(segments)-1].level
+			assertClose(t, "momentary", m.Momentary(), last, 0.1)
+			assertClose(t, "short-term", m.ShortTerm(), last, 0.1)
+		}
+	}
+	// EBU Tech 3341 and 3342 test signals.
+	t.Run("-23 dBFS", testOk(-23, 0, segment{-23, 20}))
+	t.Run("-33 dBFS", testOk(-33, 0, segment{-33, 20}))
+	t.Run("relative gate", testOk(-23, 13, segment{-36, 10}, segment{-23, 60}, segment{-36, 10}))
+	t.Run("absolute gate", testOk(-23, 13, segment{-72, 10}, segment{-36, 10}, segment{-23, 60}, segment{-36, 10}, segment{-72, 10}))
+	t.Run("range 10 LU", testOk(-22.6, 10, segment{-20, 20}, segment{-30, 20}))
+	t.Run("range 5 LU", testOk(-16.81, 5, segment{-20, 20}, segment{-15, 20}))
+
+	t.Run("fixed-point", func(t *testing.T) {
+		m, err := loudness.New[int16](sampleRate, loudness.Stereo)
+		assertNoError(t, err)
+		sine(m, signal.BitDepth16, segment{-23, 5})
+		assertClose(t, "integrated", m.Integrated(), -23, 0.1)
+		m24, err := loudness.New[int32](sampleRate, loudness.Stereo)
+		assertNoError(t, err)
+		sine(m24, signal.BitDepth24, segment{-23, 5})
+		assertClose(t, "24 bit", m24.Integrated(), -23, 0.1)
+	})
+
+	t.Run("silence", func(t *testing.T) {
+		m, _ := loudness.New[float64](sampleRate, loudness.Stereo)
+		sine(m, 0, segment{-100, 1})
+		assertEqual(t, "integrated", m.Integrated(), math.Inf(-1))
+		assertEqual(t, "short-term", m.ShortTerm(), math.Inf(-1))
+		assertEqual(t, "range", m.Range(), 0.0)
+	})
+
+	t.Run("reset", func(t *testing.T) {
+		m, _ := loudness.New[float64](sampleRate, loudness.Stereo)
+		sine(m, 0, segment{-10, 5})
+		m.Reset()
+		sine(m, 0, segment{-30, 5})
+		assertClose(t, "integrated", m.Integrated(), -30, 0.1)
+		assertClose(t, "true peak", m.TruePeak(), -30, 0.1)
+	})
+
+	_, err := loudness.New[float64](0, loudness.Stereo)
+	assertEqual(t, "sample rate error", err, loudness.ErrSampleRate)
+	_, err = loudness.New[float64](sampleRate, nil)
+	assertEqual(t, "layout error", err, loudness.ErrLayout)
+	m, _ := loudness.New[float64](sampleRate, loudness.Surround51)
+	assertPanic(t, func() {
+		m.Write(signal.Alloc[float64](signal.Allocator{Channels: 2, Length: 1, Capacity: 1}))
+	})
+}
+
+func TestWriteAllocs(t *testing.T) {
+	m, err := loudness.New[int16](sampleRate, loudness.Stereo)
+	assertNoError(t, err)
+	b := signal.Alloc[int16](signal.Allocator{Channels: 2, Length: 480, Capacity: 480})
+	m.Write(b)
+	allocs := testing.AllocsPerRun(10, func() {
+		m.Write(b)
+	})
+	assertEqual(t, "allocs", allocs, 0.0)
+}
+
+func TestLayout(t *testing.T) {
+	// single channel of 0 dBFS sine reads -3.01 LUFS.
+	testOk := func(layout loudness.Layout, channel int, expected float64) func(*testing.T) {
+		return func(t *testing.T) {
+			t.Helper()
+			const length = 48000
+			m, err := loudness.New[float64](sampleRate, layout)
+			assertNoError(t, err)
+			b := signal.Alloc[float64](signal.Allocator{Channels: len(layout), Length: length, Capacity: length})
+			for i := 0; i < length; i++ {
+				b.SetSample(b.BufferIndex(channel, i), math.Sin(2*math.Pi*1000*float64(i)/float64(sampleRate)))
+			}
+			m.Write(b)
+			assertClose(t, "integrated", m.Integrated(), expected, 0.05)
+		}
+	}
+	t.Run("mono", testOk(loudness.Mono, 0, -3.01))
+	t.Run("left", testOk(loudness.Stereo, 0, -3.01))
+	t.Run("center", testOk(loudness.Surround51, 2, -3.01))
+	t.Run("surround", testOk(loudness.Surround51, 4, -1.52))
+	t.Run("lfe", testOk(loudness.Surround51, 3, math.Inf(-1)))
+}
+
+func TestKWeighting(t *testing.T) {
+	// coefficients at 48 kHz are specified by ITU-R BS.1770.
+	s := loudness.KWeighting(sampleRate)
+	expected := []float64{
+		1.53512485958697, -2.69169618940638, 1.19839281085285, -1.69065929318241, 0.73248077421585,
+		1.0, -2.0, 1.0, -1.99004745483398, 0.99007225036621,
+	}
+	result := []float64{
+		s[0].B0, s[0].B1, s[0].B2, s[0].A1, s[0].A2,
+		s[1].B0, s[1].B1, s[1].B2, s[1].A1, s[1].A2,
+	}
+	for i := range expected {
+		assertClose(t, "coefficient", result[i], expected[i], 1e-8)
+	}
+}
+
+func assertClose(t *testing.T, name string, result, expected, tolerance float64) {
+	t.Helper()
+	if math.IsInf(expected, -1) && math.IsInf(result, -1) {
+		return
+	}
+	if math.Abs(result-expected) > tolerance {
+		t.Fatalf("%v: %v expected: %v", name, result, expected)
+	}
+}
+
+func assertEqual(t *testing.T, name string, result, expected interface{}) {
+	t.Helper()
+	if !reflect.DeepEqual(expected, result) {
+		t.Fatalf("%v\nresult: \t%T\t%+v \nexpected: \t%T\t%+v", name, result, result, expected, expected)
+	}
+}
+
+func assertNoError(t *testing.T, err error) {
+	t.Helper()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func assertPanic(t *testing.T, fn func()) {
+	t.Helper()
+	defer func() {
+		if r := recover(); r == nil {
+			t.Fatalf("didn't panic")
+		}
+	}()
+	fn()
+}
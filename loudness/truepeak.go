@@ -0,0 +1,82 @@
+package loudness
+
+import (
+	"math"
+
+	"pipelined.dev/signal"
+	"pipelined.dev/signal/fir"
+	"pipelined.dev/signal/window"
+)
+
+// filterTaps is a number of interpolation filter taps. It's odd, so
+// the filter delay is an integer number of original samples.
+const filterTaps = 49
+
+// truePeak measures the maximum absolute value of oversampled signal.
+// Signal is oversampled 4 times below 96 kHz and 2 times below 192 kHz.
+type truePeak struct {
+	factor int
+	// phases of interpolation filter.
+	phases  [][]float64
+	history [][]float64
+	peak    float64
+}
+
+func newTruePeak(sampleRate signal.Frequency, channels int) *truePeak {
+	factor := 1
+	switch {
+	case sampleRate < 96000:
+		factor = 4
+	case sampleRate < 192000:
+		factor = 2
+	}
+	tp := truePeak{
+		factor:  factor,
+		phases:  make([][]float64, factor),
+		history: make([][]float64, channels),
+	}
+	// low-pass filter at Nyquist frequency of the original signal.
+	taps := fir.LowPass(sampleRate*signal.Frequency(factor), sampleRate/2, window.Kaiser(filterTaps, 8))
+	for i, t := range taps {
+		// compensate the gain of zeros inserted between samples.
+		tp.phases[i%factor] = append(tp.phases[i%factor], t*float64(factor))
+	}
+	for c := range tp.history {
+		tp.history[c] = make([]float64, len(tp.phases[0]))
+	}
+	return &tp
+}
+
+// write measures [0:Length] samples of the Buffer.
+func (tp *truePeak) write(b *signal.Buffer[float64]) {
+	for c, history := range tp.history {
+		for i := 0; i < b.Length(); i++ {
+			v := b.Sample(b.BufferIndex(c, i))
+			if tp.factor == 1 {
+				tp.peak = math.Max(tp.peak, math.Abs(v))
+				continue
+			}
+			copy(history[1:], history)
+			history[0] = v
+			for _, phase := range tp.phases {
+				var sum float64
+				for k, t := range phase {
+					sum += t * history[k]
+				}
+				tp.peak = math.Max(tp.peak, math.Abs(sum))
+			}
+		}
+	}
+}
+
+// max returns the maximum linear true peak.
+func (tp *truePeak) max() float64 {
+	return tp.peak
+}
+
+func (tp *truePeak) reset() {
+	for _, h := range tp.history {
+		clear(h)
+	}
+	tp.peak = 0
+}
@@ -0,0 +1,35 @@
+package loudness_test
+
+import (
+	"math"
+	"testing"
+
+	"pipelined.dev/signal"
+	"pipelined.dev/signal/loudness"
+)
+
+func TestTruePeak(t *testing.T) {
+	testOk := func(sampleRate signal.Frequency, freq, phase, expected float64) func(*testing.T) {
+		return func(t *testing.T) {
+			t.Helper()
+			length := int(sampleRate)
+			m, err := loudness.New[float64](sampleRate, loudness.Mono)
+			assertNoError(t, err)
+			b := signal.Alloc[float64](signal.Allocator{Channels: 1, Length: length, Capacity: length})
+			for i := 0; i < length; i++ {
+				b.SetSample(i, 0.5*math.Sin(2*math.Pi*freq*float64(i)/float64(sampleRate)+phase))
+			}
+			m.Write(b)
+			assertClose(t, "true peak", m.TruePeak(), expected, 0.2)
+		}
+	}
+	// samples of quarter sample rate sine with 45 degrees phase are
+	// 3 dB below the peak.
+	t.Run("inter-sample", testOk(48000, 12000, math.Pi/4, -6.02))
+	t.Run("sample peak", testOk(48000, 1000, 0, -6.02))
+	t.Run("2x oversampling", testOk(96000, 24000, math.Pi/4, -6.02))
+	t.Run("no oversampling", testOk(192000, 1000, 0, -6.02))
+
+	m, _ := loudness.New[float64](48000, loudness.Mono)
+	assertEqual(t, "empty", m.TruePeak(), math.Inf(-1))
+}
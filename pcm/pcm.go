@@ -0,0 +1,291 @@
+// Package pcm provides encoding and decoding of interleaved PCM byte
+// streams to and from signal buffers.
+//
+// Samples in the byte stream are described by Format. Decoded values are
+// quantized to the bit depth of the destination Buffer, encoded values are
+// quantized to the bit depth of the Format. Fixed-point values are mapped
+// to floating-point [-1, 1] range the same way signal conversion
+// functions do.
+package pcm
+
+import (
+	"errors"
+	"math"
+
+	"pipelined.dev/signal"
+)
+
+// ErrFormat is returned when Format is not supported.
+var ErrFormat = errors.New("pcm: unsupported format")
+
+// Encoding is the type of samples in the PCM byte stream.
+type Encoding uint8
+
+const (
+	// Signed is a two's complement fixed-point encoding.
+	Signed Encoding = iota
+	// Unsigned is an offset binary fixed-point encoding.
+	Unsigned
+	// Float is an IEEE 754 floating-point encoding.
+	Float
+)
+
+// Format defines the layout of samples in the PCM byte stream. BitDepth is
+// the size of a sample in bits and must be a multiple of 8. Samples of
+// lower resolution, ie 20 bits in a 24 bits container, should be decoded
+// with the container bit depth.
+type Format struct {
+	Encoding
+	signal.BitDepth
+	BigEndian bool
+}
+
+// Validate returns ErrFormat if the format is not supported.
+func (f Format) Validate() error {
+	switch f.Encoding {
+	case Signed, Unsigned:
+		if f.BitDepth == 0 || f.BitDepth > signal.MaxBitDepth || f.BitDepth%8 != 0 {
+			return ErrFormat
+		}
+	case Float:
+		if f.BitDepth != signal.BitDepth32 && f.BitDepth != signal.BitDepth64 {
+			return ErrFormat
+		}
+	default:
+		return ErrFormat
+	}
+	return nil
+}
+
+// SampleSize returns the size of a single sample in bytes.
+func (f Format) SampleSize() int {
+	return int(f.BitDepth) / 8
+}
+
+// FrameSize returns the size of samples for all channels at a single
+// point in time in bytes.
+func (f Format) FrameSize(channels int) int {
+	return f.SampleSize() * channels
+}
+
+// Decode decodes interleaved samples from src into the dst Buffer. Only
+// complete frames are decoded, incomplete last frame of the Buffer is
+// not modified. Returns a number of samples decoded per
+// channel.
+func Decode[T signal.SignalTypes](f Format, src []byte, dst *signal.Buffer[T]) (int, error) {
+	if err := f.Validate(); err != nil {
+		return 0, err
+	}
+	if dst.Channels() == 0 {
+		return 0, nil
+	}
+	size := f.SampleSize()
+	frames := min(len(src)/f.FrameSize(dst.Channels()), dst.Len()/dst.Channels())
+	k, bd := kindOf[T](), dst.BitDepth()
+	for i := 0; i < frames*dst.Channels(); i++ {
+		b := src[i*size : i*size+size]
+		if f.Encoding == Float {
+			dst.SetSample(i, fromFloat[T](f.float(b), k, bd))
+		} else {
+			dst.SetSample(i, fromSigned[T](f.signed(b), f.BitDepth, k, bd))
+		}
+	}
+	return frames, nil
+}
+
+// Encode encodes samples of the src Buffer into dst as interleaved
+// samples. Only complete frames are encoded, incomplete last frame of the
+// Buffer is skipped. Returns a number of samples
+// encoded per channel.
+func Encode[T signal.SignalTypes](f Format, src *signal.Buffer[T], dst []byte) (int, error) {
+	if err := f.Validate(); err != nil {
+		return 0, err
+	}
+	if src.Channels() == 0 {
+		return 0, nil
+	}
+	size := f.SampleSize()
+	frames := min(len(dst)/f.FrameSize(src.Channels()), src.Len()/src.Channels())
+	k, bd := kindOf[T](), src.BitDepth()
+	for i := 0; i < frames*src.Channels(); i++ {
+		b := dst[i*size : i*size+size]
+		if f.Encoding == Float {
+			f.putFloat(b, asFloat(src.Sample(i), k, bd))
+		} else {
+			f.putSigned(b, asSigned(src.Sample(i), k, bd, f.BitDepth))
+		}
+	}
+	return frames, nil
+}
+
+// kind is a class of signal types.
+type kind uint8
+
+const (
+	floating kind = iota
+	signed
+	unsigned
+)
+
+func kindOf[T signal.SignalTypes]() kind {
+	var v T = 1
+	if v/2 != 0 {
+		return floating
+	}
+	var z T
+	if z-1 < 0 {
+		return signed
+	}
+	return unsigned
+}
+
+// uint reads the raw sample bits.
+func (f Format) uint(b []byte) (v uint64) {
+	if f.BigEndian {
+		for i := range b {
+			v = v<<8 | uint64(b[i])
+		}
+		return
+	}
+	for i := len(b) - 1; i >= 0; i-- {
+		v = v<<8 | uint64(b[i])
+	}
+	return
+}
+
+// putUint writes the raw sample bits.
+func (f Format) putUint(b []byte, v uint64) {
+	if f.BigEndian {
+		for i := len(b) - 1; i >= 0; i-- {
+			b[i] = byte(v)
+			v >>= 8
+		}
+		return
+	}
+	for i := range b {
+		b[i] = byte(v)
+		v >>= 8
+	}
+}
+
+// signed reads fixed-point sample as a signed value.
+func (f Format) signed(b []byte) int64 {
+	v := f.uint(b)
+	if f.Encoding == Unsigned {
+		return int64(v - offset(f.BitDepth))
+	}
+	shift := 64 - f.BitDepth
+	return int64(v<<shift) >> shift
+}
+
+// putSigned writes signed value as a fixed-point sample.
+func (f Format) putSigned(b []byte, v int64) {
+	if f.Encoding == Unsigned {
+		f.putUint(b, uint64(v)+offset(f.BitDepth))
+		return
+	}
+	f.putUint(b, uint64(v))
+}
+
+func (f Format) float(b []byte) float64 {
+	if f.BitDepth == signal.BitDepth32 {
+		return float64(math.Float32frombits(uint32(f.uint(b))))
+	}
+	return math.Float64frombits(f.uint(b))
+}
+
+func (f Format) putFloat(b []byte, v float64) {
+	if f.BitDepth == signal.BitDepth32 {
+		f.putUint(b, uint64(math.Float32bits(float32(v))))
+		return
+	}
+	f.putUint(b, math.Float64bits(v))
+}
+
+// offset returns the value of unsigned zero level for the bit depth.
+func offset(bd signal.BitDepth) uint64 {
+	return uint64(bd.MaxSignedValue()) + 1
+}
+
+// fromSigned converts signed value of src bit depth into the sample of
+// dst bit depth.
+func fromSigned[T signal.SignalTypes](v int64, src signal.BitDepth, k kind, dst signal.BitDepth) T {
+	switch k {
+	case floating:
+		return T(signedAsFloat(v, src))
+	case signed:
+		return T(requantize(v, src, dst))
+	default:
+		return T(uint64(requantize(v, src, dst)) + offset(dst))
+	}
+}
+
+// fromFloat converts floating value into the sample of dst bit depth.
+func fromFloat[T signal.SignalTypes](v float64, k kind, dst signal.BitDepth) T {
+	switch k {
+	case floating:
+		return T(v)
+	case signed:
+		return T(floatAsSigned(v, dst))
+	default:
+		return T(uint64(floatAsSigned(v, dst)) + offset(dst))
+	}
+}
+
+// asSigned converts the sample of src bit depth into signed value of dst
+// bit depth.
+func asSigned[T signal.SignalTypes](s T, k kind, src, dst signal.BitDepth) int64 {
+	switch k {
+	case floating:
+		return floatAsSigned(float64(s), dst)
+	case signed:
+		return requantize(int64(s), src, dst)
+	default:
+		return requantize(int64(uint64(s)-offset(src)), src, dst)
+	}
+}
+
+// asFloat converts the sample of src bit depth into floating value.
+func asFloat[T signal.SignalTypes](s T, k kind, src signal.BitDepth) float64 {
+	switch k {
+	case floating:
+		return float64(s)
+	case signed:
+		return signedAsFloat(int64(s), src)
+	default:
+		return signedAsFloat(int64(uint64(s)-offset(src)), src)
+	}
+}
+
+// requantize maps signed value to another bit depth.
+func requantize(v int64, src, dst signal.BitDepth) int64 {
+	if src >= dst {
+		return v >> (src - dst)
+	}
+	if v > 0 {
+		return (v+1)<<(dst-src) - 1
+	}
+	return v << (dst - src)
+}
+
+func signedAsFloat(v int64, bd signal.BitDepth) float64 {
+	msv := float64(bd.MaxSignedValue())
+	if v > 0 {
+		return float64(v) / msv
+	}
+	return float64(v) / (msv + 1)
+}
+
+func floatAsSigned(v float64, bd signal.BitDepth) int64 {
+	msv := bd.MaxSignedValue()
+	switch {
+	case v >= 1:
+		return msv
+	case v <= -1:
+		return -msv - 1
+	case v > 0:
+		return int64(v * float64(msv))
+	default:
+		return int64(v * (float64(msv) + 1))
+	}
+}
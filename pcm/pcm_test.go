@@ -0,0 +1,181 @@
+package pcm_test
+
+import (
+	"bytes"
+	"errors"
+	"math"
+	"reflect"
+	"testing"
+
+	"pipelined.dev/signal"
+	"pipelined.dev/signal/pcm"
+)
+
+func TestDecode(t *testing.T) {
+	alloc := signal.Allocator{Channels: 2, Length: 2, Capacity: 2}
+	t.Run("signed 16 bits little endian", func(t *testing.T) {
+		buf := signal.Alloc[int16](alloc)
+		n, err := pcm.Decode(
+			pcm.Format{Encoding: pcm.Signed, BitDepth: signal.BitDepth16},
+			[]byte{0xff, 0x7f, 0x00, 0x80, 0x01, 0x00, 0xff, 0xff},
+			buf,
+		)
+		assertNoError(t, err)
+		assertEqual(t, "length", n, 2)
+		assertEqual(t, "samples", samples(buf), []int16{math.MaxInt16, math.MinInt16, 1, -1})
+	})
+	t.Run("signed 24 bits big endian into 24 bits", func(t *testing.T) {
+		buf := signal.AllocBitDepth[int32](alloc, signal.BitDepth24)
+		n, err := pcm.Decode(
+			pcm.Format{Encoding: pcm.Signed, BitDepth: signal.BitDepth24, BigEndian: true},
+			[]byte{0x7f, 0xff, 0xff, 0x80, 0x00, 0x00, 0x00, 0x00, 0x01, 0xff, 0xff, 0xff},
+			buf,
+		)
+		assertNoError(t, err)
+		assertEqual(t, "length", n, 2)
+		assertEqual(t, "samples", samples(buf), []int32{8388607, -8388608, 1, -1})
+	})
+	t.Run("signed 16 bits into 24 bits", func(t *testing.T) {
+		buf := signal.AllocBitDepth[int32](alloc, signal.BitDepth24)
+		n, err := pcm.Decode(
+			pcm.Format{Encoding: pcm.Signed, BitDepth: signal.BitDepth16},
+			[]byte{0xff, 0x7f, 0x00, 0x80, 0x00, 0x00, 0xff, 0xff},
+			buf,
+		)
+		assertNoError(t, err)
+		assertEqual(t, "length", n, 2)
+		assertEqual(t, "samples", samples(buf), []int32{8388607, -8388608, 0, -256})
+	})
+	t.Run("unsigned 8 bits into float", func(t *testing.T) {
+		buf := signal.Alloc[float64](alloc)
+		n, err := pcm.Decode(
+			pcm.Format{Encoding: pcm.Unsigned, BitDepth: signal.BitDepth8},
+			[]byte{0xff, 0x00, 0x80, 0x40},
+			buf,
+		)
+		assertNoError(t, err)
+		assertEqual(t, "length", n, 2)
+		assertEqual(t, "samples", samples(buf), []float64{1, -1, 0, -0.5})
+	})
+	t.Run("float 32 bits into 16 bits", func(t *testing.T) {
+		buf := signal.Alloc[int16](alloc)
+		src := make([]byte, 16)
+		for i, v := range []float32{1.5, -1, 0.5, 0} {
+			bits := math.Float32bits(v)
+			src[i*4], src[i*4+1], src[i*4+2], src[i*4+3] = byte(bits), byte(bits>>8), byte(bits>>16), byte(bits>>24)
+		}
+		n, err := pcm.Decode(pcm.Format{Encoding: pcm.Float, BitDepth: signal.BitDepth32}, src, buf)
+		assertNoError(t, err)
+		assertEqual(t, "length", n, 2)
+		assertEqual(t, "samples", samples(buf), []int16{math.MaxInt16, math.MinInt16, 16383, 0})
+	})
+	t.Run("incomplete frame", func(t *testing.T) {
+		buf := signal.Alloc[int8](alloc)
+		n, err := pcm.Decode(pcm.Format{Encoding: pcm.Signed, BitDepth: signal.BitDepth8}, []byte{1, 2, 3}, buf)
+		assertNoError(t, err)
+		assertEqual(t, "length", n, 1)
+		assertEqual(t, "samples", samples(buf), []int8{1, 2, 0, 0})
+	})
+	t.Run("incomplete buffer frame", func(t *testing.T) {
+		buf := signal.Alloc[int8](signal.Allocator{Channels: 2, Capacity: 2})
+		for i := 0; i < 3; i++ {
+			buf.AppendSample(0)
+		}
+		f := pcm.Format{Encoding: pcm.Signed, BitDepth: signal.BitDepth8}
+		n, err := pcm.Decode(f, []byte{1, 2, 3, 4}, buf)
+		assertNoError(t, err)
+		assertEqual(t, "decoded", n, 1)
+		assertEqual(t, "samples", samples(buf), []int8{1, 2, 0})
+
+		dst := []byte{9, 9, 9, 9}
+		n, err = pcm.Encode(f, buf, dst)
+		assertNoError(t, err)
+		assertEqual(t, "encoded", n, 1)
+		assertEqual(t, "bytes", dst, []byte{1, 2, 9, 9})
+	})
+	t.Run("unsupported format", func(t *testing.T) {
+		buf := signal.Alloc[int8](alloc)
+		_, err := pcm.Decode(pcm.Format{Encoding: pcm.Float, BitDepth: signal.BitDepth16}, []byte{1, 2}, buf)
+		if !errors.Is(err, pcm.ErrFormat) {
+			t.Fatalf("expected error: %v got: %v", pcm.ErrFormat, err)
+		}
+	})
+}
+
+func TestRoundTrip(t *testing.T) {
+	alloc := signal.Allocator{Channels: 2, Length: 4, Capacity: 4}
+	testOk := func(f pcm.Format, src []byte) func(*testing.T) {
+		return func(t *testing.T) {
+			t.Helper()
+			buf := signal.AllocBitDepth[int64](alloc, f.BitDepth)
+			n, err := pcm.Decode(f, src, buf)
+			assertNoError(t, err)
+			dst := make([]byte, len(src))
+			m, err := pcm.Encode(f, buf, dst)
+			assertNoError(t, err)
+			assertEqual(t, "length", n, m)
+			if !bytes.Equal(src, dst) {
+				t.Fatalf("result: %v expected: %v", dst, src)
+			}
+		}
+	}
+	src := make([]byte, 64)
+	for i := range src {
+		src[i] = byte(i * 37)
+	}
+	for _, bd := range []signal.BitDepth{8, 16, 24, 32, 64} {
+		size := int(bd) / 8 * 8
+		t.Run("signed", testOk(pcm.Format{Encoding: pcm.Signed, BitDepth: bd}, src[:size]))
+		t.Run("unsigned", testOk(pcm.Format{Encoding: pcm.Unsigned, BitDepth: bd}, src[:size]))
+		t.Run("signed big endian", testOk(pcm.Format{Encoding: pcm.Signed, BitDepth: bd, BigEndian: true}, src[:size]))
+	}
+}
+
+func TestEncodeFloat(t *testing.T) {
+	alloc := signal.Allocator{Channels: 1, Length: 3, Capacity: 3}
+	buf := signal.Alloc[float32](alloc)
+	signal.Write([]float32{1, -0.5, 0.25}, buf)
+	dst := make([]byte, 12)
+	n, err := pcm.Encode(pcm.Format{Encoding: pcm.Float, BitDepth: signal.BitDepth32, BigEndian: true}, buf, dst)
+	assertNoError(t, err)
+	assertEqual(t, "length", n, 3)
+	assertEqual(t, "bytes", dst, []byte{0x3f, 0x80, 0, 0, 0xbf, 0, 0, 0, 0x3e, 0x80, 0, 0})
+
+	i16 := signal.Alloc[int16](alloc)
+	_, err = pcm.Encode(pcm.Format{Encoding: pcm.Signed, BitDepth: signal.BitDepth16}, buf, dst)
+	assertNoError(t, err)
+	_, err = pcm.Decode(pcm.Format{Encoding: pcm.Signed, BitDepth: signal.BitDepth16}, dst, i16)
+	assertNoError(t, err)
+	assertEqual(t, "samples", samples(i16), []int16{math.MaxInt16, -16384, 8191})
+}
+
+func TestAllocations(t *testing.T) {
+	f := pcm.Format{Encoding: pcm.Signed, BitDepth: signal.BitDepth24}
+	buf := signal.AllocBitDepth[int32](signal.Allocator{Channels: 2, Length: 512, Capacity: 512}, signal.BitDepth24)
+	data := make([]byte, f.FrameSize(2)*512)
+	allocs := testing.AllocsPerRun(10, func() {
+		pcm.Decode(f, data, buf)
+		pcm.Encode(f, buf, data)
+	})
+	assertEqual(t, "allocations", allocs, float64(0))
+}
+
+func samples[T signal.SignalTypes](b *signal.Buffer[T]) []T {
+	result := make([]T, b.Len())
+	signal.Read(b, result)
+	return result
+}
+
+func assertNoError(t *testing.T, err error) {
+	t.Helper()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func assertEqual(t *testing.T, name string, result, expected interface{}) {
+	t.Helper()
+	if !reflect.DeepEqual(expected, result) {
+		t.Fatalf("%v\nresult: \t%T\t%+v \nexpected: \t%T\t%+v", name, result, result, expected, expected)
+	}
+}
@@ -0,0 +1,178 @@
+package signal
+
+import "time"
+
+// Planar is a buffer that contains digital signal of given type, where
+// samples of each channel are held in a separate contiguous slice. It
+// addresses samples with the same interleaved indices as Buffer, so it
+// implements Samples and can be used with Read, Write and conversion
+// functions.
+type Planar[T SignalTypes] struct {
+	channels
+	// data holds a slice per channel. All slices have the same length
+	// and share a single allocation.
+	data [][]T
+	bitDepth
+	sampleRate
+}
+
+// AllocPlanar allocates planar signal buffer based on provided type
+// parameter. Type parameter also determines bit depth of the buffer the
+// same way as in Alloc.
+func AllocPlanar[T SignalTypes](a Allocator) *Planar[T] {
+	return allocPlanar[T](a, getBitDepth[T]())
+}
+
+// AllocPlanarBitDepth allocates planar signal buffer with explicitly
+// provided bit depth. Bit depth is validated the same way as in
+// AllocBitDepth.
+func AllocPlanarBitDepth[T SignalTypes](a Allocator, bd BitDepth) *Planar[T] {
+	mustValidBitDepth[T](bd)
+	return allocPlanar[T](a, bd)
+}
+
+func allocPlanar[T SignalTypes](a Allocator, bd BitDepth) *Planar[T] {
+	return &Planar[T]{
+		channels:   channels(a.Channels),
+		data:       planarData(make([]T, a.Channels*a.Capacity), a.Channels, a.Length, a.Capacity),
+		bitDepth:   bitDepth(bd),
+		sampleRate: sampleRate(a.SampleRate),
+	}
+}
+
+// planarData splits the slice into channels of provided length and
+// capacity.
+func planarData[T SignalTypes](s []T, channels, length, capacity int) [][]T {
+	data := make([][]T, channels)
+	for c := range data {
+		data[c] = s[c*capacity : c*capacity+length : (c+1)*capacity]
+	}
+	return data
+}
+
+// Channel returns samples of the channel. The slice shares memory with
+// the Planar buffer.
+func (b *Planar[T]) Channel(c int) []T {
+	return b.data[c]
+}
+
+// Slice the Planar buffer with respect to channels.
+func (b *Planar[T]) Slice(start, end int) *Planar[T] {
+	data := make([][]T, len(b.data))
+	for c := range data {
+		data[c] = b.data[c][start:end]
+	}
+	return &Planar[T]{
+		channels:   b.channels,
+		data:       data,
+		bitDepth:   b.bitDepth,
+		sampleRate: b.sampleRate,
+	}
+}
+
+// Duration returns time duration of a single channel. Zero is returned if
+// sample rate is not set.
+func (b *Planar[T]) Duration() time.Duration {
+	if b.sampleRate == 0 {
+		return 0
+	}
+	return b.SampleRate().Duration(b.Length())
+}
+
+// Capacity returns capacity of a single channel.
+func (b *Planar[T]) Capacity() int {
+	if len(b.data) == 0 {
+		return 0
+	}
+	return cap(b.data[0])
+}
+
+// Length returns length of a single channel.
+func (b *Planar[T]) Length() int {
+	if len(b.data) == 0 {
+		return 0
+	}
+	return len(b.data[0])
+}
+
+// Cap returns capacity of whole Planar buffer.
+func (b *Planar[T]) Cap() int {
+	return b.Capacity() * b.Channels()
+}
+
+// Len returns length of whole Planar buffer.
+func (b *Planar[T]) Len() int {
+	return b.Length() * b.Channels()
+}
+
+// Sample returns signal value for provided interleaved sample index.
+func (b *Planar[T]) Sample(i int) T {
+	return b.data[i%int(b.channels)][i/int(b.channels)]
+}
+
+// SetSample sets sample value for provided interleaved sample index.
+func (b *Planar[T]) SetSample(i int, v T) {
+	b.data[i%int(b.channels)][i/int(b.channels)] = v
+}
+
+// Append appends [0:Length] samples from src to current Planar buffer.
+// Both buffers must have same number of channels, bit depth and sample
+// rate, otherwise function will panic. If capacity is not enough, new
+// memory is allocated for all channels.
+func (dst *Planar[T]) Append(src *Planar[T]) {
+	mustSame(dst.Channels(), src.Channels(), diffChannels)
+	mustSame(dst.BitDepth(), src.BitDepth(), diffBitDepth)
+	mustSame(dst.SampleRate(), src.SampleRate(), diffSampleRate)
+	offset := dst.Length()
+	length := offset + src.Length()
+	if dst.Capacity() < length {
+		capacity := max(length, 2*dst.Capacity())
+		data := planarData(make([]T, dst.Channels()*capacity), dst.Channels(), offset, capacity)
+		for c := range data {
+			copy(data[c], dst.data[c])
+		}
+		dst.data = data
+	}
+	for c := range dst.data {
+		dst.data[c] = dst.data[c][:length]
+		copy(dst.data[c][offset:], src.data[c])
+	}
+}
+
+func (b *Planar[T]) clear() {
+	for c := range b.data {
+		clear(b.data[c])
+	}
+}
+
+// Interleave copies [0:Length] samples from the planar source into the
+// interleaved destination Buffer. Buffers must have the same number of
+// channels and destination must contain only whole frames, otherwise
+// function will panic. Returns a number of samples copied per channel.
+func Interleave[T SignalTypes](src *Planar[T], dst *Buffer[T]) int {
+	mustSame(src.Channels(), dst.Channels(), diffChannels)
+	mustWholeFrames(dst.Len(), dst.Channels())
+	length := min(src.Length(), dst.Length())
+	for c, samples := range src.data {
+		for i, v := range samples[:length] {
+			dst.data[dst.BufferIndex(c, i)] = v
+		}
+	}
+	return length
+}
+
+// Deinterleave copies [0:Length] samples from the interleaved source
+// Buffer into the planar destination. Buffers must have the same number
+// of channels and source must contain only whole frames, otherwise
+// function will panic. Returns a number of samples copied per channel.
+func Deinterleave[T SignalTypes](src *Buffer[T], dst *Planar[T]) int {
+	mustSame(src.Channels(), dst.Channels(), diffChannels)
+	mustWholeFrames(src.Len(), src.Channels())
+	length := min(src.Length(), dst.Length())
+	for c, samples := range dst.data {
+		for i := range samples[:length] {
+			samples[i] = src.data[src.BufferIndex(c, i)]
+		}
+	}
+	return length
+}
@@ -0,0 +1,163 @@
+package signal_test
+
+import (
+	"testing"
+
+	"pipelined.dev/signal"
+)
+
+func TestPlanar(t *testing.T) {
+	alloc := signal.Allocator{Channels: 2, Length: 3, Capacity: 4, SampleRate: 44100}
+	planar := func(data ...[]int16) *signal.Planar[int16] {
+		p := signal.AllocPlanar[int16](signal.Allocator{
+			Channels:   len(data),
+			Length:     len(data[0]),
+			Capacity:   len(data[0]),
+			SampleRate: 44100,
+		})
+		for c := range data {
+			copy(p.Channel(c), data[c])
+		}
+		return p
+	}
+	result := func(p *signal.Planar[int16]) [][]int16 {
+		data := make([][]int16, p.Channels())
+		for c := range data {
+			data[c] = append([]int16{}, p.Channel(c)...)
+		}
+		return data
+	}
+
+	t.Run("alloc", func(t *testing.T) {
+		p := signal.AllocPlanar[int16](alloc)
+		assertEqual(t, "channels", p.Channels(), 2)
+		assertEqual(t, "length", p.Length(), 3)
+		assertEqual(t, "capacity", p.Capacity(), 4)
+		assertEqual(t, "len", p.Len(), 6)
+		assertEqual(t, "cap", p.Cap(), 8)
+		assertEqual(t, "bit depth", p.BitDepth(), signal.BitDepth16)
+		assertEqual(t, "sample rate", p.SampleRate(), signal.Frequency(44100))
+		assertEqual(t, "24 bits", signal.AllocPlanarBitDepth[int32](alloc, signal.BitDepth24).BitDepth(), signal.BitDepth24)
+		assertPanic(t, func() {
+			signal.AllocPlanarBitDepth[int16](alloc, signal.BitDepth24)
+		})
+		empty := signal.AllocPlanar[float64](signal.Allocator{})
+		assertEqual(t, "empty length", empty.Length(), 0)
+	})
+	t.Run("interleaved index", func(t *testing.T) {
+		p := planar([]int16{1, 2, 3}, []int16{11, 12, 13})
+		assertEqual(t, "sample", p.Sample(p.BufferIndex(1, 2)), int16(13))
+		p.SetSample(p.BufferIndex(0, 1), 5)
+		assertEqual(t, "channel", p.Channel(0), []int16{1, 5, 3})
+		read := make([]int16, p.Len())
+		signal.Read(p, read)
+		assertEqual(t, "read", read, []int16{1, 11, 5, 12, 3, 13})
+	})
+	t.Run("slice", func(t *testing.T) {
+		p := planar([]int16{1, 2, 3}, []int16{11, 12, 13})
+		s := p.Slice(1, 3)
+		assertEqual(t, "samples", result(s), [][]int16{{2, 3}, {12, 13}})
+		// slices share memory.
+		s.Channel(1)[0] = 0
+		assertEqual(t, "shared", p.Channel(1), []int16{11, 0, 13})
+	})
+	t.Run("append", func(t *testing.T) {
+		p := signal.AllocPlanar[int16](alloc).Slice(0, 1)
+		p.Append(planar([]int16{1, 2}, []int16{11, 12}))
+		assertEqual(t, "within capacity", result(p), [][]int16{{0, 1, 2}, {0, 11, 12}})
+		assertEqual(t, "capacity", p.Capacity(), 4)
+		p.Append(planar([]int16{3, 4}, []int16{13, 14}))
+		assertEqual(t, "grow", result(p), [][]int16{{0, 1, 2, 3, 4}, {0, 11, 12, 13, 14}})
+		assertPanic(t, func() {
+			p.Append(planar([]int16{1}))
+		})
+	})
+	t.Run("interleave", func(t *testing.T) {
+		p := planar([]int16{1, 2, 3}, []int16{11, 12, 13})
+		b := signal.Alloc[int16](alloc)
+		n := signal.Interleave(p, b)
+		assertEqual(t, "interleaved", n, 3)
+		read := make([]int16, b.Len())
+		signal.Read(b, read)
+		assertEqual(t, "buffer", read, []int16{1, 11, 2, 12, 3, 13})
+
+		d := signal.AllocPlanar[int16](alloc).Slice(0, 2)
+		n = signal.Deinterleave(b, d)
+		assertEqual(t, "deinterleaved", n, 2)
+		assertEqual(t, "planar", result(d), [][]int16{{1, 2}, {11, 12}})
+
+		allocs := testing.AllocsPerRun(10, func() {
+			signal.Interleave(p, b)
+			signal.Deinterleave(b, p)
+		})
+		assertEqual(t, "allocs", allocs, 0.0)
+		assertPanic(t, func() {
+			signal.Interleave(planar([]int16{1}), b)
+		})
+		// buffer with incomplete last frame.
+		partial := signal.Alloc[int16](signal.Allocator{Channels: 2, Length: 1, Capacity: 2})
+		partial.AppendSample(1)
+		assertPanicMessage(t, func() {
+			signal.Interleave(p, partial)
+		}, "buffer length is not a multiple of channels")
+		assertPanicMessage(t, func() {
+			signal.Deinterleave(partial, p)
+		}, "buffer length is not a multiple of channels")
+	})
+	t.Run("convert", func(t *testing.T) {
+		p := planar([]int16{-16384, 32767}, []int16{0, -32768})
+		b := signal.Alloc[float64](signal.Allocator{Channels: 2, Length: 2, Capacity: 2})
+		signal.Convert(p, b)
+		read := make([]float64, b.Len())
+		signal.Read(b, read)
+		assertEqual(t, "to buffer", read, []float64{-0.5, 0, 1, -1})
+
+		f := signal.AllocPlanar[float32](signal.Allocator{Channels: 2, Length: 2, Capacity: 2})
+		signal.Convert(b, f)
+		assertEqual(t, "to planar", f.Channel(1), []float32{0, -1})
+	})
+	t.Run("pool", func(t *testing.T) {
+		pool := signal.PoolAlloc[int16](alloc)
+		p := pool.GetPlanar()
+		assertEqual(t, "length", p.Length(), 3)
+		assertEqual(t, "capacity", p.Capacity(), 4)
+		p.Channel(0)[0] = 1
+		pool.PutPlanar(p)
+		assertEqual(t, "cleared", p.Channel(0), []int16{0, 0, 0})
+		assertPanic(t, func() {
+			pool.PutPlanar(signal.AllocPlanar[int16](signal.Allocator{Channels: 2, Capacity: 2, SampleRate: 44100}))
+		})
+	})
+}
+
+func TestStriped(t *testing.T) {
+	alloc := signal.Allocator{Channels: 2, Length: 3, Capacity: 3}
+	testOk := func(s signal.Samples[int16]) func(*testing.T) {
+		return func(t *testing.T) {
+			t.Helper()
+			written := signal.WriteStriped([][]int8{{1, 2, 3, 4}, {11}}, s)
+			assertEqual(t, "written", written, 3)
+			result := [][]float64{make([]float64, 2), nil}
+			read := signal.ReadStriped(s, result)
+			assertEqual(t, "read", read, 2)
+			assertEqual(t, "striped", result, [][]float64{{1, 2}, nil})
+			interleaved := make([]int16, s.Len())
+			signal.Read(s, interleaved)
+			assertEqual(t, "interleaved", interleaved, []int16{1, 11, 2, 0, 3, 0})
+			assertPanic(t, func() {
+				signal.ReadStriped(s, [][]int16{{}})
+			})
+		}
+	}
+	t.Run("buffer", testOk(signal.Alloc[int16](alloc)))
+	t.Run("planar", testOk(signal.AllocPlanar[int16](alloc)))
+
+	t.Run("channel", func(t *testing.T) {
+		b := signal.Alloc[int16](alloc)
+		written := signal.WriteStriped([][]int16{{5, 6, 7}}, b.Channel(1))
+		assertEqual(t, "written", written, 3)
+		result := make([]int16, b.Len())
+		signal.Read(b, result)
+		assertEqual(t, "samples", result, []int16{0, 5, 0, 6, 0, 7})
+	})
+}
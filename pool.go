@@ -1,23 +1,44 @@
 package signal
 
 import (
+	"math/bits"
 	"sync"
 )
 
 // PoolAllocator allows to decrease a number of allocations at runtime.
 // Internally it relies on sync.Pool to manage objects in memory.
 type PoolAllocator[T SignalTypes] struct {
-	pool  *sync.Pool
-	alloc Allocator
+	pool     *sync.Pool
+	planar   *sync.Pool
+	alloc    Allocator
+	bitDepth BitDepth
 }
 
 // PoolAlloc returns new PoolAllocator.
 func PoolAlloc[T SignalTypes](a Allocator) PoolAllocator[T] {
+	return poolAlloc[T](a, getBitDepth[T]())
+}
+
+// PoolAllocBitDepth returns new PoolAllocator that allocates buffers with
+// provided bit depth. Bit depth is validated the same way as in
+// AllocBitDepth.
+func PoolAllocBitDepth[T SignalTypes](a Allocator, bd BitDepth) PoolAllocator[T] {
+	mustValidBitDepth[T](bd)
+	return poolAlloc[T](a, bd)
+}
+
+func poolAlloc[T SignalTypes](a Allocator, bd BitDepth) PoolAllocator[T] {
 	return PoolAllocator[T]{
-		alloc: a,
+		alloc:    a,
+		bitDepth: bd,
 		pool: &sync.Pool{
 			New: func() any {
-				return Alloc[T](a)
+				return alloc[T](a, bd)
+			},
+		},
+		planar: &sync.Pool{
+			New: func() any {
+				return allocPlanar[T](a, bd)
 			},
 		},
 	}
@@ -29,6 +50,152 @@ func (p *PoolAllocator[T]) Get() *Buffer[T] {
 
 func (p *PoolAllocator[T]) Put(b *Buffer[T]) {
 	mustSame(p.alloc.Capacity*p.alloc.Channels, b.Cap(), diffCapacity)
+	mustSame(p.alloc.SampleRate, b.SampleRate(), diffSampleRate)
 	b.clear()
 	p.pool.Put(b)
 }
+
+// GetPlanar returns Planar buffer from the pool.
+func (p *PoolAllocator[T]) GetPlanar() *Planar[T] {
+	return p.planar.Get().(*Planar[T])
+}
+
+// PutPlanar clears the Planar buffer and returns it to the pool. Buffer
+// must have the capacity and sample rate of the pool allocator, otherwise
+// function will panic.
+func (p *PoolAllocator[T]) PutPlanar(b *Planar[T]) {
+	mustSame(p.alloc.Capacity*p.alloc.Channels, b.Cap(), diffCapacity)
+	mustSame(p.alloc.SampleRate, b.SampleRate(), diffSampleRate)
+	b.clear()
+	p.planar.Put(b)
+}
+
+// Pool serves buffers of any number of channels and capacity. Buffers
+// are kept in a sync.Pool per number of channels and capacity class.
+// Capacity is rounded up to the power of two, so buffers of close sizes
+// share the same class. It is safe for concurrent use by multiple
+// goroutines.
+type Pool[T SignalTypes] struct {
+	bitDepth BitDepth
+	mu       sync.RWMutex
+	buffers  map[poolKey]*sync.Pool
+	planars  map[poolKey]*sync.Pool
+}
+
+// poolKey identifies the class of pooled buffers.
+type poolKey struct {
+	channels int
+	capacity int
+}
+
+// NewPool returns new Pool. Type parameter determines bit depth of
+// buffers the same way as in Alloc.
+func NewPool[T SignalTypes]() *Pool[T] {
+	return newPool[T](getBitDepth[T]())
+}
+
+// NewPoolBitDepth returns new Pool that serves buffers with provided bit
+// depth. Bit depth is validated the same way as in AllocBitDepth.
+func NewPoolBitDepth[T SignalTypes](bd BitDepth) *Pool[T] {
+	mustValidBitDepth[T](bd)
+	return newPool[T](bd)
+}
+
+func newPool[T SignalTypes](bd BitDepth) *Pool[T] {
+	return &Pool[T]{
+		bitDepth: bd,
+		buffers:  make(map[poolKey]*sync.Pool),
+		planars:  make(map[poolKey]*sync.Pool),
+	}
+}
+
+// Get returns Buffer with number of channels, length and sample rate of
+// the allocator. Capacity of the Buffer is at least the allocator
+// capacity.
+func (p *Pool[T]) Get(a Allocator) *Buffer[T] {
+	b := p.pool(false, a).Get().(*Buffer[T])
+	b.data = b.data[:a.Channels*a.Length]
+	b.sampleRate = sampleRate(a.SampleRate)
+	return b
+}
+
+// Put clears the Buffer and returns it to the pool. Buffer must have the
+// bit depth of the pool and capacity of one of the classes, which is true
+// for any Buffer returned by Get, otherwise function will panic.
+func (p *Pool[T]) Put(b *Buffer[T]) {
+	mustSame(p.bitDepth, b.BitDepth(), diffBitDepth)
+	mustSame(capacityClass(b.Capacity()), b.Capacity(), diffCapacity)
+	b.data = b.data[:cap(b.data)]
+	b.clear()
+	p.pool(false, Allocator{Channels: b.Channels(), Capacity: b.Capacity()}).Put(b)
+}
+
+// GetPlanar returns Planar buffer with number of channels, length and
+// sample rate of the allocator. Capacity of the buffer is at least the
+// allocator capacity.
+func (p *Pool[T]) GetPlanar(a Allocator) *Planar[T] {
+	b := p.pool(true, a).Get().(*Planar[T])
+	for c := range b.data {
+		b.data[c] = b.data[c][:a.Length]
+	}
+	b.sampleRate = sampleRate(a.SampleRate)
+	return b
+}
+
+// PutPlanar clears the Planar buffer and returns it to the pool. Buffer
+// must have the bit depth of the pool and capacity of one of the
+// classes, which is true for any buffer returned by GetPlanar, otherwise
+// function will panic.
+func (p *Pool[T]) PutPlanar(b *Planar[T]) {
+	mustSame(p.bitDepth, b.BitDepth(), diffBitDepth)
+	mustSame(capacityClass(b.Capacity()), b.Capacity(), diffCapacity)
+	for c := range b.data {
+		b.data[c] = b.data[c][:cap(b.data[c])]
+	}
+	b.clear()
+	p.pool(true, Allocator{Channels: b.Channels(), Capacity: b.Capacity()}).Put(b)
+}
+
+// pool returns sync.Pool of Buffer or Planar buffers of the allocator
+// class. It's created on the first use.
+func (p *Pool[T]) pool(planar bool, a Allocator) *sync.Pool {
+	k := poolKey{
+		channels: a.Channels,
+		capacity: capacityClass(max(a.Capacity, a.Length)),
+	}
+	pools := p.buffers
+	if planar {
+		pools = p.planars
+	}
+	p.mu.RLock()
+	sp, ok := pools[k]
+	p.mu.RUnlock()
+	if ok {
+		return sp
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if sp, ok := pools[k]; ok {
+		return sp
+	}
+	ka := Allocator{Channels: k.channels, Capacity: k.capacity}
+	sp = &sync.Pool{
+		New: func() any {
+			if planar {
+				return allocPlanar[T](ka, p.bitDepth)
+			}
+			return alloc[T](ka, p.bitDepth)
+		},
+	}
+	pools[k] = sp
+	return sp
+}
+
+// capacityClass returns the capacity class, which is the closest power of
+// two that is not less than capacity.
+func capacityClass(capacity int) int {
+	if capacity <= 0 {
+		return 0
+	}
+	return 1 << bits.Len(uint(capacity-1))
+}
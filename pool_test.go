@@ -1,6 +1,7 @@
 package signal_test
 
 import (
+	"sync"
 	"testing"
 
 	"golang.org/x/exp/constraints"
@@ -18,7 +19,7 @@ func TestPool(t *testing.T) {
 	testOk := func(t *testing.T, allocs int, channels, length, capacity int) func(t *testing.T) {
 		return func(t *testing.T) {
 			t.Helper()
-			alloc := signal.Allocator{channels, length, capacity}
+			alloc := signal.Allocator{Channels: channels, Length: length, Capacity: capacity}
 			for i := 0; i < allocs; i++ {
 				// floating
 				fp := signal.PoolAlloc[float64](alloc)
@@ -89,3 +90,97 @@ func assertAllocation[T signal.SignalTypes](t *testing.T, s *signal.Buffer[T], e
 		t.Fatalf("Invalid Buffer capacity: %v expected: %v", s.Capacity(), e.capacity)
 	}
 }
+
+func TestMultiSizePool(t *testing.T) {
+	testOk := func(a signal.Allocator, capacity int) func(*testing.T) {
+		return func(t *testing.T) {
+			t.Helper()
+			p := signal.NewPool[int16]()
+			for i := 0; i < 3; i++ {
+				b := p.Get(a)
+				assertEqual(t, "channels", b.Channels(), a.Channels)
+				assertEqual(t, "length", b.Length(), a.Length)
+				assertEqual(t, "capacity", b.Capacity(), capacity)
+				assertEqual(t, "sample rate", b.SampleRate(), a.SampleRate)
+				assertEqual(t, "bit depth", b.BitDepth(), signal.BitDepth16)
+				for j := 0; j < b.Len(); j++ {
+					assertEqual(t, "cleared", b.Sample(j), int16(0))
+					b.SetSample(j, 1)
+				}
+				// buffer is returned after slicing.
+				p.Put(b.Slice(0, 1))
+
+				pl := p.GetPlanar(a)
+				assertEqual(t, "planar length", pl.Length(), a.Length)
+				assertEqual(t, "planar capacity", pl.Capacity(), capacity)
+				for c := 0; c < pl.Channels(); c++ {
+					assertEqual(t, "planar cleared", pl.Channel(c), make([]int16, a.Length))
+					pl.Channel(c)[0] = 1
+				}
+				p.PutPlanar(pl)
+			}
+		}
+	}
+	t.Run("exact class", testOk(signal.Allocator{Channels: 2, Length: 256, Capacity: 256, SampleRate: 44100}, 256))
+	t.Run("rounded up", testOk(signal.Allocator{Channels: 1, Length: 100, Capacity: 300}, 512))
+	t.Run("length only", testOk(signal.Allocator{Channels: 6, Length: 33}, 64))
+	t.Run("single sample", testOk(signal.Allocator{Channels: 3, Length: 1, Capacity: 1}, 1))
+
+	t.Run("shapes", func(t *testing.T) {
+		// buffers of different shapes are served at the same time.
+		p := signal.NewPool[float32]()
+		var issued []*signal.Buffer[float32]
+		for channels := 1; channels <= 8; channels++ {
+			for length := 1; length <= 4096; length *= 3 {
+				b := p.Get(signal.Allocator{Channels: channels, Length: length, Capacity: length})
+				assertEqual(t, "channels", b.Channels(), channels)
+				assertEqual(t, "length", b.Length(), length)
+				issued = append(issued, b)
+			}
+		}
+		for _, b := range issued {
+			assertNoError(t, p.TryPut(b))
+		}
+	})
+
+	t.Run("bit depth", func(t *testing.T) {
+		p := signal.NewPoolBitDepth[int32](signal.BitDepth24)
+		b := p.Get(signal.Allocator{Channels: 2, Length: 10, Capacity: 10})
+		assertEqual(t, "bit depth", b.BitDepth(), signal.BitDepth24)
+		p.Put(b)
+		assertPanic(t, func() {
+			signal.NewPoolBitDepth[int16](signal.BitDepth24)
+		})
+	})
+
+	t.Run("foreign", func(t *testing.T) {
+		p := signal.NewPool[int16]()
+		assertPanic(t, func() {
+			p.Put(signal.Alloc[int16](signal.Allocator{Channels: 2, Capacity: 100}))
+		})
+		assertPanic(t, func() {
+			p.PutPlanar(signal.AllocPlanarBitDepth[int16](signal.Allocator{Channels: 2, Capacity: 128}, signal.BitDepth8))
+		})
+		assertEqual(t, "capacity", p.TryPut(signal.Alloc[int16](signal.Allocator{Channels: 2, Capacity: 100})), signal.ErrCapacity)
+		assertEqual(t, "bit depth", p.TryPutPlanar(signal.AllocPlanarBitDepth[int16](signal.Allocator{Channels: 2, Capacity: 128}, signal.BitDepth8)), signal.ErrBitDepth)
+		// buffer of the class capacity can be put even if it wasn't issued.
+		assertNoError(t, p.TryPut(signal.Alloc[int16](signal.Allocator{Channels: 2, Capacity: 128})))
+	})
+
+	t.Run("concurrent", func(t *testing.T) {
+		p := signal.NewPool[float64]()
+		var wg sync.WaitGroup
+		for i := 0; i < 8; i++ {
+			wg.Add(1)
+			go func(channels int) {
+				defer wg.Done()
+				for j := 1; j < 100; j++ {
+					a := signal.Allocator{Channels: channels, Length: j, Capacity: j}
+					p.Put(p.Get(a))
+					p.PutPlanar(p.GetPlanar(a))
+				}
+			}(i%3 + 1)
+		}
+		wg.Wait()
+	})
+}
@@ -0,0 +1,121 @@
+package signal
+
+// Reframer accepts buffers of arbitrary length and splits the signal into
+// frames of fixed length. Consecutive frames start hop samples apart, so
+// frames overlap if hop is less than frame length. Reframer is not safe
+// for concurrent use.
+type Reframer[T SignalTypes] struct {
+	channels
+	bitDepth
+	sampleRate
+	length int
+	hop    int
+	alloc  Allocator
+	pool   *PoolAllocator[T]
+	// data contains interleaved samples of the next frames, covered is a
+	// number of leading samples per channel that were already emitted.
+	data    []T
+	covered int
+}
+
+// NewReframer returns Reframer that emits frames allocated with provided
+// Allocator. Frame length is the Length of Allocator, hop must be in
+// range [1, Length], otherwise function will panic. Frames have the
+// bit depth of written buffers.
+func NewReframer[T SignalTypes](a Allocator, hop int) *Reframer[T] {
+	if hop <= 0 || hop > a.Length {
+		panic(hopOutOfRange)
+	}
+	if a.Capacity < a.Length {
+		a.Capacity = a.Length
+	}
+	return &Reframer[T]{
+		channels:   channels(a.Channels),
+		bitDepth:   bitDepth(getBitDepth[T]()),
+		sampleRate: sampleRate(a.SampleRate),
+		length:     a.Length,
+		hop:        hop,
+		alloc:      a,
+		data:       make([]T, 0, a.Channels*a.Length),
+	}
+}
+
+// NewPoolReframer returns Reframer that emits frames drawn from the pool.
+// Frame length is the Length of pool Allocator, hop must be in range [1,
+// Length], otherwise function will panic. Written buffers must have the
+// bit depth of the pool.
+func NewPoolReframer[T SignalTypes](p *PoolAllocator[T], hop int) *Reframer[T] {
+	r := NewReframer[T](p.alloc, hop)
+	r.bitDepth = bitDepth(p.bitDepth)
+	r.pool = p
+	return r
+}
+
+// Write appends [0:Length] samples of the src Buffer to the Reframer.
+// Buffer must have the same number of channels and sample rate and
+// contain only whole frames, otherwise function will panic.
+func (r *Reframer[T]) Write(src *Buffer[T]) {
+	mustSame(r.Channels(), src.Channels(), diffChannels)
+	mustWholeFrames(src.Len(), src.Channels())
+	mustSame(r.SampleRate(), src.SampleRate(), diffSampleRate)
+	switch {
+	case r.pool != nil:
+		mustSame(r.BitDepth(), src.BitDepth(), diffBitDepth)
+	case len(r.data) == 0:
+		r.bitDepth = src.bitDepth
+	default:
+		mustSame(r.BitDepth(), src.BitDepth(), diffBitDepth)
+	}
+	r.data = append(r.data, src.data...)
+}
+
+// Frame returns the next frame if enough samples were written. Frames
+// drawn from the pool should be put back by the caller.
+func (r *Reframer[T]) Frame() (*Buffer[T], bool) {
+	size := r.length * r.Channels()
+	if len(r.data) < size {
+		return nil, false
+	}
+	var frame *Buffer[T]
+	if r.pool != nil {
+		frame = r.pool.Get()
+		frame.data = frame.data[:size]
+	} else {
+		frame = alloc[T](r.alloc, r.BitDepth())
+	}
+	copy(frame.data, r.data)
+	r.data = r.data[:copy(r.data, r.data[r.hop*r.Channels():])]
+	r.covered = r.length - r.hop
+	return frame, true
+}
+
+// Flush pads the written signal with silence, so all samples that weren't
+// emitted yet are returned by Frame. Silence of unsigned types is the
+// middle of the range. It should be called when the stream ends.
+func (r *Reframer[T]) Flush() {
+	pending := len(r.data) / r.Channels()
+	if pending <= r.covered {
+		return
+	}
+	// start of the last frame that contains the last sample.
+	var last int
+	if pending > r.length {
+		last = (pending - r.length + r.hop - 1) / r.hop * r.hop
+	}
+	var silence, zero T
+	if zero-1 > 0 {
+		silence = T(uint64(r.BitDepth().MaxSignedValue()) + 1)
+	}
+	for i := pending; i < last+r.length; i++ {
+		for c := 0; c < r.Channels(); c++ {
+			r.data = append(r.data, silence)
+		}
+	}
+}
+
+// Reset discards written samples, so Reframer can be used for a new
+// stream.
+func (r *Reframer[T]) Reset() {
+	r.data = r.data[:0]
+	r.covered = 0
+}
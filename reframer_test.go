@@ -0,0 +1,109 @@
+package signal_test
+
+import (
+	"testing"
+
+	"pipelined.dev/signal"
+)
+
+func TestReframer(t *testing.T) {
+	buffer := func(s ...int16) *signal.Buffer[int16] {
+		b := signal.Alloc[int16](signal.Allocator{Channels: 2, Length: len(s) / 2, Capacity: len(s) / 2})
+		signal.Write(s, b)
+		return b
+	}
+	frames := func(r *signal.Reframer[int16]) [][]int16 {
+		var result [][]int16
+		for f, ok := r.Frame(); ok; f, ok = r.Frame() {
+			assertEqual(t, "length", f.Length(), 4)
+			result = append(result, samples(f))
+		}
+		return result
+	}
+
+	t.Run("no overlap", func(t *testing.T) {
+		r := signal.NewReframer[int16](signal.Allocator{Channels: 2, Length: 4}, 4)
+		r.Write(buffer(1, 1, 2, 2, 3, 3))
+		assertEqual(t, "not enough", frames(r), [][]int16(nil))
+		r.Write(buffer(4, 4, 5, 5))
+		assertEqual(t, "frames", frames(r), [][]int16{{1, 1, 2, 2, 3, 3, 4, 4}})
+		r.Flush()
+		assertEqual(t, "flushed", frames(r), [][]int16{{5, 5, 0, 0, 0, 0, 0, 0}})
+		r.Flush()
+		assertEqual(t, "flushed twice", frames(r), [][]int16(nil))
+	})
+	t.Run("overlap", func(t *testing.T) {
+		r := signal.NewReframer[int16](signal.Allocator{Channels: 2, Length: 4}, 2)
+		r.Write(buffer(1, 1, 2, 2, 3, 3, 4, 4, 5, 5, 6, 6, 7, 7))
+		assertEqual(t, "frames", frames(r), [][]int16{
+			{1, 1, 2, 2, 3, 3, 4, 4},
+			{3, 3, 4, 4, 5, 5, 6, 6},
+		})
+		r.Flush()
+		assertEqual(t, "flushed", frames(r), [][]int16{
+			{5, 5, 6, 6, 7, 7, 0, 0},
+		})
+		r.Reset()
+		r.Write(buffer(8, 8))
+		r.Flush()
+		assertEqual(t, "reset", frames(r), [][]int16{
+			{8, 8, 0, 0, 0, 0, 0, 0},
+		})
+	})
+	t.Run("pool", func(t *testing.T) {
+		pool := signal.PoolAllocBitDepth[int16](signal.Allocator{Channels: 2, Length: 4, Capacity: 4}, signal.BitDepth8)
+		r := signal.NewPoolReframer(&pool, 3)
+		src := signal.AllocBitDepth[int16](signal.Allocator{Channels: 2, Length: 7, Capacity: 7}, signal.BitDepth8)
+		signal.Write([]int16{1, 1, 2, 2, 3, 3, 4, 4, 5, 5, 6, 6, 7, 7}, src)
+		r.Write(src)
+		var result [][]int16
+		for f, ok := r.Frame(); ok; f, ok = r.Frame() {
+			assertEqual(t, "bit depth", f.BitDepth(), signal.BitDepth8)
+			result = append(result, samples(f))
+			pool.Put(f)
+		}
+		assertEqual(t, "frames", result, [][]int16{
+			{1, 1, 2, 2, 3, 3, 4, 4},
+			{4, 4, 5, 5, 6, 6, 7, 7},
+		})
+		assertPanic(t, func() {
+			r.Write(buffer(1, 1))
+		})
+	})
+	t.Run("bit depth", func(t *testing.T) {
+		r := signal.NewReframer[int16](signal.Allocator{Channels: 2, Length: 1}, 1)
+		src := signal.AllocBitDepth[int16](signal.Allocator{Channels: 2, Length: 1, Capacity: 1}, signal.BitDepth8)
+		r.Write(src)
+		f, _ := r.Frame()
+		assertEqual(t, "bit depth", f.BitDepth(), signal.BitDepth8)
+	})
+	t.Run("panic", func(t *testing.T) {
+		assertPanic(t, func() {
+			signal.NewReframer[int16](signal.Allocator{Channels: 2, Length: 4}, 5)
+		})
+		assertPanic(t, func() {
+			signal.NewReframer[int16](signal.Allocator{Channels: 2, Length: 4}, 0)
+		})
+		r := signal.NewReframer[int16](signal.Allocator{Channels: 2, Length: 4, SampleRate: 44100}, 4)
+		assertPanic(t, func() {
+			r.Write(buffer(1, 1))
+		})
+		partial := signal.Alloc[int16](signal.Allocator{Channels: 2, Capacity: 2, SampleRate: 44100})
+		for i := 0; i < 3; i++ {
+			partial.AppendSample(1)
+		}
+		assertPanicMessage(t, func() {
+			r.Write(partial)
+		}, "buffer length is not a multiple of channels")
+	})
+	t.Run("unsigned silence", func(t *testing.T) {
+		r := signal.NewReframer[uint8](signal.Allocator{Channels: 1, Length: 4}, 4)
+		src := signal.Alloc[uint8](signal.Allocator{Channels: 1, Length: 2, Capacity: 2})
+		signal.Write([]uint8{1, 2}, src)
+		r.Write(src)
+		r.Flush()
+		f, ok := r.Frame()
+		assertEqual(t, "flushed", ok, true)
+		assertEqual(t, "frame", samples(f), []uint8{1, 2, 128, 128})
+	})
+}
@@ -0,0 +1,360 @@
+// Package resample provides streaming sample-rate conversion of signal
+// buffers.
+//
+// Resampler converts between any two integer sample rates using a
+// polyphase filter. Input is written to the Resampler and converted
+// output is read from it, filter state is kept between successive calls:
+//
+//	r, err := resample.New[float64](2, 44100, 48000, resample.Options{})
+//	if err != nil {
+//		return err
+//	}
+//	out := signal.Alloc[float64](signal.Allocator{
+//		Channels:   2,
+//		Length:     1024,
+//		Capacity:   1024,
+//		SampleRate: 48000,
+//	})
+//	r.Write(in)
+//	for n := r.Read(out); n > 0; n = r.Read(out) {
+//		// process out.Slice(0, n)
+//	}
+//
+// Output is delayed by Latency samples. When the stream ends, Flush makes
+// the remaining output available.
+package resample
+
+import (
+	"errors"
+	"math"
+
+	"pipelined.dev/signal"
+	"pipelined.dev/signal/window"
+)
+
+var (
+	// ErrSampleRate is returned when sample rate is not a positive
+	// integer value.
+	ErrSampleRate = errors.New("resample: invalid sample rate")
+	// ErrChannels is returned when number of channels is not positive.
+	ErrChannels = errors.New("resample: invalid number of channels")
+	// ErrQuality is returned when quality is not supported.
+	ErrQuality = errors.New("resample: unknown quality")
+	// ErrTaps is returned when number of taps is not a positive even
+	// value.
+	ErrTaps = errors.New("resample: invalid number of taps")
+)
+
+// panic messages.
+const (
+	diffChannels   = "resample: different number of channels"
+	diffSampleRate = "resample: different sample rate"
+)
+
+// DefaultTaps is a number of taps used by Sinc quality if it's not
+// provided in Options.
+const DefaultTaps = 64
+
+// attenuation is a stop-band attenuation in dB of the windowed-sinc
+// filter.
+const attenuation = 80.0
+
+// maxTable limits the size of precomputed polyphase table. If the number
+// of phases multiplied by the number of taps exceeds it, coefficients are
+// computed for every output sample.
+const maxTable = 1 << 17
+
+// Quality defines the interpolation method of the Resampler.
+type Quality int
+
+const (
+	// Sinc is a band-limited interpolation with Kaiser-windowed sinc
+	// filter. It's the only method that suppresses aliasing when the
+	// sample rate is decreased.
+	Sinc Quality = iota
+	// Cubic is a Catmull-Rom spline interpolation.
+	Cubic
+	// Linear is a linear interpolation.
+	Linear
+)
+
+// Options of the Resampler.
+type Options struct {
+	Quality Quality
+	// Taps is a number of filter taps for Sinc quality at unity ratio.
+	// It's scaled up when the sample rate is decreased. DefaultTaps is
+	// used if it's zero.
+	Taps int
+}
+
+// Resampler converts the sample rate of the signal. Samples of all types
+// are processed in float64 precision and fixed-point output is rounded
+// and clipped to the bit depth of the destination Buffer. Unsigned
+// samples are centered around the midpoint of their range, so the filter
+// is primed and flushed with silence for all types.
+type Resampler[T signal.SignalTypes] struct {
+	channels int
+	in, out  signal.Frequency
+	up, down int
+	taps     int
+	half     int
+	kernel   func(float64) float64
+	table    [][]float64
+	scratch  []float64
+	// history contains interleaved input samples, pos and phase define
+	// the position of next output sample in it.
+	history []float64
+	pos     int
+	phase   int
+}
+
+// New returns a Resampler that converts signal with provided number of
+// channels from in to out sample rate.
+func New[T signal.SignalTypes](channels int, in, out signal.Frequency, opts Options) (*Resampler[T], error) {
+	if channels <= 0 {
+		return nil, ErrChannels
+	}
+	if !validRate(in) || !validRate(out) {
+		return nil, ErrSampleRate
+	}
+	g := gcd(int64(in), int64(out))
+	r := Resampler[T]{
+		channels: channels,
+		in:       in,
+		out:      out,
+		up:       int(int64(out) / g),
+		down:     int(int64(in) / g),
+	}
+	switch opts.Quality {
+	case Linear:
+		r.taps, r.kernel = 2, linear
+	case Cubic:
+		r.taps, r.kernel = 4, cubic
+	case Sinc:
+		taps := opts.Taps
+		if taps == 0 {
+			taps = DefaultTaps
+		}
+		if taps < 0 || taps%2 != 0 {
+			return nil, ErrTaps
+		}
+		r.taps, r.kernel = sinc(taps, float64(r.up)/float64(r.down))
+	default:
+		return nil, ErrQuality
+	}
+	r.half = r.taps / 2
+	if r.up*r.taps <= maxTable {
+		r.table = make([][]float64, r.up)
+		for p := range r.table {
+			r.table[p] = make([]float64, r.taps)
+			r.coefficients(p, r.table[p])
+		}
+	} else {
+		r.scratch = make([]float64, r.taps)
+	}
+	r.Reset()
+	return &r, nil
+}
+
+// Latency returns a delay of output signal in output samples.
+func (r *Resampler[T]) Latency() int {
+	return r.half * r.up / r.down
+}
+
+// Available returns a number of output samples per channel that can be
+// read without writing more input.
+func (r *Resampler[T]) Available() int {
+	last := len(r.history)/r.channels - r.taps
+	if last < r.pos {
+		return 0
+	}
+	// count k such that pos + (phase + k*down)/up <= last.
+	return ((last-r.pos+1)*r.up-r.phase-1)/r.down + 1
+}
+
+// Write appends [0:Length] samples of the src Buffer to the input of
+// Resampler. Buffer must have the same number of channels and, if it's
+// set, input sample rate, otherwise function will panic.
+func (r *Resampler[T]) Write(src *signal.Buffer[T]) {
+	mustSame(r.channels, src.Channels(), diffChannels)
+	if sr := src.SampleRate(); sr != 0 {
+		mustSame(r.in, sr, diffSampleRate)
+	}
+	// unsigned samples are centered, so zero is silence for all types.
+	offset := center[T](src.BitDepth())
+	for i := 0; i < src.Len(); i++ {
+		r.history = append(r.history, float64(src.Sample(i))-offset)
+	}
+}
+
+// Read writes available output into [0:Length] of the dst Buffer. Buffer
+// must have the same number of channels and, if it's set, output sample
+// rate, otherwise function will panic. Returns a number of samples
+// written per channel.
+func (r *Resampler[T]) Read(dst *signal.Buffer[T]) int {
+	mustSame(r.channels, dst.Channels(), diffChannels)
+	if sr := dst.SampleRate(); sr != 0 {
+		mustSame(r.out, sr, diffSampleRate)
+	}
+	clip := clipper[T](dst.BitDepth())
+	offset := center[T](dst.BitDepth())
+	n := min(r.Available(), dst.Length())
+	for i := 0; i < n; i++ {
+		coefs := r.scratch
+		if r.table != nil {
+			coefs = r.table[r.phase]
+		} else {
+			r.coefficients(r.phase, coefs)
+		}
+		window := r.history[r.pos*r.channels:]
+		for c := 0; c < r.channels; c++ {
+			var v float64
+			for j, coef := range coefs {
+				v += window[j*r.channels+c] * coef
+			}
+			dst.SetSample(i*r.channels+c, T(clip(v+offset)))
+		}
+		r.phase += r.down
+		r.pos += r.phase / r.up
+		r.phase %= r.up
+	}
+	// drop consumed input.
+	consumed := r.pos * r.channels
+	r.history = r.history[:copy(r.history, r.history[consumed:])]
+	r.pos = 0
+	return n
+}
+
+// Flush writes the silence to the input, so the output for all written
+// samples becomes available. It should be called when the stream ends.
+func (r *Resampler[T]) Flush() {
+	for i := 0; i < r.half*r.channels; i++ {
+		r.history = append(r.history, 0)
+	}
+}
+
+// Reset discards the filter state, so Resampler can be used for a new
+// stream.
+func (r *Resampler[T]) Reset() {
+	// filter is primed with silence, so output starts immediately.
+	r.history = r.history[:0]
+	for i := 0; i < (r.taps-1)*r.channels; i++ {
+		r.history = append(r.history, 0)
+	}
+	// filter delays signal by half of taps, initial position is shifted
+	// to make the delay a whole number of output samples.
+	offset := r.half*r.up - r.Latency()*r.down
+	r.pos = offset / r.up
+	r.phase = offset % r.up
+}
+
+// coefficients computes filter coefficients for the phase. Coefficients
+// are normalized to have unity gain at DC.
+func (r *Resampler[T]) coefficients(phase int, coefs []float64) {
+	frac := float64(phase) / float64(r.up)
+	var sum float64
+	for j := range coefs {
+		coefs[j] = r.kernel(float64(j-r.half+1) - frac)
+		sum += coefs[j]
+	}
+	if sum == 0 {
+		return
+	}
+	for j := range coefs {
+		coefs[j] /= sum
+	}
+}
+
+func linear(x float64) float64 {
+	x = math.Abs(x)
+	if x >= 1 {
+		return 0
+	}
+	return 1 - x
+}
+
+// cubic is a Keys kernel with a = -0.5.
+func cubic(x float64) float64 {
+	const a = -0.5
+	x = math.Abs(x)
+	switch {
+	case x <= 1:
+		return ((a+2)*x-(a+3))*x*x + 1
+	case x < 2:
+		return ((a*x-5*a)*x+8*a)*x - 4*a
+	}
+	return 0
+}
+
+// sinc returns a number of taps and Kaiser-windowed sinc kernel for the
+// conversion ratio. Cut-off frequency is placed so the transition band
+// ends at the Nyquist frequency of the lower sample rate.
+func sinc(taps int, ratio float64) (int, func(float64) float64) {
+	scale := math.Min(1, ratio)
+	// transition width in cycles per sample.
+	width := (attenuation - 8) / (2.285 * 2 * math.Pi * float64(taps))
+	cutoff := scale * (1 - width)
+	// number of taps grows with decimation to keep the transition width.
+	taps = int(math.Ceil(float64(taps)/scale/2)) * 2
+	half := float64(taps / 2)
+	beta := 0.1102 * (attenuation - 8.7)
+	norm := window.BesselI0(beta)
+	return taps, func(x float64) float64 {
+		w := x / half
+		if w <= -1 || w >= 1 {
+			return 0
+		}
+		v := cutoff
+		if x != 0 {
+			v = math.Sin(math.Pi*cutoff*x) / (math.Pi * x)
+		}
+		return v * window.BesselI0(beta*math.Sqrt(1-w*w)) / norm
+	}
+}
+
+// center returns the value of silence for the type and bit depth. It's
+// the midpoint of the range for unsigned types and zero otherwise.
+func center[T signal.SignalTypes](bd signal.BitDepth) float64 {
+	var zero T
+	if zero-1 > 0 {
+		return float64(bd.MaxSignedValue()) + 1
+	}
+	return 0
+}
+
+// clipper returns a function that rounds and clips values for the type
+// and bit depth. Floating-point values are not clipped.
+func clipper[T signal.SignalTypes](bd signal.BitDepth) func(float64) float64 {
+	var one T = 1
+	if one/2 != 0 {
+		return func(v float64) float64 { return v }
+	}
+	var zero T
+	lo, hi := float64(bd.MinSignedValue()), float64(bd.MaxSignedValue())
+	if zero-1 > 0 {
+		lo, hi = 0, float64(bd.MaxUnsignedValue())
+	}
+	// the largest value of 64 bits is not representable by float64 and
+	// must not overflow on conversion.
+	hi = math.Min(hi, math.Nextafter(hi+1, 0))
+	return func(v float64) float64 {
+		return math.Max(lo, math.Min(hi, math.Round(v)))
+	}
+}
+
+func validRate(f signal.Frequency) bool {
+	return f > 0 && f == signal.Frequency(math.Trunc(float64(f))) && f < math.MaxInt32
+}
+
+func gcd(a, b int64) int64 {
+	for b != 0 {
+		a, b = b, a%b
+	}
+	return a
+}
+
+func mustSame[T comparable](a, b T, panicStr string) {
+	if a != b {
+		panic(panicStr)
+	}
+}
@@ -0,0 +1,280 @@
+package resample_test
+
+import (
+	"math"
+	"reflect"
+	"testing"
+
+	"pipelined.dev/signal"
+	"pipelined.dev/signal/resample"
+)
+
+func TestNew(t *testing.T) {
+	testError := func(channels int, in, out signal.Frequency, opts resample.Options, expected error) func(*testing.T) {
+		return func(t *testing.T) {
+			t.Helper()
+			_, err := resample.New[float64](channels, in, out, opts)
+			assertEqual(t, "error", err, expected)
+		}
+	}
+	t.Run("ok", testError(2, 44100, 48000, resample.Options{}, nil))
+	t.Run("channels", testError(0, 44100, 48000, resample.Options{}, resample.ErrChannels))
+	t.Run("zero rate", testError(1, 0, 48000, resample.Options{}, resample.ErrSampleRate))
+	t.Run("fractional rate", testError(1, 44100.5, 48000, resample.Options{}, resample.ErrSampleRate))
+	t.Run("quality", testError(1, 44100, 48000, resample.Options{Quality: 10}, resample.ErrQuality))
+	t.Run("odd taps", testError(1, 44100, 48000, resample.Options{Taps: 31}, resample.ErrTaps))
+}
+
+func TestLinear(t *testing.T) {
+	r, err := resample.New[float64](1, 1, 2, resample.Options{Quality: resample.Linear})
+	assertNoError(t, err)
+	assertEqual(t, "latency", r.Latency(), 2)
+	in := buffer[float64](1, 1, 0, 1, 2, 3)
+	out := buffer[float64](2, 1, make([]float64, 10)...)
+	r.Write(in)
+	r.Flush()
+	n := r.Read(out)
+	assertEqual(t, "length", n, 10)
+	assertEqual(t, "samples", samples(out), []float64{0, 0, 0, 0.5, 1, 1.5, 2, 2.5, 3, 1.5})
+}
+
+func TestSine(t *testing.T) {
+	testOk := func(in, out signal.Frequency, opts resample.Options, freq, tolerance float64) func(*testing.T) {
+		return func(t *testing.T) {
+			t.Helper()
+			const channels = 2
+			input := sine(channels, in, freq, int(in)/10)
+			r, err := resample.New[float64](channels, in, out, opts)
+			assertNoError(t, err)
+			r.Write(input)
+			r.Flush()
+			output := signal.Alloc[float64](signal.Allocator{
+				Channels:   channels,
+				Length:     r.Available(),
+				Capacity:   r.Available(),
+				SampleRate: out,
+			})
+			n := r.Read(output)
+			assertEqual(t, "available", r.Available(), 0)
+			latency := r.Latency()
+			if expected := int(math.Ceil(float64(input.Length()) * float64(out) / float64(in))); n-latency < expected-1 || n-latency > expected+1 {
+				t.Fatalf("invalid length: %d expected: %d", n-latency, expected)
+			}
+			// skip edges that are affected by the start and end of signal.
+			edge := int(out) / 100
+			var maxErr float64
+			for i := latency + edge; i < n-edge; i++ {
+				expected := math.Sin(2 * math.Pi * freq * float64(i-latency) / float64(out))
+				for c := 0; c < channels; c++ {
+					maxErr = math.Max(maxErr, math.Abs(output.Sample(i*channels+c)-expected))
+				}
+			}
+			if maxErr > tolerance {
+				t.Fatalf("error %v exceeds tolerance %v", maxErr, tolerance)
+			}
+		}
+	}
+	t.Run("sinc up", testOk(44100, 48000, resample.Options{}, 1000, 1e-3))
+	t.Run("sinc down", testOk(48000, 44100, resample.Options{}, 1000, 1e-3))
+	t.Run("sinc taps", testOk(48000, 32000, resample.Options{Taps: 128}, 5000, 1e-3))
+	t.Run("sinc 2x", testOk(22050, 44100, resample.Options{}, 3000, 1e-3))
+	t.Run("cubic", testOk(44100, 48000, resample.Options{Quality: resample.Cubic}, 1000, 1e-2))
+	t.Run("linear", testOk(44100, 48000, resample.Options{Quality: resample.Linear}, 1000, 1e-2))
+}
+
+func TestAntiAliasing(t *testing.T) {
+	const in, out = 48000, 8000
+	// tone above the output Nyquist frequency.
+	input := sine(1, in, 6000, in/10)
+	r, err := resample.New[float64](1, in, out, resample.Options{})
+	assertNoError(t, err)
+	r.Write(input)
+	r.Flush()
+	output := signal.Alloc[float64](signal.Allocator{Channels: 1, Length: r.Available(), Capacity: r.Available()})
+	n := r.Read(output)
+	var peak float64
+	for i := r.Latency() * 2; i < n-r.Latency()*2; i++ {
+		peak = math.Max(peak, math.Abs(output.Sample(i)))
+	}
+	if peak > 1e-3 {
+		t.Fatalf("aliased tone peak: %v", peak)
+	}
+}
+
+func TestStreaming(t *testing.T) {
+	const (
+		channels = 2
+		in       = 44100
+		out      = 48000
+	)
+	input := sine(channels, in, 440, 4410)
+
+	whole, err := resample.New[float32](channels, in, out, resample.Options{})
+	assertNoError(t, err)
+	whole.Write(convert[float32](input))
+	whole.Flush()
+	expected := signal.Alloc[float32](signal.Allocator{Channels: channels, Length: whole.Available(), Capacity: whole.Available()})
+	whole.Read(expected)
+
+	chunked, err := resample.New[float32](channels, in, out, resample.Options{})
+	assertNoError(t, err)
+	result := signal.Alloc[float32](signal.Allocator{Channels: channels, Capacity: expected.Length()})
+	chunk := signal.Alloc[float32](signal.Allocator{Channels: channels, Length: 100, Capacity: 100})
+	for i := 0; i < input.Length(); i += 333 {
+		chunked.Write(convert[float32](input.Slice(i, min(i+333, input.Length()))))
+		for n := chunked.Read(chunk); n > 0; n = chunked.Read(chunk) {
+			result.Append(chunk.Slice(0, n))
+		}
+	}
+	chunked.Flush()
+	for n := chunked.Read(chunk); n > 0; n = chunked.Read(chunk) {
+		result.Append(chunk.Slice(0, n))
+	}
+	assertEqual(t, "samples", samples(result), samples(expected))
+
+	chunked.Reset()
+	chunked.Write(convert[float32](input))
+	chunked.Flush()
+	assertEqual(t, "available after reset", chunked.Available(), expected.Length())
+}
+
+func TestInteger(t *testing.T) {
+	r, err := resample.New[int16](1, 1, 2, resample.Options{Quality: resample.Cubic})
+	assertNoError(t, err)
+	in := signal.AllocBitDepth[int16](signal.Allocator{Channels: 1, Length: 4, Capacity: 4}, signal.BitDepth8)
+	signal.Write([]int16{127, -128, 127, -128}, in)
+	r.Write(in)
+	r.Flush()
+	out := signal.AllocBitDepth[int16](signal.Allocator{Channels: 1, Length: r.Available(), Capacity: r.Available()}, signal.BitDepth8)
+	n := r.Read(out)
+	for i := 0; i < n; i++ {
+		if v := out.Sample(i); v > 127 || v < -128 {
+			t.Fatalf("sample %d is not clipped: %d", i, v)
+		}
+	}
+}
+
+func TestUnsigned(t *testing.T) {
+	// constant midpoint is silence, it must not be affected by the
+	// filter history at the start and the end of the stream.
+	r, err := resample.New[uint8](1, 2, 3, resample.Options{})
+	assertNoError(t, err)
+	in := make([]uint8, 100)
+	for i := range in {
+		in[i] = 128
+	}
+	r.Write(buffer(2, 1, in...))
+	r.Flush()
+	out := signal.Alloc[uint8](signal.Allocator{Channels: 1, Length: r.Available(), Capacity: r.Available()})
+	n := r.Read(out)
+	for i, v := range samples(out)[:n] {
+		if v != 128 {
+			t.Fatalf("sample %d: %d expected: 128", i, v)
+		}
+	}
+}
+
+func TestFullScale(t *testing.T) {
+	t.Run("int64", testFullScale[int64](math.MaxInt64))
+	t.Run("uint64", testFullScale[uint64](math.MaxUint64))
+}
+
+// testFullScale checks that constant full-scale signal doesn't overflow
+// when converted back from floating-point.
+func testFullScale[T int64 | uint64](v T) func(*testing.T) {
+	return func(t *testing.T) {
+		t.Helper()
+		r, err := resample.New[T](1, 2, 3, resample.Options{})
+		assertNoError(t, err)
+		in := make([]T, 200)
+		for i := range in {
+			in[i] = v
+		}
+		r.Write(buffer(2, 1, in...))
+		out := signal.Alloc[T](signal.Allocator{Channels: 1, Length: r.Available(), Capacity: r.Available()})
+		n := r.Read(out)
+		// skip the transition from the silent history.
+		for i, s := range samples(out)[2*r.Latency() : n] {
+			if s < v/2 {
+				t.Fatalf("sample %d: %d expected: %d", i, s, v)
+			}
+		}
+	}
+}
+
+func TestMismatch(t *testing.T) {
+	r, err := resample.New[float64](2, 44100, 48000, resample.Options{})
+	assertNoError(t, err)
+	assertPanic(t, func() {
+		r.Write(signal.Alloc[float64](signal.Allocator{Channels: 1, Length: 1, Capacity: 1}))
+	})
+	assertPanic(t, func() {
+		r.Write(signal.Alloc[float64](signal.Allocator{Channels: 2, Length: 1, Capacity: 1, SampleRate: 48000}))
+	})
+	assertPanic(t, func() {
+		r.Read(signal.Alloc[float64](signal.Allocator{Channels: 2, Length: 1, Capacity: 1, SampleRate: 44100}))
+	})
+}
+
+func sine(channels int, sampleRate signal.Frequency, freq float64, length int) *signal.Buffer[float64] {
+	b := signal.Alloc[float64](signal.Allocator{
+		Channels:   channels,
+		Length:     length,
+		Capacity:   length,
+		SampleRate: sampleRate,
+	})
+	for i := 0; i < length; i++ {
+		v := math.Sin(2 * math.Pi * freq * float64(i) / float64(sampleRate))
+		for c := 0; c < channels; c++ {
+			b.SetSample(i*channels+c, v)
+		}
+	}
+	return b
+}
+
+func buffer[T signal.SignalTypes](sampleRate signal.Frequency, channels int, s ...T) *signal.Buffer[T] {
+	b := signal.Alloc[T](signal.Allocator{
+		Channels:   channels,
+		Length:     len(s) / channels,
+		Capacity:   len(s) / channels,
+		SampleRate: sampleRate,
+	})
+	signal.Write(s, b)
+	return b
+}
+
+func convert[T signal.SignalTypes](src *signal.Buffer[float64]) *signal.Buffer[T] {
+	dst := signal.Alloc[T](signal.Allocator{Channels: src.Channels(), Length: src.Length(), Capacity: src.Length()})
+	signal.Write(samples(src), dst)
+	return dst
+}
+
+func samples[T signal.SignalTypes](b *signal.Buffer[T]) []T {
+	s := make([]T, b.Len())
+	signal.Read(b, s)
+	return s
+}
+
+func assertEqual(t *testing.T, name string, result, expected interface{}) {
+	t.Helper()
+	if !reflect.DeepEqual(expected, result) {
+		t.Fatalf("%v\nresult: \t%T\t%+v \nexpected: \t%T\t%+v", name, result, result, expected, expected)
+	}
+}
+
+func assertNoError(t *testing.T, err error) {
+	t.Helper()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func assertPanic(t *testing.T, fn func()) {
+	t.Helper()
+	defer func() {
+		if r := recover(); r == nil {
+			t.Fatalf("didn't panic")
+		}
+	}()
+	fn()
+}
@@ -0,0 +1,108 @@
+package signal
+
+import (
+	"sync/atomic"
+)
+
+// Ring is a fixed-size circular buffer of multi-channel signal. It's safe
+// for concurrent use by a single producer and a single consumer goroutine
+// without locks. Ring never allocates after it's created, so it can be
+// used in real-time audio callbacks.
+type Ring[T SignalTypes] struct {
+	channels
+	data []T
+	size uint64
+	// total numbers of written and read samples per channel. Positions
+	// in data are derived from them.
+	written   atomic.Uint64
+	read      atomic.Uint64
+	overruns  atomic.Uint64
+	underruns atomic.Uint64
+}
+
+// NewRing returns Ring with the number of channels and capacity per
+// channel defined by Allocator. Length of Allocator is ignored.
+func NewRing[T SignalTypes](a Allocator) *Ring[T] {
+	return &Ring[T]{
+		channels: channels(a.Channels),
+		data:     make([]T, a.Channels*a.Capacity),
+		size:     uint64(a.Capacity),
+	}
+}
+
+// Capacity returns capacity of a single channel.
+func (r *Ring[T]) Capacity() int {
+	return int(r.size)
+}
+
+// Length returns a number of samples per channel available for read.
+func (r *Ring[T]) Length() int {
+	return int(r.written.Load() - r.read.Load())
+}
+
+// Overruns returns a total number of samples per channel that were
+// dropped by Write because Ring was full.
+func (r *Ring[T]) Overruns() uint64 {
+	return r.overruns.Load()
+}
+
+// Underruns returns a total number of samples per channel that were
+// replaced with silence by Read because Ring was empty.
+func (r *Ring[T]) Underruns() uint64 {
+	return r.underruns.Load()
+}
+
+// Write copies [0:Length] samples of the src Buffer into the Ring. If
+// there is not enough space, samples that don't fit are dropped and
+// counted as overruns. Buffer must have the same number of channels and
+// contain only whole frames, otherwise function will panic. It must be
+// called only by producer.
+// Returns a number of samples written per channel.
+func (r *Ring[T]) Write(src *Buffer[T]) int {
+	mustSame(r.Channels(), src.Channels(), diffChannels)
+	mustWholeFrames(src.Len(), src.Channels())
+	written := r.written.Load()
+	free := r.size - (written - r.read.Load())
+	n := uint64(src.Length())
+	if n > free {
+		r.overruns.Add(n - free)
+		n = free
+	}
+	if n == 0 {
+		return 0
+	}
+	pos := int(written%r.size) * r.Channels()
+	copied := copy(r.data[pos:], src.data[:int(n)*r.Channels()])
+	copy(r.data, src.data[copied:int(n)*r.Channels()])
+	r.written.Store(written + n)
+	return int(n)
+}
+
+// Read copies samples from the Ring into [0:Length] of the dst Buffer. If
+// there is not enough samples, the rest of the Buffer is filled with
+// silence and missing samples are counted as underruns. Buffer must have
+// the same number of channels and contain only whole frames, otherwise
+// function will panic. It must be called only by consumer. Returns a
+// number of samples read per channel.
+func (r *Ring[T]) Read(dst *Buffer[T]) int {
+	mustSame(r.Channels(), dst.Channels(), diffChannels)
+	mustWholeFrames(dst.Len(), dst.Channels())
+	read := r.read.Load()
+	available := r.written.Load() - read
+	n := uint64(dst.Length())
+	if n > available {
+		r.underruns.Add(n - available)
+		for i := int(available) * r.Channels(); i < dst.Len(); i++ {
+			dst.data[i] = 0
+		}
+		n = available
+	}
+	if n == 0 {
+		return 0
+	}
+	pos := int(read%r.size) * r.Channels()
+	copied := copy(dst.data[:int(n)*r.Channels()], r.data[pos:])
+	copy(dst.data[copied:int(n)*r.Channels()], r.data)
+	r.read.Store(read + n)
+	return int(n)
+}
@@ -0,0 +1,104 @@
+package signal_test
+
+import (
+	"runtime"
+	"testing"
+
+	"pipelined.dev/signal"
+)
+
+func TestRing(t *testing.T) {
+	ring := signal.NewRing[int16](signal.Allocator{Channels: 2, Capacity: 4})
+	assertEqual(t, "capacity", ring.Capacity(), 4)
+	buffer := func(s ...int16) *signal.Buffer[int16] {
+		b := signal.Alloc[int16](signal.Allocator{Channels: 2, Length: len(s) / 2, Capacity: len(s) / 2})
+		signal.Write(s, b)
+		return b
+	}
+
+	assertEqual(t, "written", ring.Write(buffer(1, 2, 3, 4, 5, 6)), 3)
+	assertEqual(t, "length", ring.Length(), 3)
+
+	dst := buffer(0, 0, 0, 0)
+	assertEqual(t, "read", ring.Read(dst), 2)
+	assertEqual(t, "samples", samples(dst), []int16{1, 2, 3, 4})
+	assertEqual(t, "length", ring.Length(), 1)
+
+	// wrap around and overrun.
+	assertEqual(t, "written wrapped", ring.Write(buffer(7, 8, 9, 10, 11, 12, 13, 14)), 3)
+	assertEqual(t, "overruns", ring.Overruns(), uint64(1))
+	assertEqual(t, "length", ring.Length(), 4)
+
+	// read wrapped and underrun.
+	dst = buffer(-1, -1, -1, -1, -1, -1, -1, -1, -1, -1)
+	assertEqual(t, "read wrapped", ring.Read(dst), 4)
+	assertEqual(t, "samples", samples(dst), []int16{5, 6, 7, 8, 9, 10, 11, 12, 0, 0})
+	assertEqual(t, "underruns", ring.Underruns(), uint64(1))
+	assertEqual(t, "length", ring.Length(), 0)
+
+	assertPanic(t, func() {
+		ring.Write(signal.Alloc[int16](signal.Allocator{Channels: 1, Length: 1, Capacity: 1}))
+	})
+	assertPanic(t, func() {
+		ring.Read(signal.Alloc[int16](signal.Allocator{Channels: 1, Length: 1, Capacity: 1}))
+	})
+
+	// buffers with incomplete last frame.
+	partial := signal.Alloc[int16](signal.Allocator{Channels: 2, Length: 1, Capacity: 2})
+	partial.AppendSample(1)
+	assertPanicMessage(t, func() {
+		ring.Write(partial)
+	}, "buffer length is not a multiple of channels")
+	assertPanicMessage(t, func() {
+		ring.Read(partial)
+	}, "buffer length is not a multiple of channels")
+}
+
+func TestRingAllocs(t *testing.T) {
+	alloc := signal.Allocator{Channels: 2, Length: 100, Capacity: 100}
+	ring := signal.NewRing[float32](signal.Allocator{Channels: 2, Capacity: 256})
+	src, dst := signal.Alloc[float32](alloc), signal.Alloc[float32](alloc)
+	allocs := testing.AllocsPerRun(100, func() {
+		ring.Write(src)
+		ring.Read(dst)
+	})
+	assertEqual(t, "allocs", allocs, 0.0)
+}
+
+func TestRingConcurrent(t *testing.T) {
+	const (
+		total = 100000
+		chunk = 64
+	)
+	ring := signal.NewRing[int64](signal.Allocator{Channels: 2, Capacity: 256})
+	alloc := signal.Allocator{Channels: 2, Length: chunk, Capacity: chunk}
+
+	go func() {
+		src := signal.Alloc[int64](alloc)
+		for i := 0; i < total; {
+			for j := 0; j < chunk; j++ {
+				src.SetSample(2*j, int64(i+j))
+				src.SetSample(2*j+1, -int64(i+j))
+			}
+			n := ring.Write(src.Slice(0, min(chunk, total-i)))
+			if n == 0 {
+				runtime.Gosched()
+			}
+			i += n
+		}
+	}()
+
+	dst := signal.Alloc[int64](alloc)
+	for i := 0; i < total; {
+		n := ring.Read(dst.Slice(0, min(chunk, total-i)))
+		if n == 0 {
+			runtime.Gosched()
+		}
+		for j := 0; j < n; j++ {
+			if dst.Sample(2*j) != int64(i+j) || dst.Sample(2*j+1) != -int64(i+j) {
+				t.Fatalf("invalid sample %d: %d", i+j, dst.Sample(2*j))
+			}
+		}
+		i += n
+	}
+}
@@ -11,20 +11,48 @@ import (
 )
 
 const (
-	diffChannels string = "different number of channels"
-	diffCapacity string = "different buffer capacity"
+	diffChannels   string = "different number of channels"
+	diffCapacity   string = "different buffer capacity"
+	diffBitDepth   string = "different bit depth"
+	diffSampleRate string = "different sample rate"
+
+	bitDepthOutOfRange string = "bit depth out of range"
+	channelOutOfRange  string = "channel out of range"
+	noSampleRate       string = "sample rate is not set"
+	hopOutOfRange      string = "hop size out of range"
+	partialFrame       string = "buffer length is not a multiple of channels"
 )
 
 type (
 	SignalTypes interface {
 		constraints.Float | constraints.Integer
 	}
+
+	// Samples provides access to samples by interleaved index. It's
+	// implemented by Buffer, Planar and a single channel C, so functions
+	// that accept Samples can be used with all of them. Functions have
+	// fast paths for Buffer.
+	Samples[T SignalTypes] interface {
+		// Channels returns number of channels.
+		Channels() int
+		// Length returns length of a single channel.
+		Length() int
+		// Len returns length of all channels.
+		Len() int
+		// BitDepth returns bit depth of samples.
+		BitDepth() BitDepth
+		// Sample returns sample value for provided index.
+		Sample(i int) T
+		// SetSample sets sample value for provided index.
+		SetSample(i int, v T)
+	}
 )
 
 // types for Buffer properties.
 type (
-	bitDepth BitDepth
-	channels int
+	bitDepth   BitDepth
+	channels   int
+	sampleRate Frequency
 )
 
 // BitDepth is the number of bits of information in each sample.
@@ -118,18 +146,14 @@ func (f Frequency) Events(d time.Duration) int {
 // destination Buffer. Both buffers must have the same number of channels,
 // otherwise function will panic. Returns a number of samples written per
 // channel.
-func FloatAsFloat[S, D constraints.Float](src *Buffer[S], dst *Buffer[D]) int {
-	mustSame(src.Channels(), dst.Channels(), diffChannels)
-	// cap length to destination capacity.
-	length := min(src.Len(), dst.Len())
-	if length == 0 {
-		return 0
-	}
-	// determine the multiplier for bit depth conversion
-	for i := 0; i < length; i++ {
-		dst.SetSample(i, D(src.Sample(i)))
+func FloatAsFloat[S, D constraints.Float](src Samples[S], dst Samples[D]) int {
+	return convert(src, dst, floating, floating)
+}
+
+func floatAsFloat[S, D SignalTypes](src []S, dst []D, _, _ BitDepth) {
+	for i, v := range src {
+		dst[i] = D(v)
 	}
-	return min(src.Length(), dst.Length())
 }
 
 // FloatAsSigned converts floating-point samples into signed fixed-point
@@ -138,18 +162,16 @@ func FloatAsFloat[S, D constraints.Float](src *Buffer[S], dst *Buffer[D]) int {
 // values beyond the range will be clipped. Buffers must have the same
 // number of channels, otherwise function will panic. Returns a number of
 // samples written per channel.
-func FloatAsSigned[S constraints.Float, D constraints.Signed](src *Buffer[S], dst *Buffer[D]) int {
-	mustSame(src.Channels(), dst.Channels(), diffChannels)
-	// cap length to destination capacity.
-	length := min(src.Len(), dst.Len())
-	if length == 0 {
-		return 0
-	}
+func FloatAsSigned[S constraints.Float, D constraints.Signed](src Samples[S], dst Samples[D]) int {
+	return convert(src, dst, floating, signed)
+}
+
+func floatAsSigned[S, D SignalTypes](src []S, dst []D, _, bd BitDepth) {
 	// determine the multiplier for bit depth conversion
-	msv := D(dst.BitDepth().MaxSignedValue())
-	for i := 0; i < length; i++ {
+	msv := D(bd.MaxSignedValue())
+	for i, v := range src {
 		var sample D
-		if f := float64(src.Sample(i)); f > 0 {
+		if f := float64(v); f > 0 {
 			// detect overflow
 			if D(f) == 0 {
 				sample = D(f * float64(msv))
@@ -157,12 +179,15 @@ func FloatAsSigned[S constraints.Float, D constraints.Signed](src *Buffer[S], ds
 				sample = msv
 			}
 		} else {
-			// no overflow here
-			sample = D(f * (float64(msv) + 1))
+			// detect underflow
+			if f > -1 {
+				sample = D(f * (float64(msv) + 1))
+			} else {
+				sample = -msv - 1
+			}
 		}
-		dst.SetSample(i, sample)
+		dst[i] = sample
 	}
-	return min(src.Length(), dst.Length())
 }
 
 // FloatAsUnsigned converts floating-point samples into unsigned
@@ -170,19 +195,17 @@ func FloatAsSigned[S constraints.Float, D constraints.Signed](src *Buffer[S], ds
 // sample range [-1,1] is mapped to unsigned [0, 2^bitDepth-1]. Floating
 // values beyond the range will be clipped. Buffers must have the same
 // number of channels, otherwise function will panic.
-func FloatAsUnsigned[S constraints.Float, D constraints.Unsigned](src *Buffer[S], dst *Buffer[D]) int {
-	mustSame(src.Channels(), dst.Channels(), diffChannels)
-	// cap length to destination capacity.
-	length := min(src.Len(), dst.Len())
-	if length == 0 {
-		return 0
-	}
+func FloatAsUnsigned[S constraints.Float, D constraints.Unsigned](src Samples[S], dst Samples[D]) int {
+	return convert(src, dst, floating, unsigned)
+}
+
+func floatAsUnsigned[S, D SignalTypes](src []S, dst []D, _, bd BitDepth) {
 	// determine the multiplier for bit depth conversion
-	msv := D(dst.BitDepth().MaxSignedValue())
+	msv := D(bd.MaxSignedValue())
 	offset := msv + 1
-	for i := 0; i < length; i++ {
+	for i, v := range src {
 		var sample D
-		if f := float64(src.Sample(i)); f > 0 {
+		if f := float64(v); f > 0 {
 			// detect overflow
 			if int64(f) == 0 {
 				sample = D(f*float64(msv)) + offset
@@ -190,12 +213,15 @@ func FloatAsUnsigned[S constraints.Float, D constraints.Unsigned](src *Buffer[S]
 				sample = msv + offset
 			}
 		} else {
-			// no overflow here
-			sample = D(f*(float64(msv)+1)) + offset
+			// detect underflow
+			if f > -1 {
+				sample = D(int64(f*(float64(msv)+1))) + offset
+			} else {
+				sample = 0
+			}
 		}
-		dst.SetSample(i, sample)
+		dst[i] = sample
 	}
-	return min(src.Length(), dst.Length())
 }
 
 // SignedAsFloat converts signed fixed-point samples into floating-point
@@ -203,56 +229,49 @@ func FloatAsUnsigned[S constraints.Float, D constraints.Unsigned](src *Buffer[S]
 // [-2^(bitDepth-1), 2^(bitDepth-1)-1] is mapped to floating [-1,1].
 // Buffers must have the same number of channels, otherwise function will
 // panic.
-func SignedAsFloat[S constraints.Signed, D constraints.Float](src *Buffer[S], dst *Buffer[D]) int {
-	mustSame(src.Channels(), dst.Channels(), diffChannels)
-	// cap length to destination capacity.
-	length := min(src.Len(), dst.Len())
-	if length == 0 {
-		return 0
-	}
+func SignedAsFloat[S constraints.Signed, D constraints.Float](src Samples[S], dst Samples[D]) int {
+	return convert(src, dst, signed, floating)
+}
+
+func signedAsFloat[S, D SignalTypes](src []S, dst []D, bd, _ BitDepth) {
 	// determine the divider for bit depth conversion.
-	msv := D(src.BitDepth().MaxSignedValue())
-	for i := 0; i < length; i++ {
-		if sample := src.Sample(i); sample > 0 {
-			dst.SetSample(i, D(sample)/msv)
+	msv := D(bd.MaxSignedValue())
+	for i, sample := range src {
+		if sample > 0 {
+			dst[i] = D(sample) / msv
 		} else {
-			dst.SetSample(i, D(sample)/(msv+1))
+			dst[i] = D(sample) / (msv + 1)
 		}
 	}
-	return min(src.Length(), dst.Length())
 }
 
 // SignedAsSigned appends signed fixed-point samples to the signed
 // fixed-point destination Buffer. The samples are quantized to the
 // destination bit depth. Buffers must have the same number of channels,
 // otherwise function will panic.
-func SignedAsSigned[S, D constraints.Signed](src *Buffer[S], dst *Buffer[D]) int {
-	mustSame(src.Channels(), dst.Channels(), diffChannels)
-	// cap length to destination capacity.
-	length := min(src.Len(), dst.Len())
-	if length == 0 {
-		return 0
-	}
+func SignedAsSigned[S, D constraints.Signed](src Samples[S], dst Samples[D]) int {
+	return convert(src, dst, signed, signed)
+}
 
+func signedAsSigned[S, D SignalTypes](src []S, dst []D, srcBitDepth, dstBitDepth BitDepth) {
 	// downscale
-	if src.BitDepth() >= dst.BitDepth() {
-		scale := Scale[S](src.BitDepth(), dst.BitDepth())
-		for i := 0; i < length; i++ {
-			dst.SetSample(i, D(src.Sample(i)/scale))
+	if srcBitDepth >= dstBitDepth {
+		scale := S(Scale[uint64](srcBitDepth, dstBitDepth))
+		for i, sample := range src {
+			dst[i] = D(sample / scale)
 		}
-		return min(src.Length(), dst.Length())
+		return
 	}
 
 	// upscale
-	scale := Scale[D](dst.BitDepth(), src.BitDepth())
-	for i := 0; i < length; i++ {
-		if sample := src.Sample(i); sample > 0 {
-			dst.SetSample(i, ((D(src.Sample(i))+1)*scale)-1)
+	scale := D(Scale[uint64](dstBitDepth, srcBitDepth))
+	for i, sample := range src {
+		if sample > 0 {
+			dst[i] = ((D(sample) + 1) * scale) - 1
 		} else {
-			dst.SetSample(i, D(src.Sample(i))*scale)
+			dst[i] = D(sample) * scale
 		}
 	}
-	return min(src.Length(), dst.Length())
 }
 
 // SignedAsUnsigned converts signed fixed-point samples into unsigned
@@ -261,57 +280,50 @@ func SignedAsSigned[S, D constraints.Signed](src *Buffer[S], dst *Buffer[D]) int
 // [-2^(bitDepth-1), 2^(bitDepth-1)-1] is mapped to unsigned [0,
 // 2^bitDepth-1]. Buffers must have the same number of channels, otherwise
 // function will panic.
-func SignedAsUnsigned[S constraints.Signed, D constraints.Unsigned](src *Buffer[S], dst *Buffer[D]) int {
-	mustSame(src.Channels(), dst.Channels(), diffChannels)
-	// cap length to destination capacity.
-	length := min(src.Len(), dst.Len())
-	if length == 0 {
-		return 0
-	}
+func SignedAsUnsigned[S constraints.Signed, D constraints.Unsigned](src Samples[S], dst Samples[D]) int {
+	return convert(src, dst, signed, unsigned)
+}
 
-	msv := D(dst.BitDepth().MaxSignedValue())
+func signedAsUnsigned[S, D SignalTypes](src []S, dst []D, srcBitDepth, dstBitDepth BitDepth) {
+	msv := D(dstBitDepth.MaxSignedValue())
 	// downscale
-	if src.BitDepth() >= dst.BitDepth() {
-		scale := Scale[S](src.BitDepth(), dst.BitDepth())
-		for i := 0; i < length; i++ {
-			dst.SetSample(i, D(src.Sample(i)/scale)+msv+1)
+	if srcBitDepth >= dstBitDepth {
+		scale := S(Scale[uint64](srcBitDepth, dstBitDepth))
+		for i, sample := range src {
+			dst[i] = D(sample/scale) + msv + 1
 		}
-		return min(src.Length(), dst.Length())
+		return
 	}
 
 	// upscale
-	scale := Scale[D](dst.BitDepth(), src.BitDepth())
-	for i := 0; i < length; i++ {
-		if sample := src.Sample(i); sample > 0 {
-			dst.SetSample(i, D((src.Sample(i)+1))*scale+msv)
+	scale := D(Scale[uint64](dstBitDepth, srcBitDepth))
+	for i, sample := range src {
+		if sample > 0 {
+			dst[i] = (D(sample)+1)*scale + msv
 		} else {
-			dst.SetSample(i, D(src.Sample(i))*scale+msv+1)
+			dst[i] = D(sample)*scale + msv + 1
 		}
 	}
-	return min(src.Length(), dst.Length())
 }
 
 // UnsignedAsFloat converts unsigned fixed-point samples into
 // floating-point and appends them to the destination Buffer. The unsigned
 // sample range [0, 2^bitDepth-1] is mapped to floating [-1,1]. Buffers
 // must have the same number of channels, otherwise function will panic.
-func UnsignedAsFloat[S constraints.Unsigned, D constraints.Float](src *Buffer[S], dst *Buffer[D]) int {
-	mustSame(src.Channels(), dst.Channels(), diffChannels)
-	// cap length to destination capacity.
-	length := min(src.Len(), dst.Len())
-	if length == 0 {
-		return 0
-	}
+func UnsignedAsFloat[S constraints.Unsigned, D constraints.Float](src Samples[S], dst Samples[D]) int {
+	return convert(src, dst, unsigned, floating)
+}
+
+func unsignedAsFloat[S, D SignalTypes](src []S, dst []D, bd, _ BitDepth) {
 	// determine the multiplier for bit depth conversion
-	msv := D(src.BitDepth().MaxSignedValue())
-	for i := 0; i < length; i++ {
-		if sample := src.Sample(i); sample > 0 {
-			dst.SetSample(i, (D(sample)-(msv+1))/msv)
+	msv := D(bd.MaxSignedValue())
+	for i, sample := range src {
+		if sample > 0 {
+			dst[i] = (D(sample) - (msv + 1)) / msv
 		} else {
-			dst.SetSample(i, (D(sample)-(msv+1))/(msv+1))
+			dst[i] = (D(sample) - (msv + 1)) / (msv + 1)
 		}
 	}
-	return min(src.Length(), dst.Length())
 }
 
 // UnsignedAsSigned converts unsigned fixed-point samples into signed
@@ -320,70 +332,136 @@ func UnsignedAsFloat[S constraints.Unsigned, D constraints.Float](src *Buffer[S]
 // 2^bitDepth-1] is mapped to signed [-2^(bitDepth-1), 2^(bitDepth-1)-1].
 // Buffers must have the same number of channels, otherwise function will
 // panic.
-func UnsignedAsSigned[S constraints.Unsigned, D constraints.Signed](src *Buffer[S], dst *Buffer[D]) int {
-	mustSame(src.Channels(), dst.Channels(), diffChannels)
-	// cap length to destination capacity.
-	length := min(src.Len(), dst.Len())
-	if length == 0 {
-		return 0
-	}
-	msv := src.BitDepth().MaxSignedValue()
+func UnsignedAsSigned[S constraints.Unsigned, D constraints.Signed](src Samples[S], dst Samples[D]) int {
+	return convert(src, dst, unsigned, signed)
+}
+
+func unsignedAsSigned[S, D SignalTypes](src []S, dst []D, srcBitDepth, dstBitDepth BitDepth) {
+	// offset is subtracted in unsigned domain, so the result wraps into
+	// the proper signed value for any bit depth.
+	offset := uint64(srcBitDepth.MaxSignedValue()) + 1
 	// downscale
-	if src.BitDepth() >= dst.BitDepth() {
-		scale := Scale[S](src.BitDepth(), dst.BitDepth())
-		for i := 0; i < length; i++ {
-			dst.SetSample(i, D((src.Sample(i)-S(msv+1))/scale))
+	if srcBitDepth >= dstBitDepth {
+		shift := srcBitDepth - dstBitDepth
+		for i, sample := range src {
+			dst[i] = D(int64(uint64(sample)-offset) >> shift)
 		}
-		return min(src.Length(), dst.Length())
+		return
 	}
 
 	// upscale
-	scale := Scale[D](dst.BitDepth(), src.BitDepth())
-	for i := 0; i < length; i++ {
-		if sample := D(src.Sample(i)) - D(msv+1); sample > 0 {
-			dst.SetSample(i, D((sample+1)*scale-1))
+	shift := dstBitDepth - srcBitDepth
+	for i, v := range src {
+		if sample := int64(uint64(v) - offset); sample > 0 {
+			dst[i] = D((sample+1)<<shift - 1)
 		} else {
-			dst.SetSample(i, D(sample*scale))
+			dst[i] = D(sample << shift)
 		}
 	}
-	return min(src.Length(), dst.Length())
 }
 
 // UnsignedAsUnsigned appends unsigned fixed-point samples to the unsigned
 // fixed-point destination Buffer. The samples are quantized to the
 // destination bit depth. Buffers must have the same number of channels,
 // otherwise function will panic.
-func UnsignedAsUnsigned[S, D constraints.Unsigned](src *Buffer[S], dst *Buffer[D]) int {
+func UnsignedAsUnsigned[S, D constraints.Unsigned](src Samples[S], dst Samples[D]) int {
+	return convert(src, dst, unsigned, unsigned)
+}
+
+func unsignedAsUnsigned[S, D SignalTypes](src []S, dst []D, srcBitDepth, dstBitDepth BitDepth) {
+	// downscale
+	if srcBitDepth >= dstBitDepth {
+		scale := S(Scale[uint64](srcBitDepth, dstBitDepth))
+		for i, sample := range src {
+			dst[i] = D(sample / scale)
+		}
+		return
+	}
+
+	// upscale
+	scale := D(Scale[uint64](dstBitDepth, srcBitDepth))
+	msv := S(srcBitDepth.MaxSignedValue())
+	for i, sample := range src {
+		if sample > msv+1 {
+			dst[i] = (D(sample)+1)*scale - 1
+		} else {
+			dst[i] = D(sample) * scale
+		}
+	}
+}
+
+// chunkLength is a number of samples converted at once when source or
+// destination is not a Buffer.
+const chunkLength = 256
+
+// convert converts [0:Len] samples of the source into the destination.
+// Buffers are converted directly, other Samples are converted in chunks
+// through arrays on the stack, so no allocations happen. Returns a number
+// of samples written per channel.
+func convert[S, D SignalTypes](src Samples[S], dst Samples[D], srcKind, dstKind kind) int {
 	mustSame(src.Channels(), dst.Channels(), diffChannels)
 	// cap length to destination capacity.
 	length := min(src.Len(), dst.Len())
 	if length == 0 {
 		return 0
 	}
-
-	// downscale
-	if src.BitDepth() >= dst.BitDepth() {
-		scale := Scale[S](src.BitDepth(), dst.BitDepth())
-		for i := 0; i < length; i++ {
-			dst.SetSample(i, D(src.Sample(i)/scale))
-		}
+	srcBuf, srcOk := src.(*Buffer[S])
+	dstBuf, dstOk := dst.(*Buffer[D])
+	if srcOk && dstOk {
+		convertSlice(srcKind, dstKind, srcBuf.data[:length], dstBuf.data[:length], src.BitDepth(), dst.BitDepth())
 		return min(src.Length(), dst.Length())
 	}
-
-	// upscale
-	scale := Scale[D](dst.BitDepth(), src.BitDepth())
-	msv := S(src.BitDepth().MaxSignedValue())
-	for i := 0; i < length; i++ {
-		var sample S
-		if sample = src.Sample(i); sample > msv+1 {
-			dst.SetSample(i, D(sample+1)*scale-1)
-		} else {
-			dst.SetSample(i, D(sample)*scale)
+	var (
+		srcChunk [chunkLength]S
+		dstChunk [chunkLength]D
+	)
+	for offset := 0; offset < length; offset += chunkLength {
+		n := min(chunkLength, length-offset)
+		for i := range srcChunk[:n] {
+			srcChunk[i] = src.Sample(offset + i)
+		}
+		convertSlice(srcKind, dstKind, srcChunk[:n], dstChunk[:n], src.BitDepth(), dst.BitDepth())
+		for i, v := range dstChunk[:n] {
+			dst.SetSample(offset+i, v)
 		}
 	}
 	return min(src.Length(), dst.Length())
 }
 
+// convertSlice calls conversion function for the kinds of source and
+// destination.
+func convertSlice[S, D SignalTypes](srcKind, dstKind kind, src []S, dst []D, srcBitDepth, dstBitDepth BitDepth) {
+	switch srcKind {
+	case floating:
+		switch dstKind {
+		case floating:
+			floatAsFloat(src, dst, srcBitDepth, dstBitDepth)
+		case signed:
+			floatAsSigned(src, dst, srcBitDepth, dstBitDepth)
+		case unsigned:
+			floatAsUnsigned(src, dst, srcBitDepth, dstBitDepth)
+		}
+	case signed:
+		switch dstKind {
+		case floating:
+			signedAsFloat(src, dst, srcBitDepth, dstBitDepth)
+		case signed:
+			signedAsSigned(src, dst, srcBitDepth, dstBitDepth)
+		case unsigned:
+			signedAsUnsigned(src, dst, srcBitDepth, dstBitDepth)
+		}
+	case unsigned:
+		switch dstKind {
+		case floating:
+			unsignedAsFloat(src, dst, srcBitDepth, dstBitDepth)
+		case signed:
+			unsignedAsSigned(src, dst, srcBitDepth, dstBitDepth)
+		case unsigned:
+			unsignedAsUnsigned(src, dst, srcBitDepth, dstBitDepth)
+		}
+	}
+}
+
 // BitDepth returns bit depth of the Buffer.
 func (bd bitDepth) BitDepth() BitDepth {
 	return BitDepth(bd)
@@ -394,6 +472,12 @@ func (c channels) Channels() int {
 	return int(c)
 }
 
+// SampleRate returns sample rate of the Buffer. Zero value means the
+// sample rate is not set.
+func (sr sampleRate) SampleRate() Frequency {
+	return Frequency(sr)
+}
+
 func min(v1, v2 int) int {
 	if v1 < v2 {
 		return v1
@@ -407,6 +491,14 @@ func mustSame[T comparable](a, b T, panicStr string) {
 	}
 }
 
+// mustWholeFrames panics if the buffer of provided length contains
+// samples of an incomplete frame.
+func mustWholeFrames(length, channels int) {
+	if channels != 0 && length%channels != 0 {
+		panic(partialFrame)
+	}
+}
+
 // ChannelLength calculates a channel length for provided Buffer length and
 // number of channels.
 func ChannelLength(sliceLen, channels int) int {
@@ -420,51 +512,81 @@ func (c channels) BufferIndex(channel, idx int) int {
 	return int(c)*idx + channel
 }
 
-// ReadFloat reads values from the Buffer into provided slice.
+// Read reads values from the Samples into provided slice.
 // Returns number of samples read per channel.
-func Read[S, D SignalTypes](src *Buffer[S], dst []D) int {
+func Read[S, D SignalTypes](src Samples[S], dst []D) int {
 	length := min(src.Len(), len(dst))
+	if b, ok := src.(*Buffer[S]); ok {
+		for i, v := range b.data[:length] {
+			dst[i] = D(v)
+		}
+		return ChannelLength(length, src.Channels())
+	}
 	for i := 0; i < length; i++ {
 		dst[i] = D(src.Sample(i))
 	}
 	return ChannelLength(length, src.Channels())
 }
 
-// ReadStripedFloat reads values from the Buffer into provided slice. The
+// ReadStriped reads values from the Samples into provided slice. The
 // length of provided slice must be equal to the number of channels,
 // otherwise function will panic. Nested slices can be nil, no values for
 // that channel will be read. Returns a number of samples read for the
 // longest channel.
-func ReadStriped[S, D SignalTypes](src *Buffer[S], dst [][]D) (read int) {
+func ReadStriped[S, D SignalTypes](src Samples[S], dst [][]D) (read int) {
 	mustSame(src.Channels(), len(dst), diffChannels)
-	for c := 0; c < src.Channels(); c++ {
-		length := min(len(dst[c]), src.Length())
-		if length > read {
-			read = length
+	switch b := src.(type) {
+	case *Buffer[S]:
+		for c := range dst {
+			length := min(len(dst[c]), b.Length())
+			read = max(read, length)
+			for i := range dst[c][:length] {
+				dst[c][i] = D(b.data[b.BufferIndex(c, i)])
+			}
+		}
+	case *Planar[S]:
+		for c := range dst {
+			length := min(len(dst[c]), b.Length())
+			read = max(read, length)
+			for i, v := range b.data[c][:length] {
+				dst[c][i] = D(v)
+			}
 		}
-		for i := 0; i < length; i++ {
-			dst[c][i] = D(src.Sample(src.BufferIndex(c, i)))
+	default:
+		channels := src.Channels()
+		for c := range dst {
+			length := min(len(dst[c]), src.Length())
+			read = max(read, length)
+			for i := range dst[c][:length] {
+				dst[c][i] = D(src.Sample(channels*i + c))
+			}
 		}
 	}
 	return
 }
 
-// WriteFloat writes values from provided slice into the Buffer.
+// Write writes values from provided slice into the Samples.
 // Returns a number of samples written per channel.
-func Write[S, D SignalTypes](src []S, dst *Buffer[D]) int {
+func Write[S, D SignalTypes](src []S, dst Samples[D]) int {
 	length := min(dst.Len(), len(src))
+	if b, ok := dst.(*Buffer[D]); ok {
+		for i, v := range src[:length] {
+			b.data[i] = D(v)
+		}
+		return ChannelLength(length, dst.Channels())
+	}
 	for i := 0; i < length; i++ {
 		dst.SetSample(i, D(src[i]))
 	}
 	return ChannelLength(length, dst.Channels())
 }
 
-// WriteStripedFloat64 writes values from provided slice into the Buffer.
-// The length of provided slice must be equal to the number of channels,
+// WriteStriped writes values from provided slice into the Samples. The
+// length of provided slice must be equal to the number of channels,
 // otherwise function will panic. Nested slices can be nil, zero values for
 // that channel will be written. Returns a number of samples written for
 // the longest channel.
-func WriteStriped[S, D SignalTypes](src [][]S, dst *Buffer[D]) (written int) {
+func WriteStriped[S, D SignalTypes](src [][]S, dst Samples[D]) (written int) {
 	mustSame(dst.Channels(), len(src), diffChannels)
 	// determine the length of longest nested slice
 	for i := range src {
@@ -474,12 +596,34 @@ func WriteStriped[S, D SignalTypes](src [][]S, dst *Buffer[D]) (written int) {
 	}
 	// limit a number of writes to the length of the Buffer
 	written = min(written, dst.Length())
-	for c := 0; c < dst.Channels(); c++ {
-		for i := 0; i < written; i++ {
-			if i < len(src[c]) {
-				dst.SetSample(dst.BufferIndex(c, i), D(src[c][i]))
-			} else {
-				dst.SetSample(dst.BufferIndex(c, i), 0)
+	switch b := dst.(type) {
+	case *Buffer[D]:
+		for c := range src {
+			for i := 0; i < written; i++ {
+				if i < len(src[c]) {
+					b.data[b.BufferIndex(c, i)] = D(src[c][i])
+				} else {
+					b.data[b.BufferIndex(c, i)] = 0
+				}
+			}
+		}
+	case *Planar[D]:
+		for c := range src {
+			n := min(written, len(src[c]))
+			for i, v := range src[c][:n] {
+				b.data[c][i] = D(v)
+			}
+			clear(b.data[c][n:written])
+		}
+	default:
+		channels := dst.Channels()
+		for c := range src {
+			for i := 0; i < written; i++ {
+				if i < len(src[c]) {
+					dst.SetSample(channels*i+c, D(src[c][i]))
+				} else {
+					dst.SetSample(channels*i+c, 0)
+				}
 			}
 		}
 	}
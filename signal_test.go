@@ -634,6 +634,13 @@ func assertEqual(t *testing.T, name string, result, expected interface{}) {
 	}
 }
 
+func assertNoError(t *testing.T, err error) {
+	t.Helper()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
 func assertPanic(t *testing.T, fn func()) {
 	t.Helper()
 	defer func() {
@@ -644,6 +651,18 @@ func assertPanic(t *testing.T, fn func()) {
 	fn()
 }
 
+// assertPanicMessage checks that function panics with provided message
+// rather than with a runtime error.
+func assertPanicMessage(t *testing.T, fn func(), msg string) {
+	t.Helper()
+	defer func() {
+		if r := recover(); r != msg {
+			t.Fatalf("expected panic: %v got: %v", msg, r)
+		}
+	}()
+	fn()
+}
+
 func result[T signal.SignalTypes](sig *signal.Buffer[T]) [][]T {
 	result := make([][]T, sig.Channels())
 	for i := range result {
@@ -652,3 +671,55 @@ func result[T signal.SignalTypes](sig *signal.Buffer[T]) [][]T {
 	signal.ReadStriped[T](sig, result)
 	return result
 }
+
+func TestConversionsBitDepth(t *testing.T) {
+	alloc := signal.Allocator{
+		Channels: 1,
+		Capacity: 5,
+		Length:   5,
+	}
+	floats := []float64{1.5, 1, 0, -1, -1.5}
+
+	t.Run("float to signed 24 bits", func(t *testing.T) {
+		f64, i32 := signal.Alloc[float64](alloc), signal.AllocBitDepth[int32](alloc, signal.BitDepth24)
+		signal.Write(floats, f64)
+		signal.FloatAsSigned(f64, i32)
+		assertEqual(t, "signed", result(i32), [][]int32{{8388607, 8388607, 0, -8388608, -8388608}})
+	})
+	t.Run("float to unsigned 24 bits", func(t *testing.T) {
+		f64, u32 := signal.Alloc[float64](alloc), signal.AllocBitDepth[uint32](alloc, signal.BitDepth24)
+		signal.Write(floats, f64)
+		signal.FloatAsUnsigned(f64, u32)
+		assertEqual(t, "unsigned", result(u32), [][]uint32{{16777215, 16777215, 8388608, 0, 0}})
+	})
+	t.Run("signed 24 bits to float", func(t *testing.T) {
+		i32, f64 := signal.AllocBitDepth[int32](alloc, signal.BitDepth24), signal.Alloc[float64](alloc)
+		signal.Write([]int32{8388607, 0, -8388608}, i32)
+		signal.SignedAsFloat(i32, f64)
+		assertEqual(t, "float", result(f64), [][]float64{{1, 0, -1, 0, 0}})
+	})
+	t.Run("signed 12 bits to signed 24 bits", func(t *testing.T) {
+		i16, i32 := signal.AllocBitDepth[int16](alloc, 12), signal.AllocBitDepth[int32](alloc, signal.BitDepth24)
+		signal.Write([]int16{2047, 0, -2048}, i16)
+		signal.SignedAsSigned(i16, i32)
+		assertEqual(t, "signed", result(i32), [][]int32{{8388607, 0, -8388608, 0, 0}})
+	})
+	t.Run("unsigned 8 bits to unsigned 24 bits", func(t *testing.T) {
+		u8, u32 := signal.Alloc[uint8](alloc), signal.AllocBitDepth[uint32](alloc, signal.BitDepth24)
+		signal.Write([]uint8{255, 128, 0}, u8)
+		signal.UnsignedAsUnsigned(u8, u32)
+		assertEqual(t, "unsigned", result(u32), [][]uint32{{16777215, 8388608, 0, 0, 0}})
+	})
+	t.Run("signed 8 bits to unsigned 24 bits", func(t *testing.T) {
+		i8, u32 := signal.Alloc[int8](alloc), signal.AllocBitDepth[uint32](alloc, signal.BitDepth24)
+		signal.Write([]int8{127, 0, -128}, i8)
+		signal.SignedAsUnsigned(i8, u32)
+		assertEqual(t, "unsigned", result(u32), [][]uint32{{16777215, 8388608, 0, 8388608, 8388608}})
+	})
+	t.Run("unsigned 24 bits to signed 64 bits", func(t *testing.T) {
+		u32, i64 := signal.AllocBitDepth[uint32](alloc, signal.BitDepth24), signal.AllocBitDepth[int64](alloc, signal.BitDepth24)
+		signal.Write([]uint32{16777215, 8388608, 0}, u32)
+		signal.UnsignedAsSigned(u32, i64)
+		assertEqual(t, "signed", result(i64), [][]int64{{8388607, 0, -8388608, -8388608, -8388608}})
+	})
+}
@@ -0,0 +1,222 @@
+// Package stft provides short-time Fourier transform of signal buffers
+// with weighted overlap-add resynthesis.
+//
+// The signal of every channel is split into overlapping frames, each
+// frame is multiplied by analysis window and transformed into spectrum.
+// Spectrum is passed to the callback, which can analyse or modify it.
+// Then the inverse transform of spectrum is multiplied by synthesis window
+// and added to the output:
+//
+//	s, err := stft.New[float64](2, window.Periodic(window.Hann, 1024), 256,
+//		func(channel int, spectrum []complex128) {
+//			// modify spectrum
+//		},
+//	)
+//	if err != nil {
+//		return err
+//	}
+//	s.Process(in, out)
+//
+// The same window is used for analysis and synthesis, so its square must
+// satisfy constant overlap-add constraint for the hop size. Output is
+// delayed by Latency samples.
+package stft
+
+import (
+	"errors"
+	"math"
+
+	"golang.org/x/exp/constraints"
+	"pipelined.dev/signal"
+	"pipelined.dev/signal/fft"
+	"pipelined.dev/signal/window"
+)
+
+var (
+	// ErrWindow is returned when window is empty.
+	ErrWindow = errors.New("stft: empty window")
+	// ErrHop is returned when hop size is not in range [1, window length].
+	ErrHop = errors.New("stft: invalid hop size")
+	// ErrCOLA is returned when squared window doesn't satisfy the
+	// constant overlap-add constraint for the hop size.
+	ErrCOLA = errors.New("stft: window doesn't satisfy overlap-add constraint")
+	// ErrChannels is returned when number of channels is not positive.
+	ErrChannels = errors.New("stft: invalid number of channels")
+)
+
+// panic messages.
+const (
+	diffChannels = "stft: different number of channels"
+	diffLength   = "stft: different length"
+)
+
+// colaTolerance is a maximum relative deviation of squared window
+// overlap-add.
+const colaTolerance = 1e-6
+
+// Func is called for every frame of every channel. The spectrum contains
+// non-negative frequencies and can be modified in place. Spectrum slice is
+// reused between calls and must not be retained.
+type Func func(channel int, spectrum []complex128)
+
+// STFT is a streaming short-time Fourier transform processor. It's not
+// safe for concurrent use.
+type STFT[T constraints.Float] struct {
+	window   window.Window
+	hop      int
+	fn       Func
+	plan     *fft.RealPlan
+	norm     float64
+	spectrum []complex128
+	frame    []float64
+	channels []state
+	// pos is a position of the next sample in the input frame, it's
+	// shared by all channels.
+	pos int
+}
+
+// state of a single channel.
+type state struct {
+	input  []float64
+	output []float64
+	// overlap-add accumulator.
+	sum []float64
+}
+
+// New returns STFT processor for provided number of channels with the
+// window and hop size. Length of the window defines the size of
+// transform.
+func New[T constraints.Float](channels int, w window.Window, hop int, fn Func) (*STFT[T], error) {
+	if channels <= 0 {
+		return nil, ErrChannels
+	}
+	size := len(w)
+	if size == 0 {
+		return nil, ErrWindow
+	}
+	if hop <= 0 || hop > size {
+		return nil, ErrHop
+	}
+	norm, ok := cola(w, hop)
+	if !ok {
+		return nil, ErrCOLA
+	}
+	plan, err := fft.NewRealPlan(size)
+	if err != nil {
+		return nil, err
+	}
+	s := STFT[T]{
+		window:   w,
+		hop:      hop,
+		fn:       fn,
+		plan:     plan,
+		norm:     norm,
+		spectrum: make([]complex128, plan.SpectrumLen()),
+		frame:    make([]float64, size),
+		channels: make([]state, channels),
+	}
+	for c := range s.channels {
+		s.channels[c] = state{
+			input:  make([]float64, size),
+			output: make([]float64, hop),
+			sum:    make([]float64, size),
+		}
+	}
+	s.Reset()
+	return &s, nil
+}
+
+// Latency returns a delay of output signal in samples.
+func (s *STFT[T]) Latency() int {
+	return len(s.window)
+}
+
+// Process transforms [0:Length] samples of src Buffer and writes
+// resynthesized signal into dst Buffer. Dst can be nil if only analysis is
+// needed. Buffers must have the same number of channels and length,
+// otherwise function will panic.
+func (s *STFT[T]) Process(src, dst *signal.Buffer[T]) {
+	mustSame(len(s.channels), src.Channels(), diffChannels)
+	if dst != nil {
+		mustSame(len(s.channels), dst.Channels(), diffChannels)
+		mustSame(src.Length(), dst.Length(), diffLength)
+	}
+	// samples are written to the end of the input frame and the output
+	// of the previous frame is read at the same time.
+	size := len(s.window)
+	offset := size - s.hop
+	for i := 0; i < src.Length(); i++ {
+		for c := range s.channels {
+			ch := &s.channels[c]
+			idx := src.BufferIndex(c, i)
+			ch.input[s.pos] = float64(src.Sample(idx))
+			if dst != nil {
+				dst.SetSample(idx, T(ch.output[s.pos-offset]))
+			}
+		}
+		s.pos++
+		if s.pos == size {
+			for c := range s.channels {
+				s.processFrame(c)
+			}
+			s.pos = offset
+		}
+	}
+}
+
+// Reset discards the state of processor, so it can be used for a new
+// stream.
+func (s *STFT[T]) Reset() {
+	for _, ch := range s.channels {
+		clear(ch.input)
+		clear(ch.output)
+		clear(ch.sum)
+	}
+	s.pos = len(s.window) - s.hop
+}
+
+// processFrame transforms the input frame of the channel and adds the
+// result to the overlap-add accumulator.
+func (s *STFT[T]) processFrame(c int) {
+	ch := &s.channels[c]
+	for i, w := range s.window {
+		s.frame[i] = ch.input[i] * w
+	}
+	s.plan.Forward(s.spectrum, s.frame)
+	if s.fn != nil {
+		s.fn(c, s.spectrum)
+	}
+	s.plan.Inverse(s.frame, s.spectrum)
+	for i, w := range s.window {
+		ch.sum[i] += s.frame[i] * w / s.norm
+	}
+	// first hop samples are complete.
+	copy(ch.output, ch.sum[:s.hop])
+	copy(ch.sum, ch.sum[s.hop:])
+	clear(ch.sum[len(ch.sum)-s.hop:])
+	copy(ch.input, ch.input[s.hop:])
+}
+
+// cola returns the overlap-add sum of squared window and reports if it's
+// constant.
+func cola(w window.Window, hop int) (float64, bool) {
+	sums := make([]float64, hop)
+	for i, v := range w {
+		sums[i%hop] += v * v
+	}
+	min, max := math.Inf(1), math.Inf(-1)
+	for _, v := range sums {
+		min = math.Min(min, v)
+		max = math.Max(max, v)
+	}
+	if max <= 0 || (max-min)/max > colaTolerance {
+		return 0, false
+	}
+	return (max + min) / 2, true
+}
+
+func mustSame[T comparable](a, b T, panicStr string) {
+	if a != b {
+		panic(panicStr)
+	}
+}
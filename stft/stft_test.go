@@ -0,0 +1,164 @@
+package stft_test
+
+import (
+	"math"
+	"reflect"
+	"testing"
+
+	"pipelined.dev/signal"
+	"pipelined.dev/signal/stft"
+	"pipelined.dev/signal/window"
+)
+
+func TestNew(t *testing.T) {
+	testError := func(channels int, w window.Window, hop int, expected error) func(*testing.T) {
+		return func(t *testing.T) {
+			t.Helper()
+			_, err := stft.New[float64](channels, w, hop, nil)
+			assertEqual(t, "error", err, expected)
+		}
+	}
+	t.Run("hann quarter", testError(1, window.Periodic(window.Hann, 64), 16, nil))
+	t.Run("rectangular", testError(1, window.Rectangular(64), 64, nil))
+	t.Run("hann half", testError(1, window.Periodic(window.Hann, 64), 32, stft.ErrCOLA))
+	t.Run("symmetric hann", testError(1, window.Hann(64), 16, stft.ErrCOLA))
+	t.Run("empty window", testError(1, window.Window{}, 1, stft.ErrWindow))
+	t.Run("zero hop", testError(1, window.Rectangular(64), 0, stft.ErrHop))
+	t.Run("long hop", testError(1, window.Rectangular(64), 65, stft.ErrHop))
+	t.Run("channels", testError(0, window.Rectangular(64), 64, stft.ErrChannels))
+}
+
+func TestResynthesis(t *testing.T) {
+	testOk := func(w window.Window, hop int, gain float64, chunk int) func(*testing.T) {
+		return func(t *testing.T) {
+			t.Helper()
+			const (
+				channels = 2
+				length   = 1000
+			)
+			frames := make([]int, channels)
+			s, err := stft.New[float64](channels, w, hop, func(channel int, spectrum []complex128) {
+				frames[channel]++
+				for i := range spectrum {
+					spectrum[i] *= complex(gain, 0)
+				}
+			})
+			assertNoError(t, err)
+			assertEqual(t, "latency", s.Latency(), len(w))
+
+			input := signal.Alloc[float64](signal.Allocator{Channels: channels, Length: length, Capacity: length})
+			for i := 0; i < input.Len(); i++ {
+				input.SetSample(i, math.Sin(float64(i)*0.01)+float64(i%channels))
+			}
+			output := signal.Alloc[float64](signal.Allocator{Channels: channels, Length: length, Capacity: length})
+			for i := 0; i < length; i += chunk {
+				end := min(i+chunk, length)
+				s.Process(input.Slice(i, end), output.Slice(i, end))
+			}
+			latency := s.Latency()
+			for i := 0; i < length; i++ {
+				for c := 0; c < channels; c++ {
+					var expected float64
+					if i >= latency {
+						expected = gain * input.Sample(input.BufferIndex(c, i-latency))
+					}
+					if result := output.Sample(output.BufferIndex(c, i)); math.Abs(result-expected) > 1e-9 {
+						t.Fatalf("channel %d sample %d: %v expected: %v", c, i, result, expected)
+					}
+				}
+			}
+			assertEqual(t, "frames", frames, []int{length / hop, length / hop})
+		}
+	}
+	sqrtHann := window.Periodic(window.Hann, 256)
+	for i := range sqrtHann {
+		sqrtHann[i] = math.Sqrt(sqrtHann[i])
+	}
+	t.Run("hann", testOk(window.Periodic(window.Hann, 256), 64, 1, 1000))
+	t.Run("hann chunks", testOk(window.Periodic(window.Hann, 256), 64, 1, 37))
+	t.Run("sqrt hann", testOk(sqrtHann, 128, 1, 100))
+	t.Run("rectangular", testOk(window.Rectangular(100), 100, 1, 1))
+	t.Run("gain", testOk(window.Periodic(window.Hann, 128), 32, 0.5, 64))
+	t.Run("odd size", testOk(window.Periodic(window.Hann, 120), 30, 1, 1000))
+}
+
+func TestAnalysis(t *testing.T) {
+	const size = 64
+	var peaks []int
+	s, err := stft.New[float32](1, window.Periodic(window.Hann, size), size/4, func(channel int, spectrum []complex128) {
+		peak := 0
+		for i := range spectrum {
+			if cmplxAbs(spectrum[i]) > cmplxAbs(spectrum[peak]) {
+				peak = i
+			}
+		}
+		peaks = append(peaks, peak)
+	})
+	assertNoError(t, err)
+	input := signal.Alloc[float32](signal.Allocator{Channels: 1, Length: size * 4, Capacity: size * 4})
+	for i := 0; i < input.Len(); i++ {
+		input.SetSample(i, float32(math.Cos(2*math.Pi*8*float64(i)/size)))
+	}
+	s.Process(input, nil)
+	assertEqual(t, "frames", len(peaks), 16)
+	// first frames are partially filled with silence.
+	for _, p := range peaks[3:] {
+		assertEqual(t, "peak", p, 8)
+	}
+
+	assertPanic(t, func() {
+		s.Process(input, signal.Alloc[float32](signal.Allocator{Channels: 1, Length: 1, Capacity: 1}))
+	})
+	assertPanic(t, func() {
+		s.Process(signal.Alloc[float32](signal.Allocator{Channels: 2, Length: 1, Capacity: 1}), nil)
+	})
+}
+
+func TestReset(t *testing.T) {
+	s, err := stft.New[float64](1, window.Rectangular(4), 3, nil)
+	assertEqual(t, "error", err, stft.ErrCOLA)
+	s, err = stft.New[float64](1, window.Periodic(window.Hann, 4), 1, nil)
+	assertNoError(t, err)
+	alloc := signal.Allocator{Channels: 1, Length: 8, Capacity: 8}
+	input, output := signal.Alloc[float64](alloc), signal.Alloc[float64](alloc)
+	signal.Write([]float64{1, 2, 3, 4, 5, 6, 7, 8}, input)
+	s.Process(input, output)
+	first := samples(output)
+	s.Reset()
+	s.Process(input, output)
+	assertEqual(t, "samples", samples(output), first)
+}
+
+func cmplxAbs(v complex128) float64 {
+	return math.Hypot(real(v), imag(v))
+}
+
+func samples[T signal.SignalTypes](b *signal.Buffer[T]) []T {
+	s := make([]T, b.Len())
+	signal.Read(b, s)
+	return s
+}
+
+func assertEqual(t *testing.T, name string, result, expected interface{}) {
+	t.Helper()
+	if !reflect.DeepEqual(expected, result) {
+		t.Fatalf("%v\nresult: \t%T\t%+v \nexpected: \t%T\t%+v", name, result, result, expected, expected)
+	}
+}
+
+func assertNoError(t *testing.T, err error) {
+	t.Helper()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func assertPanic(t *testing.T, fn func()) {
+	t.Helper()
+	defer func() {
+		if r := recover(); r == nil {
+			t.Fatalf("didn't panic")
+		}
+	}()
+	fn()
+}
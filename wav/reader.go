@@ -0,0 +1,161 @@
+package wav
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+
+	"pipelined.dev/signal"
+	"pipelined.dev/signal/pcm"
+)
+
+// Reader decodes samples from the WAVE stream.
+type Reader struct {
+	Header
+	r      io.Reader
+	format pcm.Format
+	// remaining is a number of bytes left in the data chunk, negative if
+	// the size of the data chunk is unknown.
+	remaining int64
+	buf       []byte
+}
+
+// NewReader reads the header of the WAVE stream and returns a Reader
+// positioned at the beginning of samples data. Chunks other than fmt and
+// data are skipped.
+func NewReader(r io.Reader) (*Reader, error) {
+	var riff [12]byte
+	if _, err := io.ReadFull(r, riff[:]); err != nil {
+		return nil, ErrHeader
+	}
+	if string(riff[0:4]) != "RIFF" || string(riff[8:12]) != "WAVE" {
+		return nil, ErrHeader
+	}
+
+	reader := Reader{r: r}
+	var hasFormat bool
+	for {
+		var chunk [8]byte
+		if _, err := io.ReadFull(r, chunk[:]); err != nil {
+			return nil, ErrHeader
+		}
+		size := binary.LittleEndian.Uint32(chunk[4:])
+		switch string(chunk[:4]) {
+		case "fmt ":
+			h, err := readFormat(r, size)
+			if err != nil {
+				return nil, err
+			}
+			if reader.format, err = h.format(); err != nil {
+				return nil, err
+			}
+			reader.Header, hasFormat = h, true
+		case "data":
+			if !hasFormat {
+				return nil, ErrHeader
+			}
+			if size == unknownSize {
+				reader.remaining = -1
+			} else {
+				reader.remaining = int64(size)
+			}
+			return &reader, nil
+		default:
+			if err := skip(r, size); err != nil {
+				return nil, ErrHeader
+			}
+		}
+	}
+}
+
+func readFormat(r io.Reader, size uint32) (Header, error) {
+	if size < 16 {
+		return Header{}, ErrHeader
+	}
+	data := make([]byte, size+size%2)
+	if _, err := io.ReadFull(r, data); err != nil {
+		return Header{}, ErrHeader
+	}
+	var (
+		tag        = binary.LittleEndian.Uint16(data[0:])
+		channels   = int(binary.LittleEndian.Uint16(data[2:]))
+		sampleRate = binary.LittleEndian.Uint32(data[4:])
+		blockAlign = int(binary.LittleEndian.Uint16(data[12:]))
+		bits       = binary.LittleEndian.Uint16(data[14:])
+	)
+	if channels == 0 || blockAlign%channels != 0 {
+		return Header{}, ErrHeader
+	}
+	h := Header{
+		Channels:   channels,
+		SampleRate: signal.Frequency(sampleRate),
+		BitDepth:   signal.BitDepth(blockAlign / channels * 8),
+	}
+	if tag == formatExtensible {
+		if size < 40 || binary.LittleEndian.Uint16(data[16:]) < 22 {
+			return Header{}, ErrHeader
+		}
+		if [14]byte(data[26:40]) != subFormat {
+			return Header{}, ErrFormat
+		}
+		h.Extensible = true
+		h.ChannelMask = ChannelMask(binary.LittleEndian.Uint32(data[20:]))
+		bits = binary.LittleEndian.Uint16(data[18:])
+		tag = binary.LittleEndian.Uint16(data[24:])
+	}
+	switch tag {
+	case formatPCM:
+	case formatFloat:
+		h.Float = true
+	default:
+		return Header{}, ErrFormat
+	}
+	if signal.BitDepth(bits) != h.BitDepth {
+		h.ValidBits = signal.BitDepth(bits)
+	}
+	return h, nil
+}
+
+// skip discards the chunk of provided size and its padding byte.
+func skip(r io.Reader, size uint32) error {
+	_, err := io.CopyN(io.Discard, r, int64(size)+int64(size%2))
+	return err
+}
+
+// Read decodes samples into the dst Buffer. The Buffer must have the same
+// number of channels as the stream. Up to dst.Length() samples per
+// channel are read. Returns a number of samples read per channel and
+// io.EOF when there are no samples left. If the stream ends before the
+// declared size of data, io.ErrUnexpectedEOF is returned.
+func Read[T signal.SignalTypes](r *Reader, dst *signal.Buffer[T]) (int, error) {
+	if dst.Channels() != r.Channels {
+		return 0, ErrChannels
+	}
+	frameSize := r.format.FrameSize(r.Channels)
+	size := dst.Length() * frameSize
+	if r.remaining >= 0 && int64(size) > r.remaining {
+		size = int(r.remaining) - int(r.remaining)%frameSize
+	}
+	if size == 0 {
+		return 0, io.EOF
+	}
+	if len(r.buf) < size {
+		r.buf = make([]byte, size)
+	}
+
+	n, err := io.ReadFull(r.r, r.buf[:size])
+	switch {
+	case errors.Is(err, io.EOF) && r.remaining < 0:
+		return 0, io.EOF
+	case errors.Is(err, io.ErrUnexpectedEOF) && r.remaining < 0:
+		// stream of unknown size ended, next read returns EOF.
+		r.remaining, err = 0, nil
+	case errors.Is(err, io.EOF):
+		err = io.ErrUnexpectedEOF
+	}
+	if r.remaining > 0 {
+		r.remaining -= int64(n)
+	}
+	read, _ := pcm.Decode(r.format, r.buf[:n-n%frameSize], dst)
+	return read, err
+}
@@ -0,0 +1,142 @@
+// Package wav provides streaming reader and writer of RIFF/WAVE files
+// built on top of signal buffers.
+//
+// PCM, IEEE floating-point and WAVE_FORMAT_EXTENSIBLE formats are
+// supported. Samples are decoded into buffers of any signal type and
+// quantized to the bit depth of the Buffer:
+//
+//	r, err := wav.NewReader(file)
+//	if err != nil {
+//		return err
+//	}
+//	buf := signal.AllocBitDepth[int32](r.Allocator(1024), signal.BitDepth24)
+//	for {
+//		n, err := wav.Read(r, buf)
+//		if err == io.EOF {
+//			break
+//		}
+//		// process buf.Slice(0, n)
+//	}
+package wav
+
+import (
+	"errors"
+
+	"pipelined.dev/signal"
+	"pipelined.dev/signal/pcm"
+)
+
+var (
+	// ErrHeader is returned when the stream is not a valid WAVE file.
+	ErrHeader = errors.New("wav: invalid header")
+	// ErrFormat is returned when the format of samples is not supported.
+	ErrFormat = errors.New("wav: unsupported format")
+	// ErrChannels is returned when the number of channels of the Buffer
+	// doesn't match the file.
+	ErrChannels = errors.New("wav: different number of channels")
+	// ErrClosed is returned when samples are written after the Writer
+	// is closed.
+	ErrClosed = errors.New("wav: writer is closed")
+)
+
+// format tags of the fmt chunk.
+const (
+	formatPCM        uint16 = 0x0001
+	formatFloat      uint16 = 0x0003
+	formatExtensible uint16 = 0xfffe
+)
+
+// unknownSize is used as a size of chunks with unknown length.
+const unknownSize uint32 = 0xffffffff
+
+// subFormat is a tail of KSDATAFORMAT_SUBTYPE GUIDs, the first two bytes
+// of GUID contain the format tag.
+var subFormat = [14]byte{0x00, 0x00, 0x00, 0x00, 0x10, 0x00, 0x80, 0x00, 0x00, 0xaa, 0x00, 0x38, 0x9b, 0x71}
+
+// ChannelMask defines the assignment of channels to speaker positions.
+type ChannelMask uint32
+
+// Speaker positions of WAVE_FORMAT_EXTENSIBLE channel mask.
+const (
+	FrontLeft ChannelMask = 1 << iota
+	FrontRight
+	FrontCenter
+	LowFrequency
+	BackLeft
+	BackRight
+	FrontLeftOfCenter
+	FrontRightOfCenter
+	BackCenter
+	SideLeft
+	SideRight
+	TopCenter
+	TopFrontLeft
+	TopFrontCenter
+	TopFrontRight
+	TopBackLeft
+	TopBackCenter
+	TopBackRight
+)
+
+// Header describes the format of samples in the WAVE file.
+type Header struct {
+	// Float is true for IEEE floating-point samples, PCM otherwise.
+	Float bool
+	// Extensible is true for WAVE_FORMAT_EXTENSIBLE files.
+	Extensible bool
+	Channels   int
+	SampleRate signal.Frequency
+	// BitDepth is a size of the sample container.
+	BitDepth signal.BitDepth
+	// ValidBits is a number of meaningful bits in the sample container.
+	// Zero value means all bits are valid.
+	ValidBits   signal.BitDepth
+	ChannelMask ChannelMask
+}
+
+// Allocator returns an allocator for buffers that hold provided number of
+// samples per channel.
+func (h Header) Allocator(length int) signal.Allocator {
+	return signal.Allocator{
+		Channels:   h.Channels,
+		Length:     length,
+		Capacity:   length,
+		SampleRate: h.SampleRate,
+	}
+}
+
+// format returns PCM format of samples. 8-bit PCM samples are unsigned,
+// all other PCM samples are signed.
+func (h Header) format() (pcm.Format, error) {
+	f := pcm.Format{BitDepth: h.BitDepth}
+	switch {
+	case h.Float:
+		f.Encoding = pcm.Float
+	case h.BitDepth == signal.BitDepth8:
+		f.Encoding = pcm.Unsigned
+	default:
+		f.Encoding = pcm.Signed
+	}
+	if err := f.Validate(); err != nil || h.Channels <= 0 || h.ValidBits > h.BitDepth {
+		return pcm.Format{}, ErrFormat
+	}
+	return f, nil
+}
+
+func (h Header) formatTag() uint16 {
+	switch {
+	case h.Extensible:
+		return formatExtensible
+	case h.Float:
+		return formatFloat
+	default:
+		return formatPCM
+	}
+}
+
+func (h Header) validBits() signal.BitDepth {
+	if h.ValidBits == 0 {
+		return h.BitDepth
+	}
+	return h.ValidBits
+}
@@ -0,0 +1,292 @@
+package wav_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"reflect"
+	"testing"
+
+	"pipelined.dev/signal"
+	"pipelined.dev/signal/wav"
+)
+
+func TestRoundTrip(t *testing.T) {
+	testOk := func(h wav.Header, samples []int32, bd signal.BitDepth) func(*testing.T) {
+		return func(t *testing.T) {
+			t.Helper()
+			var ws writeSeeker
+			w, err := wav.NewWriter(&ws, h)
+			assertNoError(t, err)
+			src := signal.AllocBitDepth[int32](h.Allocator(len(samples)/h.Channels), bd)
+			signal.Write(samples, src)
+			// write samples in two chunks
+			n, err := wav.Write(w, src.Slice(0, 1))
+			assertNoError(t, err)
+			assertEqual(t, "written", n, 1)
+			_, err = wav.Write(w, src.Slice(1, src.Length()))
+			assertNoError(t, err)
+			assertNoError(t, w.Close())
+
+			r, err := wav.NewReader(bytes.NewReader(ws.data))
+			assertNoError(t, err)
+			assertEqual(t, "header", r.Header, w.Header)
+			dst := signal.AllocBitDepth[int32](r.Allocator(2), bd)
+			var result []int32
+			for {
+				n, err := wav.Read(r, dst)
+				if err == io.EOF {
+					break
+				}
+				assertNoError(t, err)
+				read := make([]int32, n*h.Channels)
+				signal.Read(dst, read)
+				result = append(result, read...)
+			}
+			assertEqual(t, "samples", result, samples)
+			assertEqual(t, "riff size", int(binary.LittleEndian.Uint32(ws.data[4:])), len(ws.data)-8)
+		}
+	}
+	t.Run("pcm 16 bits", testOk(
+		wav.Header{Channels: 2, SampleRate: 44100, BitDepth: signal.BitDepth16},
+		[]int32{32767, -32768, 1, -1, 0, 100},
+		signal.BitDepth16,
+	))
+	t.Run("pcm 8 bits odd size", testOk(
+		wav.Header{Channels: 1, SampleRate: 8000, BitDepth: signal.BitDepth8},
+		[]int32{127, -128, 0},
+		signal.BitDepth8,
+	))
+	t.Run("extensible 24 bits", testOk(
+		wav.Header{
+			Channels:    3,
+			SampleRate:  48000,
+			BitDepth:    signal.BitDepth24,
+			ValidBits:   20,
+			Extensible:  true,
+			ChannelMask: wav.FrontLeft | wav.FrontRight | wav.FrontCenter,
+		},
+		[]int32{8388607, -8388608, 16, -16, 0, 1},
+		signal.BitDepth24,
+	))
+	t.Run("float 32 bits", testOk(
+		wav.Header{Channels: 1, SampleRate: 96000, BitDepth: signal.BitDepth32, Float: true},
+		[]int32{8388607, -8388608, -4096},
+		signal.BitDepth24,
+	))
+}
+
+func TestWriter(t *testing.T) {
+	t.Run("incomplete frame", func(t *testing.T) {
+		var ws writeSeeker
+		h := wav.Header{Channels: 2, SampleRate: 8000, BitDepth: signal.BitDepth8}
+		w, err := wav.NewWriter(&ws, h)
+		assertNoError(t, err)
+		header := len(ws.data)
+		src := signal.Alloc[int8](signal.Allocator{Channels: 2, Capacity: 2})
+		for i := 0; i < 3; i++ {
+			src.AppendSample(1)
+		}
+		n, err := wav.Write(w, src)
+		assertNoError(t, err)
+		assertEqual(t, "written", n, 1)
+		assertEqual(t, "bytes", len(ws.data)-header, 2)
+	})
+	t.Run("write after close", func(t *testing.T) {
+		var ws writeSeeker
+		h := wav.Header{Channels: 1, SampleRate: 8000, BitDepth: signal.BitDepth8}
+		w, err := wav.NewWriter(&ws, h)
+		assertNoError(t, err)
+		assertNoError(t, w.Close())
+		_, err = wav.Write(w, signal.Alloc[int8](h.Allocator(1)))
+		assertError(t, err, wav.ErrClosed)
+	})
+	// readSamples checks the size of the stream and reads its samples.
+	readSamples := func(t *testing.T, data []byte) []int8 {
+		t.Helper()
+		assertEqual(t, "size", int(binary.LittleEndian.Uint32(data[4:])), len(data)-8)
+		r, err := wav.NewReader(bytes.NewReader(data))
+		assertNoError(t, err)
+		dst := signal.Alloc[int8](r.Allocator(10))
+		n, err := wav.Read(r, dst)
+		assertNoError(t, err)
+		result := make([]int8, n)
+		signal.Read(dst, result)
+		return result
+	}
+	t.Run("offset", func(t *testing.T) {
+		ws := writeSeeker{data: []byte("JUNK"), pos: 4}
+		h := wav.Header{Channels: 1, SampleRate: 8000, BitDepth: signal.BitDepth8}
+		w, err := wav.NewWriter(&ws, h)
+		assertNoError(t, err)
+		src := signal.Alloc[int8](h.Allocator(3))
+		signal.Write([]int8{1, 2, 3}, src)
+		_, err = wav.Write(w, src)
+		assertNoError(t, err)
+		assertNoError(t, w.Close())
+		assertEqual(t, "prefix", string(ws.data[:4]), "JUNK")
+		assertEqual(t, "samples", readSamples(t, ws.data[4:]), []int8{1, 2, 3})
+	})
+	t.Run("retry close", func(t *testing.T) {
+		var ws writeSeeker
+		h := wav.Header{Channels: 1, SampleRate: 8000, BitDepth: signal.BitDepth8}
+		w, err := wav.NewWriter(&ws, h)
+		assertNoError(t, err)
+		src := signal.Alloc[int8](h.Allocator(3))
+		signal.Write([]int8{1, 2, 3}, src)
+		_, err = wav.Write(w, src)
+		assertNoError(t, err)
+		errSeek := errors.New("seek failed")
+		ws.seekErr = errSeek
+		assertError(t, w.Close(), errSeek)
+		assertNoError(t, w.Close())
+		assertEqual(t, "samples", readSamples(t, ws.data), []int8{1, 2, 3})
+	})
+	t.Run("close twice", func(t *testing.T) {
+		var ws writeSeeker
+		h := wav.Header{Channels: 1, SampleRate: 8000, BitDepth: signal.BitDepth8}
+		w, err := wav.NewWriter(&ws, h)
+		assertNoError(t, err)
+		src := signal.Alloc[int8](h.Allocator(3))
+		_, err = wav.Write(w, src)
+		assertNoError(t, err)
+		assertNoError(t, w.Close())
+		closed := append([]byte{}, ws.data...)
+		assertNoError(t, w.Close())
+		assertEqual(t, "stream", ws.data, closed)
+	})
+}
+
+func TestReader(t *testing.T) {
+	header := func(chunks ...[]byte) []byte {
+		b := []byte("RIFF\x00\x00\x00\x00WAVE")
+		for _, c := range chunks {
+			b = append(b, c...)
+		}
+		return b
+	}
+	chunk := func(id string, data ...byte) []byte {
+		b := append([]byte(id), binary.LittleEndian.AppendUint32(nil, uint32(len(data)))...)
+		b = append(b, data...)
+		if len(data)%2 != 0 {
+			b = append(b, 0)
+		}
+		return b
+	}
+	fmtPCM16 := chunk("fmt ",
+		0x01, 0x00, 0x01, 0x00, 0x44, 0xac, 0x00, 0x00,
+		0x88, 0x58, 0x01, 0x00, 0x02, 0x00, 0x10, 0x00,
+	)
+
+	t.Run("skip chunks", func(t *testing.T) {
+		r, err := wav.NewReader(bytes.NewReader(header(
+			chunk("LIST", 1, 2, 3),
+			fmtPCM16,
+			chunk("data", 0xff, 0x7f, 0x00, 0x80),
+		)))
+		assertNoError(t, err)
+		assertEqual(t, "sample rate", r.SampleRate, signal.Frequency(44100))
+		buf := signal.Alloc[int16](r.Allocator(4))
+		n, err := wav.Read(r, buf)
+		assertNoError(t, err)
+		assertEqual(t, "read", n, 2)
+		_, err = wav.Read(r, buf)
+		assertEqual(t, "error", err, io.EOF)
+	})
+	t.Run("unknown size", func(t *testing.T) {
+		data := append([]byte("data"), 0xff, 0xff, 0xff, 0xff, 0x01, 0x00, 0x02, 0x00, 0x03)
+		r, err := wav.NewReader(bytes.NewReader(header(fmtPCM16, data)))
+		assertNoError(t, err)
+		buf := signal.Alloc[int16](r.Allocator(4))
+		n, err := wav.Read(r, buf)
+		assertNoError(t, err)
+		assertEqual(t, "read", n, 2)
+		_, err = wav.Read(r, buf)
+		assertEqual(t, "error", err, io.EOF)
+	})
+	t.Run("truncated", func(t *testing.T) {
+		data := append([]byte("data"), 0x08, 0x00, 0x00, 0x00, 0x01, 0x00, 0x02, 0x00)
+		r, err := wav.NewReader(bytes.NewReader(header(fmtPCM16, data)))
+		assertNoError(t, err)
+		buf := signal.Alloc[int16](r.Allocator(4))
+		n, err := wav.Read(r, buf)
+		assertEqual(t, "read", n, 2)
+		assertEqual(t, "error", err, io.ErrUnexpectedEOF)
+	})
+	t.Run("no format", func(t *testing.T) {
+		_, err := wav.NewReader(bytes.NewReader(header(chunk("data", 1, 2))))
+		assertError(t, err, wav.ErrHeader)
+	})
+	t.Run("not wave", func(t *testing.T) {
+		_, err := wav.NewReader(bytes.NewReader([]byte("RIFF\x00\x00\x00\x00AVI ")))
+		assertError(t, err, wav.ErrHeader)
+	})
+	t.Run("unsupported format", func(t *testing.T) {
+		adpcm := chunk("fmt ",
+			0x02, 0x00, 0x01, 0x00, 0x44, 0xac, 0x00, 0x00,
+			0x88, 0x58, 0x01, 0x00, 0x02, 0x00, 0x10, 0x00,
+		)
+		_, err := wav.NewReader(bytes.NewReader(header(adpcm, chunk("data"))))
+		assertError(t, err, wav.ErrFormat)
+	})
+	t.Run("different channels", func(t *testing.T) {
+		r, err := wav.NewReader(bytes.NewReader(header(fmtPCM16, chunk("data", 1, 2))))
+		assertNoError(t, err)
+		_, err = wav.Read(r, signal.Alloc[int16](signal.Allocator{Channels: 2, Length: 1, Capacity: 1}))
+		assertError(t, err, wav.ErrChannels)
+	})
+}
+
+// writeSeeker is an in-memory io.WriteSeeker.
+type writeSeeker struct {
+	data []byte
+	pos  int
+	// seekErr is returned by the next call to Seek.
+	seekErr error
+}
+
+func (w *writeSeeker) Write(p []byte) (int, error) {
+	if end := w.pos + len(p); end > len(w.data) {
+		w.data = append(w.data, make([]byte, end-len(w.data))...)
+	}
+	w.pos += copy(w.data[w.pos:], p)
+	return len(p), nil
+}
+
+func (w *writeSeeker) Seek(offset int64, whence int) (int64, error) {
+	if err := w.seekErr; err != nil {
+		w.seekErr = nil
+		return 0, err
+	}
+	switch whence {
+	case io.SeekStart:
+		w.pos = int(offset)
+	case io.SeekCurrent:
+		w.pos += int(offset)
+	case io.SeekEnd:
+		w.pos = len(w.data) + int(offset)
+	}
+	return int64(w.pos), nil
+}
+
+func assertNoError(t *testing.T, err error) {
+	t.Helper()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func assertError(t *testing.T, err, expected error) {
+	t.Helper()
+	if !errors.Is(err, expected) {
+		t.Fatalf("expected error: %v got: %v", expected, err)
+	}
+}
+
+func assertEqual(t *testing.T, name string, result, expected interface{}) {
+	t.Helper()
+	if !reflect.DeepEqual(expected, result) {
+		t.Fatalf("%v\nresult: \t%T\t%+v \nexpected: \t%T\t%+v", name, result, result, expected, expected)
+	}
+}
@@ -0,0 +1,177 @@
+package wav
+
+import (
+	"encoding/binary"
+	"io"
+
+	"pipelined.dev/signal"
+	"pipelined.dev/signal/pcm"
+)
+
+// Writer encodes samples into the WAVE stream. The sizes of chunks are
+// written when Writer is closed, so the length of the stream doesn't need
+// to be known in advance.
+type Writer struct {
+	Header
+	w      io.WriteSeeker
+	format pcm.Format
+	// start is the position of the header in the underlying writer.
+	start int64
+	// offsets of size fields that are written on Close, relative to
+	// the start.
+	factOffset int64
+	dataOffset int64
+	headerSize int64
+	written    int64
+	buf        []byte
+	padded     bool
+	closed     bool
+}
+
+// NewWriter writes the header of the WAVE stream and returns a Writer.
+// WAVE_FORMAT_EXTENSIBLE header is written if it's requested or if the
+// channel mask or valid bits are set.
+func NewWriter(w io.WriteSeeker, h Header) (*Writer, error) {
+	if h.ChannelMask != 0 || h.ValidBits != 0 {
+		h.Extensible = true
+	}
+	f, err := h.format()
+	if err != nil {
+		return nil, err
+	}
+	start, err := w.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return nil, err
+	}
+
+	fmtSize := 16
+	switch {
+	case h.Extensible:
+		fmtSize = 40
+	case h.Float:
+		fmtSize = 18
+	}
+	header := make([]byte, 0, 12+8+fmtSize+12+8)
+	header = append(header, "RIFF"...)
+	header = binary.LittleEndian.AppendUint32(header, unknownSize)
+	header = append(header, "WAVE"...)
+
+	blockAlign := f.FrameSize(h.Channels)
+	header = append(header, "fmt "...)
+	header = binary.LittleEndian.AppendUint32(header, uint32(fmtSize))
+	header = binary.LittleEndian.AppendUint16(header, h.formatTag())
+	header = binary.LittleEndian.AppendUint16(header, uint16(h.Channels))
+	header = binary.LittleEndian.AppendUint32(header, uint32(h.SampleRate))
+	header = binary.LittleEndian.AppendUint32(header, uint32(h.SampleRate)*uint32(blockAlign))
+	header = binary.LittleEndian.AppendUint16(header, uint16(blockAlign))
+	switch {
+	case h.Extensible:
+		header = binary.LittleEndian.AppendUint16(header, uint16(h.BitDepth))
+		header = binary.LittleEndian.AppendUint16(header, 22)
+		header = binary.LittleEndian.AppendUint16(header, uint16(h.validBits()))
+		header = binary.LittleEndian.AppendUint32(header, uint32(h.ChannelMask))
+		if h.Float {
+			header = binary.LittleEndian.AppendUint16(header, formatFloat)
+		} else {
+			header = binary.LittleEndian.AppendUint16(header, formatPCM)
+		}
+		header = append(header, subFormat[:]...)
+	case h.Float:
+		header = binary.LittleEndian.AppendUint16(header, uint16(h.BitDepth))
+		header = binary.LittleEndian.AppendUint16(header, 0)
+	default:
+		header = binary.LittleEndian.AppendUint16(header, uint16(h.BitDepth))
+	}
+
+	writer := Writer{
+		Header: h,
+		w:      w,
+		format: f,
+		start:  start,
+	}
+	// fact chunk is required for all formats except PCM.
+	if h.formatTag() != formatPCM {
+		writer.factOffset = int64(len(header)) + 8
+		header = append(header, "fact"...)
+		header = binary.LittleEndian.AppendUint32(header, 4)
+		header = binary.LittleEndian.AppendUint32(header, unknownSize)
+	}
+	writer.dataOffset = int64(len(header)) + 4
+	header = append(header, "data"...)
+	header = binary.LittleEndian.AppendUint32(header, unknownSize)
+	if _, err := w.Write(header); err != nil {
+		return nil, err
+	}
+	writer.headerSize = int64(len(header))
+	return &writer, nil
+}
+
+// Write encodes samples of the src Buffer into the stream. The Buffer
+// must have the same number of channels as the stream. Incomplete last
+// frame of the Buffer is not written. Returns a number of samples written
+// per channel.
+func Write[T signal.SignalTypes](w *Writer, src *signal.Buffer[T]) (int, error) {
+	if w.closed {
+		return 0, ErrClosed
+	}
+	if src.Channels() != w.Channels {
+		return 0, ErrChannels
+	}
+	frameSize := w.format.FrameSize(w.Channels)
+	size := src.Len() / w.Channels * frameSize
+	if len(w.buf) < size {
+		w.buf = make([]byte, size)
+	}
+	n, _ := pcm.Encode(w.format, src, w.buf[:size])
+	written, err := w.w.Write(w.buf[:n*frameSize])
+	w.written += int64(written)
+	if err != nil {
+		return written / frameSize, err
+	}
+	return n, nil
+}
+
+// Close writes the sizes of chunks into the header. It doesn't close the
+// underlying writer. If Close fails, it can be called again, otherwise
+// subsequent calls do nothing.
+func (w *Writer) Close() error {
+	if w.closed {
+		return nil
+	}
+	size := w.headerSize + w.written
+	if w.written%2 != 0 {
+		if !w.padded {
+			if _, err := w.w.Write([]byte{0}); err != nil {
+				return err
+			}
+			w.padded = true
+		}
+		size++
+	}
+	if err := w.writeUint32(4, uint32(size-8)); err != nil {
+		return err
+	}
+	if w.factOffset != 0 {
+		frames := w.written / int64(w.format.FrameSize(w.Channels))
+		if err := w.writeUint32(w.factOffset, uint32(frames)); err != nil {
+			return err
+		}
+	}
+	if err := w.writeUint32(w.dataOffset, uint32(w.written)); err != nil {
+		return err
+	}
+	if _, err := w.w.Seek(w.start+size, io.SeekStart); err != nil {
+		return err
+	}
+	w.closed = true
+	return nil
+}
+
+// writeUint32 writes the value at the offset relative to the start.
+func (w *Writer) writeUint32(offset int64, v uint32) error {
+	if _, err := w.w.Seek(w.start+offset, io.SeekStart); err != nil {
+		return err
+	}
+	_, err := w.w.Write(binary.LittleEndian.AppendUint32(nil, v))
+	return err
+}
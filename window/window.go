@@ -0,0 +1,274 @@
+// Package window provides window functions for spectral analysis.
+//
+// Window functions generate symmetric windows, Periodic should be used to
+// generate windows for the FFT analysis. The window is applied in place
+// to every channel of the Buffer:
+//
+//	w := window.Periodic(window.Hann, 1024)
+//	window.Apply(w, buf)
+//
+// Parametrized windows, like Kaiser, Tukey and Gaussian, have constructors
+// that return Func, so they can be used the same way:
+//
+//	w := window.Periodic(window.KaiserFunc(8), 1024)
+//
+// Coherent gain and equivalent noise bandwidth of the window are used to
+// normalize amplitude and power measurements after the FFT.
+package window
+
+import (
+	"math"
+
+	"pipelined.dev/signal"
+)
+
+// Window is a sequence of window coefficients.
+type Window []float64
+
+// Func generates a symmetric window of provided length.
+type Func func(n int) Window
+
+// Coefficients of cosine-sum windows.
+var (
+	hann           = []float64{0.5, 0.5}
+	hamming        = []float64{0.54, 0.46}
+	blackman       = []float64{0.42, 0.5, 0.08}
+	blackmanHarris = []float64{0.35875, 0.48829, 0.14128, 0.01168}
+	nuttall        = []float64{0.355768, 0.487396, 0.144232, 0.012604}
+	flatTop        = []float64{0.21557895, 0.41663158, 0.277263158, 0.083578947, 0.006947368}
+)
+
+// Rectangular returns a window of ones.
+func Rectangular(n int) Window {
+	w := make(Window, n)
+	for i := range w {
+		w[i] = 1
+	}
+	return w
+}
+
+// Hann returns a Hann window.
+func Hann(n int) Window {
+	return cosineSum(n, hann)
+}
+
+// Hamming returns a Hamming window.
+func Hamming(n int) Window {
+	return cosineSum(n, hamming)
+}
+
+// Blackman returns a Blackman window.
+func Blackman(n int) Window {
+	return cosineSum(n, blackman)
+}
+
+// BlackmanHarris returns a 4-term Blackman-Harris window.
+func BlackmanHarris(n int) Window {
+	return cosineSum(n, blackmanHarris)
+}
+
+// Nuttall returns a 4-term Nuttall window with continuous first
+// derivative.
+func Nuttall(n int) Window {
+	return cosineSum(n, nuttall)
+}
+
+// FlatTop returns a flat-top window. It has the lowest amplitude error of
+// frequencies between the bins.
+func FlatTop(n int) Window {
+	return cosineSum(n, flatTop)
+}
+
+// Kaiser returns a Kaiser window. Beta defines the trade-off between the
+// main lobe width and the side lobe level.
+func Kaiser(n int, beta float64) Window {
+	w := make(Window, n)
+	if n == 1 {
+		w[0] = 1
+		return w
+	}
+	norm := BesselI0(beta)
+	for i := range w {
+		x := 2*float64(i)/float64(n-1) - 1
+		w[i] = BesselI0(beta*math.Sqrt(1-x*x)) / norm
+	}
+	return w
+}
+
+// KaiserFunc returns a function that generates Kaiser windows with
+// provided beta.
+func KaiserFunc(beta float64) Func {
+	return func(n int) Window {
+		return Kaiser(n, beta)
+	}
+}
+
+// Tukey returns a tapered cosine window. Alpha in range [0, 1] is a
+// fraction of the window inside the cosine tapers, zero value results in
+// rectangular and one in Hann window.
+func Tukey(n int, alpha float64) Window {
+	if alpha <= 0 {
+		return Rectangular(n)
+	}
+	if alpha >= 1 {
+		return Hann(n)
+	}
+	w := make(Window, n)
+	if n == 1 {
+		w[0] = 1
+		return w
+	}
+	width := alpha * float64(n-1) / 2
+	for i := range w {
+		x := math.Min(float64(i), float64(n-1-i))
+		if x < width {
+			w[i] = 0.5 * (1 - math.Cos(math.Pi*x/width))
+		} else {
+			w[i] = 1
+		}
+	}
+	return w
+}
+
+// TukeyFunc returns a function that generates Tukey windows with
+// provided alpha.
+func TukeyFunc(alpha float64) Func {
+	return func(n int) Window {
+		return Tukey(n, alpha)
+	}
+}
+
+// Gaussian returns a Gaussian window. Sigma is a standard deviation
+// relative to the half of the window length, it should not exceed 0.5.
+func Gaussian(n int, sigma float64) Window {
+	w := make(Window, n)
+	if n == 1 {
+		w[0] = 1
+		return w
+	}
+	half := float64(n-1) / 2
+	for i := range w {
+		x := (float64(i) - half) / (sigma * half)
+		w[i] = math.Exp(-0.5 * x * x)
+	}
+	return w
+}
+
+// GaussianFunc returns a function that generates Gaussian windows with
+// provided sigma.
+func GaussianFunc(sigma float64) Func {
+	return func(n int) Window {
+		return Gaussian(n, sigma)
+	}
+}
+
+// Periodic returns a periodic window of length n generated by the
+// function. Periodic windows are used for the spectral analysis.
+func Periodic(fn Func, n int) Window {
+	return fn(n + 1)[:n]
+}
+
+// CoherentGain returns the mean value of the window. Amplitudes of the
+// spectrum should be divided by it.
+func (w Window) CoherentGain() float64 {
+	if len(w) == 0 {
+		return 0
+	}
+	var sum float64
+	for _, v := range w {
+		sum += v
+	}
+	return sum / float64(len(w))
+}
+
+// ENBW returns the equivalent noise bandwidth of the window in bins.
+// Power spectral density should be divided by it.
+func (w Window) ENBW() float64 {
+	var sum, squares float64
+	for _, v := range w {
+		sum += v
+		squares += v * v
+	}
+	if sum == 0 {
+		return 0
+	}
+	return float64(len(w)) * squares / (sum * sum)
+}
+
+// Apply multiplies [0:Length] samples of every channel of the Buffer by
+// the window. If the Buffer is longer than the window, the rest of
+// samples is not modified. Integer results are rounded and clipped,
+// unsigned samples are scaled around the middle of their range. Returns a number
+// of samples processed per channel.
+func Apply[T signal.SignalTypes](w Window, b *signal.Buffer[T]) int {
+	length := min(len(w), b.Length())
+	for c := 0; c < b.Channels(); c++ {
+		applyChannel(w[:length], b, c)
+	}
+	return length
+}
+
+// ApplyChannel multiplies [0:Length] samples of the channel of the Buffer
+// by the window. If the Buffer is longer than the window, the rest of
+// samples is not modified. Integer results are rounded and clipped the same
+// way as in Apply. Returns a number of samples processed.
+func ApplyChannel[T signal.SignalTypes](w Window, b *signal.Buffer[T], channel int) int {
+	length := min(len(w), b.Length())
+	applyChannel(w[:length], b, channel)
+	return length
+}
+
+func applyChannel[T signal.SignalTypes](w Window, b *signal.Buffer[T], channel int) {
+	var zero, one T = 0, 1
+	var center float64
+	lo, hi := math.Inf(-1), math.Inf(1)
+	if one/2 == 0 {
+		bd := b.BitDepth()
+		lo, hi = float64(bd.MinSignedValue()), float64(bd.MaxSignedValue())
+		if zero-1 > 0 {
+			center = float64(bd.MaxSignedValue()) + 1
+			lo, hi = 0, float64(bd.MaxUnsignedValue())
+		}
+		// the largest value of 64 bits is not representable by float64
+		// and must not overflow on conversion.
+		hi = math.Min(hi, math.Nextafter(hi+1, 0))
+	}
+	for i, v := range w {
+		idx := b.BufferIndex(channel, i)
+		s := (float64(b.Sample(idx))-center)*v + center
+		if one/2 == 0 {
+			s = math.Max(lo, math.Min(hi, math.Round(s)))
+		}
+		b.SetSample(idx, T(s))
+	}
+}
+
+// cosineSum returns a window defined by the sum of cosines with
+// coefficients of alternating signs.
+func cosineSum(n int, coefs []float64) Window {
+	w := make(Window, n)
+	if n == 1 {
+		w[0] = 1
+		return w
+	}
+	for i := range w {
+		x := 2 * math.Pi * float64(i) / float64(n-1)
+		sign := 1.0
+		for k, a := range coefs {
+			w[i] += sign * a * math.Cos(float64(k)*x)
+			sign = -sign
+		}
+	}
+	return w
+}
+
+// BesselI0 is the zero-order modified Bessel function of the first kind.
+// It defines the shape of Kaiser window.
+func BesselI0(x float64) float64 {
+	sum, term := 1.0, 1.0
+	for k := 1; term > sum*1e-12; k++ {
+		term *= (x / (2 * float64(k))) * (x / (2 * float64(k)))
+		sum += term
+	}
+	return sum
+}
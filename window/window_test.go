@@ -0,0 +1,145 @@
+package window_test
+
+import (
+	"math"
+	"reflect"
+	"testing"
+
+	"pipelined.dev/signal"
+	"pipelined.dev/signal/window"
+)
+
+const tolerance = 1e-6
+
+func TestWindows(t *testing.T) {
+	testOk := func(w window.Window, edge, center float64) func(*testing.T) {
+		return func(t *testing.T) {
+			t.Helper()
+			assertEqual(t, "length", len(w), 65)
+			assertClose(t, "edge", w[0], edge)
+			assertClose(t, "center", w[32], center)
+			for i := range w {
+				assertClose(t, "symmetry", w[i], w[len(w)-1-i])
+			}
+		}
+	}
+	t.Run("rectangular", testOk(window.Rectangular(65), 1, 1))
+	t.Run("hann", testOk(window.Hann(65), 0, 1))
+	t.Run("hamming", testOk(window.Hamming(65), 0.08, 1))
+	t.Run("blackman", testOk(window.Blackman(65), 0, 1))
+	t.Run("blackman-harris", testOk(window.BlackmanHarris(65), 0.00006, 1))
+	t.Run("nuttall", testOk(window.Nuttall(65), 0, 1))
+	t.Run("flat top", testOk(window.FlatTop(65), -0.000421053, 1))
+	t.Run("kaiser", testOk(window.Kaiser(65, 5), 1/bessel(5), 1))
+	assertClose(t, "bessel", window.BesselI0(5), bessel(5))
+	t.Run("kaiser zero", testOk(window.Kaiser(65, 0), 1, 1))
+	t.Run("tukey", testOk(window.Tukey(65, 0.5), 0, 1))
+	t.Run("gaussian", testOk(window.Gaussian(65, 0.4), math.Exp(-0.5/0.16), 1))
+
+	assertEqual(t, "tukey zero", window.Tukey(9, 0), window.Rectangular(9))
+	assertEqual(t, "tukey one", window.Tukey(9, 1), window.Hann(9))
+	tukey := window.Tukey(65, 0.5)
+	assertClose(t, "tukey flat", tukey[16], 1)
+	assertClose(t, "tukey taper", tukey[8], 0.5)
+	assertEqual(t, "single", window.Hann(1), window.Window{1})
+	assertEqual(t, "empty", window.Hann(0), window.Window{})
+}
+
+func TestGain(t *testing.T) {
+	testOk := func(w window.Window, gain, enbw float64) func(*testing.T) {
+		return func(t *testing.T) {
+			t.Helper()
+			if math.Abs(w.CoherentGain()-gain) > 1e-3 {
+				t.Fatalf("coherent gain: %v expected: %v", w.CoherentGain(), gain)
+			}
+			if math.Abs(w.ENBW()-enbw) > 1e-3 {
+				t.Fatalf("ENBW: %v expected: %v", w.ENBW(), enbw)
+			}
+		}
+	}
+	const n = 4096
+	t.Run("rectangular", testOk(window.Rectangular(n), 1, 1))
+	t.Run("hann", testOk(window.Periodic(window.Hann, n), 0.5, 1.5))
+	t.Run("hamming", testOk(window.Periodic(window.Hamming, n), 0.54, 1.3628))
+	t.Run("blackman", testOk(window.Periodic(window.Blackman, n), 0.42, 1.7268))
+	t.Run("blackman-harris", testOk(window.Periodic(window.BlackmanHarris, n), 0.35875, 2.0044))
+	t.Run("nuttall", testOk(window.Periodic(window.Nuttall, n), 0.355768, 2.0212))
+	t.Run("flat top", testOk(window.Periodic(window.FlatTop, n), 0.21557895, 3.7702))
+	assertEqual(t, "empty gain", window.Window{}.CoherentGain(), 0.0)
+	assertEqual(t, "empty enbw", window.Window{}.ENBW(), 0.0)
+}
+
+func TestPeriodic(t *testing.T) {
+	w := window.Periodic(window.Hann, 4)
+	assertEqual(t, "length", len(w), 4)
+	expected := []float64{0, 0.5, 1, 0.5}
+	for i := range w {
+		assertClose(t, "sample", w[i], expected[i])
+	}
+	kaiser := window.Periodic(func(n int) window.Window { return window.Kaiser(n, 8) }, 16)
+	assertEqual(t, "kaiser length", len(kaiser), 16)
+	assertClose(t, "kaiser center", kaiser[8], 1)
+	assertEqual(t, "kaiser func", window.Periodic(window.KaiserFunc(8), 16), kaiser)
+	assertEqual(t, "tukey func", window.TukeyFunc(0.5)(9), window.Tukey(9, 0.5))
+	assertEqual(t, "gaussian func", window.GaussianFunc(0.4)(9), window.Gaussian(9, 0.4))
+}
+
+func TestApply(t *testing.T) {
+	alloc := signal.Allocator{Channels: 2, Length: 5, Capacity: 5}
+	buf := signal.Alloc[float32](alloc)
+	signal.Write([]float32{1, 2, 1, 2, 1, 2, 1, 2, 1, 2}, buf)
+	w := window.Window{0, 0.5, 1}
+	assertEqual(t, "applied", window.Apply(w, buf), 3)
+	assertEqual(t, "samples", samples(buf), []float32{0, 0, 0.5, 1, 1, 2, 1, 2, 1, 2})
+
+	assertEqual(t, "applied channel", window.ApplyChannel(window.Window{2, 2, 2, 2, 2, 2}, buf, 1), 5)
+	assertEqual(t, "samples", samples(buf), []float32{0, 0, 0.5, 2, 1, 4, 1, 4, 1, 4})
+
+	signed := signal.Alloc[int16](signal.Allocator{Channels: 1, Length: 3, Capacity: 3})
+	signal.Write([]int16{-3, 3, 3}, signed)
+	window.Apply(window.Window{0.5, 0.5, 1}, signed)
+	assertEqual(t, "signed", samples(signed), []int16{-2, 2, 3})
+
+	unsigned := signal.Alloc[uint8](signal.Allocator{Channels: 1, Length: 3, Capacity: 3})
+	signal.Write([]uint8{0, 255, 140}, unsigned)
+	window.Apply(window.Window{0, 0.5, 1}, unsigned)
+	assertEqual(t, "unsigned", samples(unsigned), []uint8{128, 192, 140})
+	window.Apply(window.Window{2, 2, 2}, unsigned)
+	assertEqual(t, "clipped", samples(unsigned), []uint8{128, 255, 152})
+
+	full := signal.Alloc[uint64](signal.Allocator{Channels: 1, Length: 2, Capacity: 2})
+	signal.Write([]uint64{math.MaxUint64, 1 << 63}, full)
+	window.Apply(window.Window{1, 1}, full)
+	if s := samples(full); s[0] < math.MaxUint64/2 || s[1] != 1<<63 {
+		t.Fatalf("full scale: %v", s)
+	}
+}
+
+func samples[T signal.SignalTypes](b *signal.Buffer[T]) []T {
+	s := make([]T, b.Len())
+	signal.Read(b, s)
+	return s
+}
+
+func bessel(x float64) float64 {
+	sum, term := 1.0, 1.0
+	for k := 1; k < 50; k++ {
+		term *= (x / (2 * float64(k))) * (x / (2 * float64(k)))
+		sum += term
+	}
+	return sum
+}
+
+func assertClose(t *testing.T, name string, result, expected float64) {
+	t.Helper()
+	if math.Abs(result-expected) > tolerance {
+		t.Fatalf("%v: %v expected: %v", name, result, expected)
+	}
+}
+
+func assertEqual(t *testing.T, name string, result, expected interface{}) {
+	t.Helper()
+	if !reflect.DeepEqual(expected, result) {
+		t.Fatalf("%v\nresult: \t%T\t%+v \nexpected: \t%T\t%+v", name, result, result, expected, expected)
+	}
+}